@@ -1,10 +1,11 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/aggregator/pkg"
@@ -49,24 +50,30 @@ func aggregatorMain(ctx *cli.Context) error {
 		return err
 	}
 
+	// Stop on SIGINT or SIGTERM, letting the aggregated responses in flight be sent
+	runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Supervisor revives garbage collector
 	go func() {
-		for {
+		for runCtx.Err() == nil {
 			log.Println("Starting Garbage collector")
-			aggregator.ClearTasksFromMaps()
-			log.Println("Garbage collector panicked, Supervisor restarting")
+			aggregator.ClearTasksFromMaps(runCtx)
+			if runCtx.Err() == nil {
+				log.Println("Garbage collector panicked, Supervisor restarting")
+			}
 		}
 	}()
 
 	// Listen for new task created in the ServiceManager contract in a separate goroutine, both V1 and V2 subscriptions:
 	go func() {
-		listenErr := aggregator.SubscribeToNewTasks()
+		listenErr := aggregator.SubscribeToNewTasks(runCtx)
 		if listenErr != nil {
 			aggregatorConfig.BaseConfig.Logger.Fatal("Error subscribing for new tasks", "err", listenErr)
 		}
 	}()
 
-	err = aggregator.Start(context.Background())
+	err = aggregator.Start(runCtx)
 
 	return err
 }
@@ -21,6 +21,8 @@ import (
 	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/core/aggregatorapi"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/config"
 	"github.com/yetanotherco/aligned_layer/core/types"
@@ -31,6 +33,15 @@ import (
 const QUORUM_NUMBER = byte(0)
 const QUORUM_THRESHOLD = byte(67)
 
+const (
+	// ShutdownTimeout bounds how long the aggregator keeps sending the aggregated responses in flight once it's asked to stop
+	ShutdownTimeout = 2 * time.Minute
+	// ShutdownCancelTimeout is how long the aggregator waits for the responses cancelled after ShutdownTimeout to return
+	ShutdownCancelTimeout = 10 * time.Second
+	// SignatureProcessingTimeout bounds how long the BLS aggregation service may take to process an operator signature
+	SignatureProcessingTimeout = 5 * time.Second
+)
+
 // Aggregator stores TaskResponse for a task here
 type TaskResponses = []types.SignedTaskResponse
 
@@ -70,6 +81,9 @@ type Aggregator struct {
 	// Stores the start time for each batch of the aggregator by task index
 	batchStartTimeByIdx map[uint32]time.Time
 
+	// Stores the state of each batch by task index, reported to the operators
+	batchStateByIdx map[uint32]aggregatorapi.TaskState
+
 	// This task index is to communicate with the local BLS
 	// Service.
 	// Note: In case of a reboot it can start from 0 again
@@ -82,11 +96,20 @@ type Aggregator struct {
 	// - batchDataByIdentifierHash
 	// - nextBatchIndex
 	// - batchStartTimeByIdx
+	// - batchStateByIdx
 	taskMutex *sync.Mutex
 
 	// Mutex to protect ethereum wallet
 	walletMutex *sync.Mutex
 
+	// Tracks the aggregated responses being sent, to wait for them on shutdown
+	responses sync.WaitGroup
+
+	// Retries of GetTaskIndexRetryable for the operator responses, and timeout of their BLS processing.
+	// retry.NetworkRetryParams and SignatureProcessingTimeout unless changed in tests.
+	taskIndexRetryParams *retry.RetryParams
+	signatureTimeout     time.Duration
+
 	logger logging.Logger
 
 	// Metrics
@@ -129,6 +152,7 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 	batchDataByIdentifierHash := make(map[[32]byte]BatchData)
 	batchCreatedBlockByIdx := make(map[uint32]uint64)
 	batchStartTimeByIdx := make(map[uint32]time.Time)
+	batchStateByIdx := make(map[uint32]aggregatorapi.TaskState)
 
 	chainioConfig := sdkclients.BuildAllConfig{
 		EthHttpUrl:                 aggregatorConfig.BaseConfig.EthRpcUrl,
@@ -178,9 +202,12 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 		batchDataByIdentifierHash:  batchDataByIdentifierHash,
 		batchCreatedBlockByIdx:     batchCreatedBlockByIdx,
 		batchStartTimeByIdx:        batchStartTimeByIdx,
+		batchStateByIdx:            batchStateByIdx,
 		nextBatchIndex:             nextBatchIndex,
 		taskMutex:                  &sync.Mutex{},
 		walletMutex:                &sync.Mutex{},
+		taskIndexRetryParams:       retry.NetworkRetryParams(),
+		signatureTimeout:           SignatureProcessingTimeout,
 
 		blsAggregationService: blsAggregationService,
 		logger:                logger,
@@ -192,11 +219,17 @@ func NewAggregator(aggregatorConfig config.AggregatorConfig) (*Aggregator, error
 	return &aggregator, nil
 }
 
+// Start serves the operators and sends the aggregated responses until ctx is done.
+// Then it stops accepting operator responses and waits for the aggregated responses in flight, see shutdown.
 func (agg *Aggregator) Start(ctx context.Context) error {
 	agg.logger.Infof("Starting aggregator...")
 
+	// The aggregated responses outlive ctx, so they can be sent while shutting down
+	responsesCtx, cancelResponses := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelResponses()
+
 	go func() {
-		err := agg.ServeOperators()
+		err := agg.ServeOperators(ctx)
 		if err != nil {
 			agg.logger.Fatal("Error listening for tasks", "err", err)
 		}
@@ -212,6 +245,7 @@ func (agg *Aggregator) Start(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
+			agg.shutdown(cancelResponses)
 			return nil
 		case err := <-metricsErrChan:
 			agg.logger.Fatal("Metrics server failed", "err", err)
@@ -219,14 +253,34 @@ func (agg *Aggregator) Start(ctx context.Context) error {
 			agg.logger.Info("Received response from BLS aggregation service",
 				"taskIndex", blsAggServiceResp.TaskIndex)
 
-			go agg.handleBlsAggServiceResponse(blsAggServiceResp)
+			agg.responses.Add(1)
+			go func() {
+				defer agg.responses.Done()
+				agg.handleBlsAggServiceResponse(responsesCtx, blsAggServiceResp)
+			}()
 		}
 	}
 }
 
+// shutdown waits up to ShutdownTimeout for the aggregated responses in flight to be sent.
+// The ones still in flight are then cancelled, which stops them before sending a new transaction.
+func (agg *Aggregator) shutdown(cancelResponses context.CancelFunc) {
+	agg.logger.Info("Aggregator shutting down, waiting for the aggregated responses in flight")
+	if utils.WaitTimeout(&agg.responses, ShutdownTimeout) {
+		agg.logger.Info("Aggregator shut down")
+		return
+	}
+
+	agg.logger.Warnf("Aggregated responses still in flight after %v, cancelling them", ShutdownTimeout)
+	cancelResponses()
+	if !utils.WaitTimeout(&agg.responses, ShutdownCancelTimeout) {
+		agg.logger.Error("Aggregated responses did not stop after being cancelled, exiting anyway")
+	}
+}
+
 const MaxSentTxRetries = 5
 
-func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsAggregationServiceResponse) {
+func (agg *Aggregator) handleBlsAggServiceResponse(ctx context.Context, blsAggServiceResp blsagg.BlsAggregationServiceResponse) {
 	defer func() {
 		err := recover() //stops panics
 		if err != nil {
@@ -247,6 +301,7 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 	defer agg.telemetry.FinishTrace(batchData.BatchMerkleRoot)
 
 	if blsAggServiceResp.Err != nil {
+		agg.setTaskState(blsAggServiceResp.TaskIndex, aggregatorapi.TaskStateFailed)
 		agg.telemetry.LogTaskError(batchData.BatchMerkleRoot, blsAggServiceResp.Err)
 		agg.logger.Error("BlsAggregationServiceResponse contains an error", "err", blsAggServiceResp.Err, "batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
 		return
@@ -271,6 +326,7 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		NonSignerStakeIndices:        blsAggServiceResp.NonSignerStakeIndices,
 	}
 
+	agg.setTaskState(blsAggServiceResp.TaskIndex, aggregatorapi.TaskStateQuorumReached)
 	agg.telemetry.LogQuorumReached(batchData.BatchMerkleRoot)
 
 	// Only observe quorum reached if successful
@@ -284,14 +340,14 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]),
 		"taskCreatedBlock", taskCreatedBlock)
 
-	err := agg.avsSubscriber.WaitForOneBlock(taskCreatedBlock)
+	err := agg.avsSubscriber.WaitForOneBlock(ctx, taskCreatedBlock)
 	if err != nil {
 		agg.logger.Error("Error waiting for one block, sending anyway", "err", err)
 	}
 
 	agg.logger.Info("Sending aggregated response onchain", "taskIndex", blsAggServiceResp.TaskIndex,
 		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]), "merkleRoot", "0x"+hex.EncodeToString(batchData.BatchMerkleRoot[:]))
-	receipt, err := agg.sendAggregatedResponse(batchIdentifierHash, batchData.BatchMerkleRoot, batchData.SenderAddress, nonSignerStakesAndSignature)
+	receipt, err := agg.sendAggregatedResponse(ctx, batchIdentifierHash, batchData.BatchMerkleRoot, batchData.SenderAddress, nonSignerStakesAndSignature)
 	if err == nil {
 		// In some cases, we may fail to retrieve the receipt for the transaction.
 		txHash := "Unknown"
@@ -300,6 +356,7 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 			txHash = receipt.TxHash.String()
 			effectiveGasPrice = receipt.EffectiveGasPrice.String()
 		}
+		agg.setTaskState(blsAggServiceResp.TaskIndex, aggregatorapi.TaskStateResponded)
 		agg.telemetry.TaskSentToEthereum(batchData.BatchMerkleRoot, txHash, effectiveGasPrice)
 		agg.logger.Info("Aggregator successfully responded to task",
 			"taskIndex", blsAggServiceResp.TaskIndex,
@@ -314,12 +371,13 @@ func (agg *Aggregator) handleBlsAggServiceResponse(blsAggServiceResp blsagg.BlsA
 		"merkleRoot", "0x"+hex.EncodeToString(batchData.BatchMerkleRoot[:]),
 		"senderAddress", "0x"+hex.EncodeToString(batchData.SenderAddress[:]),
 		"batchIdentifierHash", "0x"+hex.EncodeToString(batchIdentifierHash[:]))
+	agg.setTaskState(blsAggServiceResp.TaskIndex, aggregatorapi.TaskStateFailed)
 	agg.telemetry.LogTaskError(batchData.BatchMerkleRoot, err)
 }
 
 // / Sends response to contract and waits for transaction receipt
 // / Returns error if it fails to send tx or receipt is not found
-func (agg *Aggregator) sendAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*gethtypes.Receipt, error) {
+func (agg *Aggregator) sendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature) (*gethtypes.Receipt, error) {
 
 	agg.walletMutex.Lock()
 	agg.logger.Infof("- Locked Wallet Resources: Sending aggregated response for batch",
@@ -334,6 +392,7 @@ func (agg *Aggregator) sendAggregatedResponse(batchIdentifierHash [32]byte, batc
 
 	startTime := time.Now()
 	receipt, err := agg.avsWriter.SendAggregatedResponse(
+		ctx,
 		batchIdentifierHash,
 		batchMerkleRoot,
 		senderAddress,
@@ -400,6 +459,7 @@ func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, senderAddress [20]by
 		SenderAddress:   senderAddress,
 	}
 	agg.batchStartTimeByIdx[batchIndex] = time.Now()
+	agg.batchStateByIdx[batchIndex] = aggregatorapi.TaskStatePending
 	agg.logger.Info(
 		"Task Info added in aggregator:",
 		"Task", batchIndex,
@@ -426,7 +486,8 @@ func (agg *Aggregator) AddNewTask(batchMerkleRoot [32]byte, senderAddress [20]by
 // Long-lived goroutine that periodically checks and removes old Tasks from stored Maps
 // It runs every GarbageCollectorPeriod and removes all tasks older than GarbageCollectorTasksAge
 // This was added because each task occupies memory in the maps, and we need to free it to avoid a memory leak
-func (agg *Aggregator) ClearTasksFromMaps() {
+// Returns once ctx is done.
+func (agg *Aggregator) ClearTasksFromMaps(ctx context.Context) {
 	defer func() {
 		err := recover() //stops panics
 		if err != nil {
@@ -437,8 +498,14 @@ func (agg *Aggregator) ClearTasksFromMaps() {
 	agg.AggregatorConfig.BaseConfig.Logger.Info(fmt.Sprintf("- Removing finalized Task Infos from Maps every %v", agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod))
 	lastIdxDeleted := uint32(0)
 
+	ticker := time.NewTicker(agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod)
+	defer ticker.Stop()
 	for {
-		time.Sleep(agg.AggregatorConfig.Aggregator.GarbageCollectorPeriod)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 
 		agg.AggregatorConfig.BaseConfig.Logger.Info("Cleaning finalized tasks from maps")
 		oldTaskIdHash, err := agg.avsReader.GetOldTaskHash(agg.AggregatorConfig.Aggregator.GarbageCollectorTasksAge, agg.AggregatorConfig.Aggregator.GarbageCollectorTasksInterval)
@@ -465,6 +532,7 @@ func (agg *Aggregator) ClearTasksFromMaps() {
 				delete(agg.batchesIdentifierHashByIdx, i)
 				delete(agg.batchDataByIdentifierHash, batchIdentifierHash)
 				delete(agg.batchStartTimeByIdx, i)
+				delete(agg.batchStateByIdx, i)
 			} else {
 				agg.logger.Warn("Task not found in maps", "taskIndex", i)
 			}
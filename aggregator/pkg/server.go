@@ -7,43 +7,63 @@ import (
 	"fmt"
 	"net/http"
 	"net/rpc"
-	"time"
 
 	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/core/aggregatorapi"
 	"github.com/yetanotherco/aligned_layer/core/types"
 )
 
-func (agg *Aggregator) ServeOperators() error {
-	// Registers a new RPC server
-	err := rpc.Register(agg)
+// ServeOperators serves the API to the operators until ctx is done: the versioned JSON API of aggregatorapi and,
+// unless disabled, the legacy gob RPC at rpc.DefaultRPCPath for the operators not migrated yet
+func (agg *Aggregator) ServeOperators(ctx context.Context) error {
+	handler, err := agg.operatorsHandler()
 	if err != nil {
 		return err
 	}
 
-	// Registers an HTTP handler for RPC messages
-	rpc.HandleHTTP()
-
 	// Start listening for requests on aggregator address
 	// ServeOperators accepts incoming HTTP connections on the listener, creating
 	// a new service goroutine for each. The service goroutines read requests
 	// and then call handler to reply to them
 	agg.logger.Info("Starting RPC server on address", "address",
-		agg.AggregatorConfig.Aggregator.ServerIpPortAddress)
+		agg.AggregatorConfig.Aggregator.ServerIpPortAddress,
+		"apiVersion", aggregatorapi.Version,
+		"legacyRpc", !agg.AggregatorConfig.Aggregator.DisableLegacyRpc)
 
-	err = http.ListenAndServe(agg.AggregatorConfig.Aggregator.ServerIpPortAddress, nil)
+	server := &http.Server{Addr: agg.AggregatorConfig.Aggregator.ServerIpPortAddress, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		agg.logger.Info("Stopping RPC server")
+		server.Close()
+	}()
 
+	err = server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
 	return err
 }
 
-// Aggregator Methods
-// This is the list of methods that the Aggregator exposes to the Operator
-// The Operator can call these methods to interact with the Aggregator
-// This methods are automatically registered by the RPC server
-// This takes a response an adds it to the internal. If reaching the quorum, it sends the aggregated signatures to ethereum
-// Returns:
-//   - 0: Success
-//   - 1: Error
-func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *types.SignedTaskResponse, reply *uint8) error {
+// operatorsHandler routes the requests of the operators to the JSON API and, unless disabled, to the legacy gob RPC
+func (agg *Aggregator) operatorsHandler() (http.Handler, error) {
+	mux := http.NewServeMux()
+	mux.Handle("/", aggregatorapi.NewHandler(agg))
+
+	if !agg.AggregatorConfig.Aggregator.DisableLegacyRpc {
+		// Only the methods of LegacyRpcServer are exposed, under the "Aggregator" name the operators call
+		rpcServer := rpc.NewServer()
+		err := rpcServer.RegisterName("Aggregator", &LegacyRpcServer{agg: agg})
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle(rpc.DefaultRPCPath, rpcServer)
+	}
+	return mux, nil
+}
+
+// ProcessSignedTaskResponse takes a response and adds it to the internal. If reaching the quorum, it sends the aggregated
+// signatures to ethereum. Returns the state of the task, or an aggregatorapi.Error explaining why the signature was lost.
+func (agg *Aggregator) ProcessSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) (aggregatorapi.TaskState, error) {
 	agg.AggregatorConfig.BaseConfig.Logger.Info("New task response",
 		"BatchMerkleRoot", "0x"+hex.EncodeToString(signedTaskResponse.BatchMerkleRoot[:]),
 		"SenderAddress", "0x"+hex.EncodeToString(signedTaskResponse.SenderAddress[:]),
@@ -56,8 +76,7 @@ func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *t
 			"SenderAddress", "0x"+hex.EncodeToString(signedTaskResponse.SenderAddress[:]),
 			"BatchIdentifierHash", "0x"+hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]),
 			"operatorId", hex.EncodeToString(signedTaskResponse.OperatorId[:]))
-		*reply = 1
-		return errors.New("invalid response: nil signature")
+		return "", aggregatorapi.NewError(aggregatorapi.ErrorCodeInvalidSignature, "invalid response: nil signature")
 	}
 
 	taskIndex := uint32(0)
@@ -66,22 +85,20 @@ func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *t
 	// If that's the case, we won't know about the task at this point
 	// so we make GetTaskIndex retryable, waiting for some seconds,
 	// before trying to fetch the task again from the map.
-	taskIndex, err := agg.GetTaskIndexRetryable(signedTaskResponse.BatchIdentifierHash, retry.NetworkRetryParams())
+	taskIndex, err := agg.GetTaskIndexRetryable(signedTaskResponse.BatchIdentifierHash, agg.taskIndexRetryParams)
 
 	if err != nil {
 		agg.logger.Warn("Task not found in the internal map, operator signature will be lost. Batch may not reach quorum")
-		*reply = 1
-		return nil
+		return "", aggregatorapi.NewError(aggregatorapi.ErrorCodeTaskNotFound, "task not found")
 	}
 	agg.telemetry.LogOperatorResponse(signedTaskResponse.BatchMerkleRoot, signedTaskResponse.OperatorId)
 
 	// Don't wait infinitely if it can't answer
-	// Create a context with a timeout of 5 seconds
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, agg.signatureTimeout)
 	defer cancel() // Ensure the cancel function is called to release resources
 
 	// Create a channel to signal when the task is done
-	done := make(chan struct{})
+	done := make(chan error, 1)
 
 	agg.logger.Info("Starting bls signature process")
 	go func() {
@@ -92,32 +109,86 @@ func (agg *Aggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *t
 
 		if err != nil {
 			agg.logger.Warnf("BLS aggregation service error: %s", err)
-			// todo shouldn't we here close the channel with a reply = 1?
 		} else {
 			agg.logger.Info("BLS process succeeded")
 		}
 
-		close(done)
+		done <- err
 	}()
 
-	*reply = 1
 	// Wait for either the context to be done or the task to complete
 	select {
 	case <-ctx.Done():
 		// The context's deadline was exceeded or it was canceled
 		agg.logger.Info("Bls process timed out, operator signature will be lost. Batch may not reach quorum")
-	case <-done:
-		// The task completed successfully
+		return "", aggregatorapi.NewError(aggregatorapi.ErrorCodeTimeout, "signature not processed in time")
+	case err := <-done:
 		agg.logger.Info("Bls context finished correctly")
-		*reply = 0
+		if err != nil {
+			return "", aggregatorapi.NewError(aggregatorapi.ErrorCodeSignatureRejected, err.Error())
+		}
 	}
 
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+	return agg.batchStateByIdx[taskIndex], nil
+}
+
+// GetTaskStatus returns the status of the task of batchIdentifierHash, while it's kept in the internal maps
+func (agg *Aggregator) GetTaskStatus(batchIdentifierHash [32]byte) (*aggregatorapi.TaskStatus, error) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+
+	taskIndex, ok := agg.batchesIdxByIdentifierHash[batchIdentifierHash]
+	if !ok {
+		return nil, aggregatorapi.NewError(aggregatorapi.ErrorCodeTaskNotFound, "task not found")
+	}
+	batchData := agg.batchDataByIdentifierHash[batchIdentifierHash]
+	return &aggregatorapi.TaskStatus{
+		BatchIdentifierHash: batchIdentifierHash,
+		BatchMerkleRoot:     batchData.BatchMerkleRoot,
+		SenderAddress:       batchData.SenderAddress,
+		TaskCreatedBlock:    agg.batchCreatedBlockByIdx[taskIndex],
+		State:               agg.batchStateByIdx[taskIndex],
+	}, nil
+}
+
+// setTaskState records the state of the task of taskIndex, for GetTaskStatus
+func (agg *Aggregator) setTaskState(taskIndex uint32, state aggregatorapi.TaskState) {
+	agg.taskMutex.Lock()
+	defer agg.taskMutex.Unlock()
+	if _, ok := agg.batchesIdentifierHashByIdx[taskIndex]; ok {
+		agg.batchStateByIdx[taskIndex] = state
+	}
+}
+
+// LegacyRpcServer serves the gob net/rpc methods the operators called before the JSON API, during their migration.
+// Only the exported methods of this type are exposed, under the "Aggregator" name.
+type LegacyRpcServer struct {
+	agg *Aggregator
+}
+
+// ProcessOperatorSignedTaskResponseV2 processes the signed task response as ProcessSignedTaskResponse.
+// As it always did, only a nil signature is returned as an error, so operators don't retry the other failures.
+// Returns:
+//   - 0: Success
+//   - 1: Error
+func (s *LegacyRpcServer) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *types.SignedTaskResponse, reply *uint8) error {
+	_, err := s.agg.ProcessSignedTaskResponse(context.Background(), signedTaskResponse)
+	if err == nil {
+		*reply = 0
+		return nil
+	}
+	*reply = 1
+	var apiErr *aggregatorapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == aggregatorapi.ErrorCodeInvalidSignature {
+		return errors.New(apiErr.Message)
+	}
 	return nil
 }
 
 // Dummy method to check if the server is running
-// TODO: Remove this method in prod
-func (agg *Aggregator) ServerRunning(_ *struct{}, reply *int64) error {
+func (s *LegacyRpcServer) ServerRunning(_ *struct{}, reply *int64) error {
 	*reply = 1
 	return nil
 }
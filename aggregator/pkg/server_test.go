@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/rpc"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	blsagg "github.com/Layr-Labs/eigensdk-go/services/bls_aggregation"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	retry "github.com/yetanotherco/aligned_layer/core"
+	"github.com/yetanotherco/aligned_layer/core/aggregatorapi"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// fakeBlsAggregationService accepts the signatures unless err is set, or never answers if hang is set
+type fakeBlsAggregationService struct {
+	blsagg.BlsAggregationService
+	err  error
+	hang bool
+}
+
+func (s *fakeBlsAggregationService) ProcessNewSignature(ctx context.Context, taskIndex eigentypes.TaskIndex, taskResponse eigentypes.TaskResponse, blsSignature *bls.Signature, operatorId eigentypes.OperatorId) error {
+	if s.hang {
+		<-ctx.Done()
+	}
+	return s.err
+}
+
+// newTestAggregator returns an aggregator knowing the pending task of batchIdentifierHash, served by the returned address
+func newTestAggregator(t *testing.T, batchIdentifierHash [32]byte, blsAggregationService blsagg.BlsAggregationService, disableLegacyRpc bool) string {
+	t.Helper()
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("could not create logger: %s", err)
+	}
+	telemetryServer := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(telemetryServer.Close)
+
+	aggregatorConfig := &config.AggregatorConfig{BaseConfig: &config.BaseConfig{Logger: logger}}
+	aggregatorConfig.Aggregator.DisableLegacyRpc = disableLegacyRpc
+	agg := &Aggregator{
+		AggregatorConfig:           aggregatorConfig,
+		blsAggregationService:      blsAggregationService,
+		batchesIdentifierHashByIdx: map[uint32][32]byte{0: batchIdentifierHash},
+		batchesIdxByIdentifierHash: map[[32]byte]uint32{batchIdentifierHash: 0},
+		batchStateByIdx:            map[uint32]aggregatorapi.TaskState{0: aggregatorapi.TaskStatePending},
+		taskMutex:                  &sync.Mutex{},
+		taskIndexRetryParams: &retry.RetryParams{
+			InitialInterval: 10 * time.Millisecond,
+			MaxInterval:     10 * time.Millisecond,
+			Multiplier:      1,
+			NumRetries:      1,
+		},
+		signatureTimeout: 100 * time.Millisecond,
+		logger:           logger,
+		telemetry:        NewTelemetry(strings.TrimPrefix(telemetryServer.URL, "http://"), logger),
+	}
+
+	handler, err := agg.operatorsHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func newTestSignedTaskResponse(t *testing.T, batchIdentifierHash [32]byte) *types.SignedTaskResponse {
+	t.Helper()
+	keyPair, err := bls.NewKeyPairFromString("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.SignedTaskResponse{
+		BatchMerkleRoot:     [32]byte{1},
+		BatchIdentifierHash: batchIdentifierHash,
+		BlsSignature:        *keyPair.SignMessage([32]byte{1}),
+		OperatorId:          [32]byte{2},
+	}
+}
+
+// TestLegacyAndJsonApis sends the same responses through the legacy gob RPC and the JSON API of one aggregator.
+// Only a nil signature is a gob error, while the JSON API replies why each signature was lost.
+func TestLegacyAndJsonApis(t *testing.T) {
+	knownTask := [32]byte{3}
+	unknownTask := [32]byte{4}
+	nilSignature := newTestSignedTaskResponse(t, knownTask)
+	nilSignature.BlsSignature.G1Point = nil
+
+	cases := map[string]struct {
+		blsAggregationService *fakeBlsAggregationService
+		signedTaskResponse    *types.SignedTaskResponse
+		expected              aggregatorapi.ErrorCode
+		retryable             bool
+	}{
+		"accepted":           {&fakeBlsAggregationService{}, newTestSignedTaskResponse(t, knownTask), "", false},
+		"nil signature":      {&fakeBlsAggregationService{}, nilSignature, aggregatorapi.ErrorCodeInvalidSignature, false},
+		"unknown task":       {&fakeBlsAggregationService{}, newTestSignedTaskResponse(t, unknownTask), aggregatorapi.ErrorCodeTaskNotFound, true},
+		"rejected signature": {&fakeBlsAggregationService{err: errors.New("signature doesn't verify")}, newTestSignedTaskResponse(t, knownTask), aggregatorapi.ErrorCodeSignatureRejected, false},
+		"timeout":            {&fakeBlsAggregationService{hang: true}, newTestSignedTaskResponse(t, knownTask), aggregatorapi.ErrorCodeTimeout, true},
+	}
+	for name, c := range cases {
+		address := newTestAggregator(t, knownTask, c.blsAggregationService, false)
+
+		gobClient, err := rpc.DialHTTP("tcp", address)
+		if err != nil {
+			t.Fatalf("%s: could not dial the legacy RPC: %s", name, err)
+		}
+		var running int64
+		if err := gobClient.Call("Aggregator.ServerRunning", &struct{}{}, &running); err != nil || running != 1 {
+			t.Errorf("%s: expected the legacy RPC to be running, got %d: %v", name, running, err)
+		}
+		var reply uint8
+		err = gobClient.Call("Aggregator.ProcessOperatorSignedTaskResponseV2", c.signedTaskResponse, &reply)
+		gobClient.Close()
+		switch {
+		case c.expected == "" && (err != nil || reply != 0):
+			t.Errorf("%s: expected the legacy RPC to reply 0, got %d: %v", name, reply, err)
+		case c.expected == aggregatorapi.ErrorCodeInvalidSignature && err == nil:
+			t.Errorf("%s: expected the legacy RPC to return an error", name)
+		case c.expected != "" && c.expected != aggregatorapi.ErrorCodeInvalidSignature && (err != nil || reply != 1):
+			t.Errorf("%s: expected the legacy RPC to reply 1 without an error, got %d: %v", name, reply, err)
+		}
+
+		jsonClient := aggregatorapi.NewClient(address, http.DefaultClient)
+		state, err := jsonClient.SubmitSignedTaskResponse(context.Background(), c.signedTaskResponse)
+		if c.expected == "" {
+			if err != nil || state != aggregatorapi.TaskStatePending {
+				t.Errorf("%s: expected the state of the task to be replied, got %q: %v", name, state, err)
+			}
+			continue
+		}
+		var apiErr *aggregatorapi.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != c.expected || aggregatorapi.IsRetryable(err) != c.retryable {
+			t.Errorf("%s: expected a %s error retryable %t, got %v", name, c.expected, c.retryable, err)
+		}
+	}
+}
+
+func TestDisableLegacyRpc(t *testing.T) {
+	address := newTestAggregator(t, [32]byte{3}, &fakeBlsAggregationService{}, true)
+
+	if _, err := rpc.DialHTTP("tcp", address); err == nil {
+		t.Errorf("expected the legacy RPC not to be served")
+	}
+	if err := aggregatorapi.NewClient(address, http.DefaultClient).CheckVersion(context.Background()); err != nil {
+		t.Errorf("expected the JSON API to be served: %v", err)
+	}
+}
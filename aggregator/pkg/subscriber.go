@@ -1,16 +1,22 @@
 package pkg
 
-func (agg *Aggregator) SubscribeToNewTasks() error {
-	err := agg.subscribeToNewTasks()
+import "context"
+
+// SubscribeToNewTasks adds the new batches as tasks until ctx is done
+func (agg *Aggregator) SubscribeToNewTasks(ctx context.Context) error {
+	err := agg.subscribeToNewTasks(ctx)
 	if err != nil {
 		return err
 	}
 
 	for {
 		select {
+		case <-ctx.Done():
+			agg.AggregatorConfig.BaseConfig.Logger.Info("Stopped adding new tasks")
+			return nil
 		case err := <-agg.taskSubscriber:
 			agg.AggregatorConfig.BaseConfig.Logger.Info("Failed to subscribe to new tasks", "err", err)
-			err = agg.subscribeToNewTasks()
+			err = agg.subscribeToNewTasks(ctx)
 			if err != nil {
 				return err
 			}
@@ -21,10 +27,10 @@ func (agg *Aggregator) SubscribeToNewTasks() error {
 	}
 }
 
-func (agg *Aggregator) subscribeToNewTasks() error {
+func (agg *Aggregator) subscribeToNewTasks(ctx context.Context) error {
 	var err error
 
-	agg.taskSubscriber, err = agg.avsSubscriber.SubscribeToNewTasksV3(agg.NewBatchChan)
+	agg.taskSubscriber, err = agg.avsSubscriber.SubscribeToNewTasksV3(ctx, agg.NewBatchChan)
 
 	if err != nil {
 		agg.AggregatorConfig.BaseConfig.Logger.Info("Failed to create task subscriber", "err", err)
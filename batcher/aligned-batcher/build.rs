@@ -3,6 +3,14 @@ use std::{env, path::PathBuf, process::Command};
 const GO_SRC: &str = "./gnark/verifier.go";
 const GO_OUT: &str = "libverifier.a";
 const GO_LIB: &str = "verifier";
+// Go sources built into the library besides GO_SRC: the module of the verifier and the
+// packages of the aligned_layer module it uses through the `replace ../../..` in its go.mod
+const GO_DEPS: &[&str] = &[
+    "./gnark/go.mod",
+    "./gnark/go.sum",
+    "../../core/vkcache",
+    "../../core/lru",
+];
 
 fn main() {
     let out_dir = PathBuf::from(env::var("OUT_DIR").unwrap());
@@ -17,6 +25,9 @@ fn main() {
     go_build.status().expect("Go build failed");
 
     println!("cargo:rerun-if-changed={}", GO_SRC);
+    for dep in GO_DEPS {
+        println!("cargo:rerun-if-changed={}", dep);
+    }
     println!(
         "cargo:rustc-link-search=native={}",
         out_dir.to_str().unwrap()
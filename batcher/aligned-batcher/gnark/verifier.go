@@ -22,6 +22,7 @@ import (
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/plonk"
 	"github.com/consensys/gnark/backend/witness"
+	"github.com/yetanotherco/aligned_layer/core/vkcache"
 )
 
 func listRefToBytes(listRef C.ListRef) []byte {
@@ -35,6 +36,17 @@ func listRefToBytes(listRef C.ListRef) []byte {
 
 func main() {}
 
+// verifyingKeys is the cache of parsed verifying keys, its budget is set with SetVerifyingKeyCacheMaxBytes
+var verifyingKeys = vkcache.New(vkcache.DefaultMaxBytes)
+
+// SetVerifyingKeyCacheMaxBytes changes the memory budget of the parsed verifying key cache,
+// measured in serialized key bytes
+//
+//export SetVerifyingKeyCacheMaxBytes
+func SetVerifyingKeyCacheMaxBytes(maxBytes C.int64_t) {
+	verifyingKeys.SetMaxBytes(int64(maxBytes))
+}
+
 //export VerifyPlonkProofBLS12_381
 func VerifyPlonkProofBLS12_381(proofBytes C.ListRef, pubInputBytes C.ListRef, verificationKeyBytes C.ListRef) bool {
 	return verifyPlonkProof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BLS12_381)
@@ -50,6 +62,31 @@ func VerifyGroth16ProofBN254(proofBytes C.ListRef, pubInputBytes C.ListRef, veri
 	return verifyGroth16Proof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BN254)
 }
 
+//export VerifyPlonkProofBLS12_377
+func VerifyPlonkProofBLS12_377(proofBytes C.ListRef, pubInputBytes C.ListRef, verificationKeyBytes C.ListRef) bool {
+	return verifyPlonkProof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BLS12_377)
+}
+
+//export VerifyPlonkProofBW6_761
+func VerifyPlonkProofBW6_761(proofBytes C.ListRef, pubInputBytes C.ListRef, verificationKeyBytes C.ListRef) bool {
+	return verifyPlonkProof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BW6_761)
+}
+
+//export VerifyGroth16ProofBLS12_381
+func VerifyGroth16ProofBLS12_381(proofBytes C.ListRef, pubInputBytes C.ListRef, verificationKeyBytes C.ListRef) bool {
+	return verifyGroth16Proof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BLS12_381)
+}
+
+//export VerifyGroth16ProofBLS12_377
+func VerifyGroth16ProofBLS12_377(proofBytes C.ListRef, pubInputBytes C.ListRef, verificationKeyBytes C.ListRef) bool {
+	return verifyGroth16Proof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BLS12_377)
+}
+
+//export VerifyGroth16ProofBW6_761
+func VerifyGroth16ProofBW6_761(proofBytes C.ListRef, pubInputBytes C.ListRef, verificationKeyBytes C.ListRef) bool {
+	return verifyGroth16Proof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BW6_761)
+}
+
 // verifyPlonkProof contains the common proof verification logic.
 func verifyPlonkProof(proofBytesRef C.ListRef, pubInputBytesRef C.ListRef, verificationKeyBytesRef C.ListRef, curve ecc.ID) bool {
 	proofBytes := listRefToBytes(proofBytesRef)
@@ -74,9 +111,8 @@ func verifyPlonkProof(proofBytesRef C.ListRef, pubInputBytesRef C.ListRef, verif
 		return false
 	}
 
-	verificationKeyReader := bytes.NewReader(verificationKeyBytes)
-	verificationKey := plonk.NewVerifyingKey(curve)
-	if _, err = verificationKey.ReadFrom(verificationKeyReader); err != nil {
+	verificationKey, err := verifyingKeys.PlonkVerifyingKey(verificationKeyBytes, curve)
+	if err != nil {
 		log.Printf("Could not read PLONK verifying key from bytes: %v", err)
 		return false
 	}
@@ -109,9 +145,8 @@ func verifyGroth16Proof(proofBytesRef C.ListRef, pubInputBytesRef C.ListRef, ver
 		return false
 	}
 
-	verificationKeyReader := bytes.NewReader(verificationKeyBytes)
-	verificationKey := groth16.NewVerifyingKey(curve)
-	if _, err = verificationKey.ReadFrom(verificationKeyReader); err != nil {
+	verificationKey, err := verifyingKeys.Groth16VerifyingKey(verificationKeyBytes, curve)
+	if err != nil {
 		log.Printf("Could not read Groth16 verifying key from bytes: %v", err)
 		return false
 	}
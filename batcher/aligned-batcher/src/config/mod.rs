@@ -47,6 +47,8 @@ pub struct BatcherConfigFromYaml {
     pub max_batch_byte_size: usize,
     pub max_batch_proof_qty: usize,
     pub pre_verification_is_enabled: bool,
+    /// Memory budget of the parsed gnark verifying keys, in serialized key bytes
+    pub verifying_key_cache_max_bytes: Option<i64>,
     pub metrics_port: u16,
     pub telemetry_ip_port_address: String,
     pub non_paying: Option<NonPayingConfigFromYaml>,
@@ -47,11 +47,33 @@ pub fn verify_gnark(
         ProvingSystemId::Groth16Bn254 => unsafe {
             VerifyGroth16ProofBN254(proof, public_input, verification_key)
         },
+        ProvingSystemId::GnarkPlonkBls12_377 => unsafe {
+            VerifyPlonkProofBLS12_377(proof, public_input, verification_key)
+        },
+        ProvingSystemId::GnarkPlonkBw6_761 => unsafe {
+            VerifyPlonkProofBW6_761(proof, public_input, verification_key)
+        },
+        ProvingSystemId::Groth16Bls12_381 => unsafe {
+            VerifyGroth16ProofBLS12_381(proof, public_input, verification_key)
+        },
+        ProvingSystemId::Groth16Bls12_377 => unsafe {
+            VerifyGroth16ProofBLS12_377(proof, public_input, verification_key)
+        },
+        ProvingSystemId::Groth16Bw6_761 => unsafe {
+            VerifyGroth16ProofBW6_761(proof, public_input, verification_key)
+        },
         _ => false,
     }
 }
 
+/// Sets the memory budget of the parsed verifying key cache of the gnark verifiers,
+/// measured in serialized key bytes
+pub fn set_verifying_key_cache_max_bytes(max_bytes: i64) {
+    unsafe { SetVerifyingKeyCacheMaxBytes(max_bytes) }
+}
+
 extern "C" {
+    pub fn SetVerifyingKeyCacheMaxBytes(max_bytes: i64);
     pub fn VerifyPlonkProofBLS12_381(
         proof: ListRef,
         public_input: ListRef,
@@ -67,4 +89,29 @@ extern "C" {
         public_input: ListRef,
         verification_key: ListRef,
     ) -> bool;
+    pub fn VerifyPlonkProofBLS12_377(
+        proof: ListRef,
+        public_input: ListRef,
+        verification_key: ListRef,
+    ) -> bool;
+    pub fn VerifyPlonkProofBW6_761(
+        proof: ListRef,
+        public_input: ListRef,
+        verification_key: ListRef,
+    ) -> bool;
+    pub fn VerifyGroth16ProofBLS12_381(
+        proof: ListRef,
+        public_input: ListRef,
+        verification_key: ListRef,
+    ) -> bool;
+    pub fn VerifyGroth16ProofBLS12_377(
+        proof: ListRef,
+        public_input: ListRef,
+        verification_key: ListRef,
+    ) -> bool;
+    pub fn VerifyGroth16ProofBW6_761(
+        proof: ListRef,
+        public_input: ListRef,
+        verification_key: ListRef,
+    ) -> bool;
 }
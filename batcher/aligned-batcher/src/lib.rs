@@ -124,6 +124,10 @@ impl Batcher {
             config.batcher.max_proof_size
         );
 
+        if let Some(max_bytes) = config.batcher.verifying_key_cache_max_bytes {
+            gnark::set_verifying_key_cache_max_bytes(max_bytes);
+        }
+
         let deployment_output =
             ContractDeploymentOutput::new(config.aligned_layer_deployment_config_file_path);
 
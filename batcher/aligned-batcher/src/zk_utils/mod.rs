@@ -38,7 +38,12 @@ fn verify_internal(verification_data: &VerificationData) -> bool {
         }
         ProvingSystemId::GnarkPlonkBls12_381
         | ProvingSystemId::GnarkPlonkBn254
-        | ProvingSystemId::Groth16Bn254 => {
+        | ProvingSystemId::Groth16Bn254
+        | ProvingSystemId::GnarkPlonkBls12_377
+        | ProvingSystemId::GnarkPlonkBw6_761
+        | ProvingSystemId::Groth16Bls12_381
+        | ProvingSystemId::Groth16Bls12_377
+        | ProvingSystemId::Groth16Bw6_761 => {
             let Some(vk) = verification_data.verification_key.as_ref() else {
                 warn!("Gnark verification key missing");
                 return false;
@@ -82,6 +87,11 @@ mod test {
             ProvingSystemId::Groth16Bn254,
             ProvingSystemId::SP1,
             ProvingSystemId::Risc0,
+            ProvingSystemId::GnarkPlonkBls12_377,
+            ProvingSystemId::GnarkPlonkBw6_761,
+            ProvingSystemId::Groth16Bls12_381,
+            ProvingSystemId::Groth16Bls12_377,
+            ProvingSystemId::Groth16Bw6_761,
         ];
         // Just to make sure we are not missing any verifier. The compilation will fail if we do and it forces us to add it to the vec above.
         for verifier in verifiers.iter() {
@@ -91,6 +101,11 @@ mod test {
                 ProvingSystemId::GnarkPlonkBls12_381 => (),
                 ProvingSystemId::GnarkPlonkBn254 => (),
                 ProvingSystemId::Groth16Bn254 => (),
+                ProvingSystemId::GnarkPlonkBls12_377 => (),
+                ProvingSystemId::GnarkPlonkBw6_761 => (),
+                ProvingSystemId::Groth16Bls12_381 => (),
+                ProvingSystemId::Groth16Bls12_377 => (),
+                ProvingSystemId::Groth16Bw6_761 => (),
             }
         }
         verifiers
@@ -35,6 +35,11 @@ pub enum ProvingSystemId {
     #[default]
     SP1,
     Risc0,
+    GnarkPlonkBls12_377,
+    GnarkPlonkBw6_761,
+    Groth16Bls12_381,
+    Groth16Bls12_377,
+    Groth16Bw6_761,
 }
 
 impl Display for ProvingSystemId {
@@ -45,6 +50,11 @@ impl Display for ProvingSystemId {
             ProvingSystemId::Groth16Bn254 => write!(f, "Groth16Bn254"),
             ProvingSystemId::SP1 => write!(f, "SP1"),
             ProvingSystemId::Risc0 => write!(f, "Risc0"),
+            ProvingSystemId::GnarkPlonkBls12_377 => write!(f, "GnarkPlonkBls12_377"),
+            ProvingSystemId::GnarkPlonkBw6_761 => write!(f, "GnarkPlonkBw6_761"),
+            ProvingSystemId::Groth16Bls12_381 => write!(f, "Groth16Bls12_381"),
+            ProvingSystemId::Groth16Bls12_377 => write!(f, "Groth16Bls12_377"),
+            ProvingSystemId::Groth16Bw6_761 => write!(f, "Groth16Bw6_761"),
         }
     }
 }
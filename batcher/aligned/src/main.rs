@@ -249,6 +249,16 @@ pub enum ProvingSystemArg {
     SP1,
     #[clap(name = "Risc0")]
     Risc0,
+    #[clap(name = "GnarkPlonkBls12_377")]
+    GnarkPlonkBls12_377,
+    #[clap(name = "GnarkPlonkBw6_761")]
+    GnarkPlonkBw6_761,
+    #[clap(name = "Groth16Bls12_381")]
+    Groth16Bls12_381,
+    #[clap(name = "Groth16Bls12_377")]
+    Groth16Bls12_377,
+    #[clap(name = "Groth16Bw6_761")]
+    Groth16Bw6_761,
 }
 
 const ANVIL_PRIVATE_KEY: &str = "2a871d0798f97d79848a013d4936a73bf4cc922c825d33c1cf7073dff6d409c6"; // Anvil address 9
@@ -261,6 +271,11 @@ impl From<ProvingSystemArg> for ProvingSystemId {
             ProvingSystemArg::Groth16Bn254 => ProvingSystemId::Groth16Bn254,
             ProvingSystemArg::SP1 => ProvingSystemId::SP1,
             ProvingSystemArg::Risc0 => ProvingSystemId::Risc0,
+            ProvingSystemArg::GnarkPlonkBls12_377 => ProvingSystemId::GnarkPlonkBls12_377,
+            ProvingSystemArg::GnarkPlonkBw6_761 => ProvingSystemId::GnarkPlonkBw6_761,
+            ProvingSystemArg::Groth16Bls12_381 => ProvingSystemId::Groth16Bls12_381,
+            ProvingSystemArg::Groth16Bls12_377 => ProvingSystemId::Groth16Bls12_377,
+            ProvingSystemArg::Groth16Bw6_761 => ProvingSystemId::Groth16Bw6_761,
         }
     }
 }
@@ -575,7 +590,12 @@ fn verification_data_from_args(args: &SubmitArgs) -> Result<VerificationData, Su
         }
         ProvingSystemId::GnarkPlonkBls12_381
         | ProvingSystemId::GnarkPlonkBn254
-        | ProvingSystemId::Groth16Bn254 => {
+        | ProvingSystemId::Groth16Bn254
+        | ProvingSystemId::GnarkPlonkBls12_377
+        | ProvingSystemId::GnarkPlonkBw6_761
+        | ProvingSystemId::Groth16Bls12_381
+        | ProvingSystemId::Groth16Bls12_377
+        | ProvingSystemId::Groth16Bw6_761 => {
             verification_key = Some(read_file_option(
                 "--vk",
                 args.verification_key_file_name.clone(),
@@ -16,10 +16,19 @@ const (
 	Groth16Bn254
 	SP1
 	Risc0
+	GnarkPlonkBls12_377
+	GnarkPlonkBw6_761
+	Groth16Bls12_381
+	Groth16Bls12_377
+	Groth16Bw6_761
 )
 
 func (t *ProvingSystemId) String() string {
-	return [...]string{"GnarkPlonkBls12_381", "GnarkPlonkBn254", "Groth16Bn254", "SP1"}[*t]
+	str, err := ProvingSystemIdToString(*t)
+	if err != nil {
+		return fmt.Sprintf("ProvingSystemId(%d)", *t)
+	}
+	return str
 }
 
 func ProvingSystemIdFromString(provingSystem string) (ProvingSystemId, error) {
@@ -34,6 +43,16 @@ func ProvingSystemIdFromString(provingSystem string) (ProvingSystemId, error) {
 		return SP1, nil
 	case "Risc0":
 		return Risc0, nil
+	case "GnarkPlonkBls12_377":
+		return GnarkPlonkBls12_377, nil
+	case "GnarkPlonkBw6_761":
+		return GnarkPlonkBw6_761, nil
+	case "Groth16Bls12_381":
+		return Groth16Bls12_381, nil
+	case "Groth16Bls12_377":
+		return Groth16Bls12_377, nil
+	case "Groth16Bw6_761":
+		return Groth16Bw6_761, nil
 	}
 
 	return 0, fmt.Errorf("unknown proving system: %s", provingSystem)
@@ -51,6 +70,16 @@ func ProvingSystemIdToString(provingSystem ProvingSystemId) (string, error) {
 		return "SP1", nil
 	case Risc0:
 		return "Risc0", nil
+	case GnarkPlonkBls12_377:
+		return "GnarkPlonkBls12_377", nil
+	case GnarkPlonkBw6_761:
+		return "GnarkPlonkBw6_761", nil
+	case Groth16Bls12_381:
+		return "Groth16Bls12_381", nil
+	case Groth16Bls12_377:
+		return "Groth16Bls12_377", nil
+	case Groth16Bw6_761:
+		return "Groth16Bw6_761", nil
 	}
 
 	return "", fmt.Errorf("unknown proving system: %d", provingSystem)
@@ -105,6 +134,16 @@ func (s *ProvingSystemId) UnmarshalCBOR(data []byte) error {
 		*s = SP1
 	case "Risc0":
 		*s = Risc0
+	case "GnarkPlonkBls12_377":
+		*s = GnarkPlonkBls12_377
+	case "GnarkPlonkBw6_761":
+		*s = GnarkPlonkBw6_761
+	case "Groth16Bls12_381":
+		*s = Groth16Bls12_381
+	case "Groth16Bls12_377":
+		*s = Groth16Bls12_377
+	case "Groth16Bw6_761":
+		*s = Groth16Bw6_761
 	}
 
 	return nil
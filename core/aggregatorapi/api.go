@@ -0,0 +1,189 @@
+// Package aggregatorapi is the versioned HTTP JSON API the aggregator serves to the operators:
+// submitting signed task responses and querying the status of tasks.
+// The schema and error codes are documented in docs/2_architecture/components/5_aggregator.md.
+package aggregatorapi
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// Version is the version of the API, prefixing all its paths
+const Version = "v1"
+
+const (
+	// VersionsPath lists the versions of the API served by the aggregator. It's not versioned itself.
+	VersionsPath            = "/versions"
+	SignedTaskResponsesPath = "/" + Version + "/signed-task-responses"
+	// TasksPath is followed by the batch identifier hash of the task
+	TasksPath = "/" + Version + "/tasks/"
+)
+
+// SignedTaskResponse is the body of `POST /v1/signed-task-responses`
+type SignedTaskResponse struct {
+	BatchIdentifierHash common.Hash    `json:"batch_identifier_hash"`
+	BatchMerkleRoot     common.Hash    `json:"batch_merkle_root"`
+	SenderAddress       common.Address `json:"sender_address"`
+	OperatorId          common.Hash    `json:"operator_id"`
+	// BlsSignature is the serialized G1 point of the signature, as hex
+	BlsSignature string `json:"bls_signature"`
+}
+
+// SignedTaskResponseReply is the body replied to an accepted signed task response
+type SignedTaskResponseReply struct {
+	Status TaskState `json:"status"`
+}
+
+// TaskState is the state of a task in the aggregator
+type TaskState string
+
+const (
+	// TaskStatePending means the aggregator is collecting signatures for the task
+	TaskStatePending TaskState = "pending"
+	// TaskStateQuorumReached means the aggregator is sending the aggregated response of the task
+	TaskStateQuorumReached TaskState = "quorum_reached"
+	// TaskStateResponded means the aggregated response of the task was sent
+	TaskStateResponded TaskState = "responded"
+	// TaskStateFailed means the quorum wasn't reached in time, or the aggregated response could not be sent
+	TaskStateFailed TaskState = "failed"
+)
+
+// TaskStatus is the body replied to `GET /v1/tasks/{batch_identifier_hash}`
+type TaskStatus struct {
+	BatchIdentifierHash common.Hash    `json:"batch_identifier_hash"`
+	BatchMerkleRoot     common.Hash    `json:"batch_merkle_root"`
+	SenderAddress       common.Address `json:"sender_address"`
+	TaskCreatedBlock    uint64         `json:"task_created_block"`
+	State               TaskState      `json:"state"`
+}
+
+// Versions is the body replied to `GET /versions`
+type Versions struct {
+	Versions []string `json:"versions"`
+}
+
+// ErrorCode identifies why a request failed
+type ErrorCode string
+
+const (
+	// ErrorCodeInvalidRequest means the request is malformed
+	ErrorCodeInvalidRequest ErrorCode = "invalid_request"
+	// ErrorCodeInvalidSignature means the BLS signature is not a point of the G1 group
+	ErrorCodeInvalidSignature ErrorCode = "invalid_signature"
+	// ErrorCodeSignatureRejected means the aggregator could not aggregate the signature, e.g. it doesn't verify
+	// or the operator already signed the task
+	ErrorCodeSignatureRejected ErrorCode = "signature_rejected"
+	// ErrorCodeTaskNotFound means the aggregator doesn't know the task, it may not have received its batch event yet
+	ErrorCodeTaskNotFound ErrorCode = "task_not_found"
+	// ErrorCodeTimeout means the signature was not processed in time
+	ErrorCodeTimeout ErrorCode = "timeout"
+	// ErrorCodeUnsupportedVersion means the path is under a version of the API not served by the aggregator
+	ErrorCodeUnsupportedVersion ErrorCode = "unsupported_version"
+	// ErrorCodeNotFound means the path is not part of the API
+	ErrorCodeNotFound ErrorCode = "not_found"
+	// ErrorCodeMethodNotAllowed means the path doesn't accept the HTTP method
+	ErrorCodeMethodNotAllowed ErrorCode = "method_not_allowed"
+	// ErrorCodeInternal means the aggregator failed to handle the request
+	ErrorCodeInternal ErrorCode = "internal"
+)
+
+// Retryable returns whether the same request can succeed if sent again later
+func (c ErrorCode) Retryable() bool {
+	switch c {
+	case ErrorCodeTaskNotFound, ErrorCodeTimeout, ErrorCodeInternal:
+		return true
+	default:
+		return false
+	}
+}
+
+// httpStatus is the HTTP status replied with the error code
+func (c ErrorCode) httpStatus() int {
+	switch c {
+	case ErrorCodeInvalidRequest, ErrorCodeInvalidSignature:
+		return http.StatusBadRequest
+	case ErrorCodeSignatureRejected:
+		return http.StatusUnprocessableEntity
+	case ErrorCodeTaskNotFound, ErrorCodeUnsupportedVersion, ErrorCodeNotFound:
+		return http.StatusNotFound
+	case ErrorCodeTimeout:
+		return http.StatusServiceUnavailable
+	case ErrorCodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error is the body replied to a failed request, and the error returned by Client
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// IsRetryable returns whether err is an Error with a retryable code, or not an Error at all,
+// like the errors of the connection to the aggregator
+func IsRetryable(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code.Retryable()
+	}
+	return true
+}
+
+// errorReply is the body of the replies of failed requests
+type errorReply struct {
+	Error *Error `json:"error"`
+}
+
+// FromSignedTaskResponse converts a signed task response to its JSON schema
+func FromSignedTaskResponse(signedTaskResponse *types.SignedTaskResponse) (*SignedTaskResponse, error) {
+	if signedTaskResponse.BlsSignature.G1Point == nil {
+		return nil, NewError(ErrorCodeInvalidSignature, "nil signature")
+	}
+	return &SignedTaskResponse{
+		BatchIdentifierHash: common.Hash(signedTaskResponse.BatchIdentifierHash),
+		BatchMerkleRoot:     common.Hash(signedTaskResponse.BatchMerkleRoot),
+		SenderAddress:       common.Address(signedTaskResponse.SenderAddress),
+		OperatorId:          common.Hash(signedTaskResponse.OperatorId),
+		BlsSignature:        "0x" + hex.EncodeToString(signedTaskResponse.BlsSignature.Serialize()),
+	}, nil
+}
+
+// ToSignedTaskResponse converts the signed task response back, checking its signature is a point of the G1 group
+func (r *SignedTaskResponse) ToSignedTaskResponse() (*types.SignedTaskResponse, error) {
+	serialized, err := hex.DecodeString(strings.TrimPrefix(r.BlsSignature, "0x"))
+	if err != nil {
+		return nil, NewError(ErrorCodeInvalidSignature, "signature is not hex: "+err.Error())
+	}
+	if len(serialized) != 64 {
+		return nil, NewError(ErrorCodeInvalidSignature, fmt.Sprintf("expected a 64 bytes signature, got %d", len(serialized)))
+	}
+	point := new(bls.G1Point).Deserialize(serialized)
+	if !point.IsOnCurve() || !point.IsInSubGroup() {
+		return nil, NewError(ErrorCodeInvalidSignature, "signature is not a point of the BN254 G1 group")
+	}
+	return &types.SignedTaskResponse{
+		BatchMerkleRoot:     r.BatchMerkleRoot,
+		SenderAddress:       r.SenderAddress,
+		BatchIdentifierHash: r.BatchIdentifierHash,
+		BlsSignature:        bls.Signature{G1Point: point},
+		OperatorId:          eigentypes.OperatorId(r.OperatorId),
+	}, nil
+}
@@ -0,0 +1,165 @@
+package aggregatorapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// fakeService accepts the signed task responses of known tasks, recording them
+type fakeService struct {
+	tasks    map[[32]byte]*TaskStatus
+	received []*types.SignedTaskResponse
+	err      error
+}
+
+func (s *fakeService) ProcessSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) (TaskState, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	status, ok := s.tasks[signedTaskResponse.BatchIdentifierHash]
+	if !ok {
+		return "", NewError(ErrorCodeTaskNotFound, "unknown task")
+	}
+	s.received = append(s.received, signedTaskResponse)
+	return status.State, nil
+}
+
+func (s *fakeService) GetTaskStatus(batchIdentifierHash [32]byte) (*TaskStatus, error) {
+	status, ok := s.tasks[batchIdentifierHash]
+	if !ok {
+		return nil, NewError(ErrorCodeTaskNotFound, "unknown task")
+	}
+	return status, nil
+}
+
+func newTestClient(t *testing.T, service Service) (*Client, string) {
+	t.Helper()
+	server := httptest.NewServer(NewHandler(service))
+	t.Cleanup(server.Close)
+	address := strings.TrimPrefix(server.URL, "http://")
+	return NewClient(address, server.Client()), server.URL
+}
+
+func newTestSignedTaskResponse(t *testing.T, batchIdentifierHash [32]byte) *types.SignedTaskResponse {
+	t.Helper()
+	keyPair, err := bls.NewKeyPairFromString("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.SignedTaskResponse{
+		BatchMerkleRoot:     [32]byte{1},
+		SenderAddress:       [20]byte{2},
+		BatchIdentifierHash: batchIdentifierHash,
+		BlsSignature:        *keyPair.SignMessage([32]byte{1}),
+		OperatorId:          [32]byte{3},
+	}
+}
+
+func TestSubmitSignedTaskResponse(t *testing.T) {
+	batchIdentifierHash := [32]byte{4}
+	service := &fakeService{tasks: map[[32]byte]*TaskStatus{
+		batchIdentifierHash: {BatchIdentifierHash: batchIdentifierHash, TaskCreatedBlock: 10, State: TaskStatePending},
+	}}
+	client, _ := newTestClient(t, service)
+	if err := client.CheckVersion(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	signedTaskResponse := newTestSignedTaskResponse(t, batchIdentifierHash)
+	state, err := client.SubmitSignedTaskResponse(context.Background(), signedTaskResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != TaskStatePending {
+		t.Errorf("expected the task state to be replied, got %q", state)
+	}
+	if len(service.received) != 1 {
+		t.Fatalf("expected the signed task response to be received, got %d", len(service.received))
+	}
+	received := service.received[0]
+	if received.BatchMerkleRoot != signedTaskResponse.BatchMerkleRoot || received.SenderAddress != signedTaskResponse.SenderAddress ||
+		received.OperatorId != signedTaskResponse.OperatorId || !received.BlsSignature.G1Point.Equal(signedTaskResponse.BlsSignature.G1Point.G1Affine) {
+		t.Errorf("expected the signed task response to be received as sent, got %+v", received)
+	}
+
+	status, err := client.GetTaskStatus(context.Background(), batchIdentifierHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TaskCreatedBlock != 10 || status.State != TaskStatePending {
+		t.Errorf("expected the status of the task, got %+v", status)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	service := &fakeService{}
+	client, url := newTestClient(t, service)
+
+	var apiErr *Error
+	_, err := client.SubmitSignedTaskResponse(context.Background(), newTestSignedTaskResponse(t, [32]byte{5}))
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeTaskNotFound || !IsRetryable(err) {
+		t.Errorf("expected a retryable task_not_found error, got %v", err)
+	}
+	if _, err := client.GetTaskStatus(context.Background(), [32]byte{5}); !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeTaskNotFound {
+		t.Errorf("expected a task_not_found error, got %v", err)
+	}
+
+	_, err = client.SubmitSignedTaskResponse(context.Background(), &types.SignedTaskResponse{})
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeInvalidSignature || IsRetryable(err) {
+		t.Errorf("expected a nil signature to be rejected, got %v", err)
+	}
+
+	service.err = errors.New("boom")
+	_, err = client.SubmitSignedTaskResponse(context.Background(), newTestSignedTaskResponse(t, [32]byte{5}))
+	if !errors.As(err, &apiErr) || apiErr.Code != ErrorCodeInternal {
+		t.Errorf("expected other errors of the service to be internal errors, got %v", err)
+	}
+
+	requests := map[string]struct {
+		method   string
+		path     string
+		body     string
+		status   int
+		expected ErrorCode
+	}{
+		"invalid signature": {http.MethodPost, SignedTaskResponsesPath, `{"bls_signature":"0x1234"}`, http.StatusBadRequest, ErrorCodeInvalidSignature},
+		"unknown field":     {http.MethodPost, SignedTaskResponsesPath, `{"reply":1}`, http.StatusBadRequest, ErrorCodeInvalidRequest},
+		"wrong method":      {http.MethodGet, SignedTaskResponsesPath, "", http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed},
+		"invalid task":      {http.MethodGet, TasksPath + "0x12", "", http.StatusBadRequest, ErrorCodeInvalidRequest},
+		"other version":     {http.MethodPost, "/v2/signed-task-responses", "{}", http.StatusNotFound, ErrorCodeUnsupportedVersion},
+		"unknown path":      {http.MethodGet, "/_goRPC_", "", http.StatusNotFound, ErrorCodeNotFound},
+	}
+	for name, request := range requests {
+		httpRequest, err := http.NewRequest(request.method, url+request.path, strings.NewReader(request.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := http.DefaultClient.Do(httpRequest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := new(bytes.Buffer)
+		_, _ = body.ReadFrom(response.Body)
+		response.Body.Close()
+		if response.StatusCode != request.status || !strings.Contains(body.String(), `"code":"`+string(request.expected)+`"`) {
+			t.Errorf("%s: expected %d %s, got %d %s", name, request.status, request.expected, response.StatusCode, body.String())
+		}
+	}
+}
+
+func TestClientOfAggregatorWithoutApi(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	client := NewClient(strings.TrimPrefix(server.URL, "http://"), server.Client())
+	if err := client.CheckVersion(context.Background()); err == nil {
+		t.Error("expected the version check to fail on an aggregator not serving the API")
+	}
+}
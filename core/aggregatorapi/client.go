@@ -0,0 +1,101 @@
+package aggregatorapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// maxReplyBytes bounds the body of the replies read from the aggregator
+const maxReplyBytes = 1 << 16
+
+// Client calls the API of the aggregator at an ip:port address.
+// Requests fail with an Error if the aggregator replied one.
+type Client struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the aggregator at aggregatorIpPortAddr, sending the requests with httpClient
+func NewClient(aggregatorIpPortAddr string, httpClient *http.Client) *Client {
+	return &Client{baseUrl: "http://" + aggregatorIpPortAddr, httpClient: httpClient}
+}
+
+// CheckVersion checks the aggregator serves this version of the API
+func (c *Client) CheckVersion(ctx context.Context) error {
+	var versions Versions
+	if err := c.do(ctx, http.MethodGet, VersionsPath, nil, &versions); err != nil {
+		return fmt.Errorf("could not get the API versions of the aggregator: %w", err)
+	}
+	if !slices.Contains(versions.Versions, Version) {
+		return NewError(ErrorCodeUnsupportedVersion, fmt.Sprintf("the aggregator serves the API versions %v, not %s", versions.Versions, Version))
+	}
+	return nil
+}
+
+// SubmitSignedTaskResponse sends the signed task response to the aggregator, returning the state of its task once processed
+func (c *Client) SubmitSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) (TaskState, error) {
+	request, err := FromSignedTaskResponse(signedTaskResponse)
+	if err != nil {
+		return "", err
+	}
+	var reply SignedTaskResponseReply
+	if err := c.do(ctx, http.MethodPost, SignedTaskResponsesPath, request, &reply); err != nil {
+		return "", err
+	}
+	return reply.Status, nil
+}
+
+// GetTaskStatus returns the status of the task of batchIdentifierHash in the aggregator
+func (c *Client) GetTaskStatus(ctx context.Context, batchIdentifierHash [32]byte) (*TaskStatus, error) {
+	var status TaskStatus
+	if err := c.do(ctx, http.MethodGet, TasksPath+hexutil.Encode(batchIdentifierHash[:]), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, request any, reply any) error {
+	var body io.Reader
+	if request != nil {
+		encoded, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, body)
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	decoder := json.NewDecoder(io.LimitReader(response.Body, maxReplyBytes))
+
+	if response.StatusCode != http.StatusOK {
+		var failed errorReply
+		if err := decoder.Decode(&failed); err != nil || failed.Error == nil {
+			// Not a reply of the API, e.g. an aggregator only serving the gob RPC
+			return fmt.Errorf("unexpected reply from the aggregator: %s", response.Status)
+		}
+		return failed.Error
+	}
+	if err := decoder.Decode(reply); err != nil {
+		return fmt.Errorf("invalid reply from the aggregator: %w", err)
+	}
+	return nil
+}
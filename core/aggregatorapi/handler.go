@@ -0,0 +1,125 @@
+package aggregatorapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// maxRequestBytes bounds the body of the requests, signed task responses take a few hundred bytes
+const maxRequestBytes = 1 << 16
+
+// Service is what the aggregator does for the requests of the API.
+// Errors can be an Error to reply its code, any other error is replied as ErrorCodeInternal.
+type Service interface {
+	// ProcessSignedTaskResponse aggregates the signature of the response, returning its task state once done
+	ProcessSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) (TaskState, error)
+	// GetTaskStatus returns the status of the task, or ErrorCodeTaskNotFound
+	GetTaskStatus(batchIdentifierHash [32]byte) (*TaskStatus, error)
+}
+
+// Handler serves the API over HTTP, see Service
+type Handler struct {
+	service Service
+	mux     *http.ServeMux
+}
+
+func NewHandler(service Service) *Handler {
+	h := &Handler{service: service, mux: http.NewServeMux()}
+	h.mux.HandleFunc(VersionsPath, h.versions)
+	h.mux.HandleFunc(SignedTaskResponsesPath, h.signedTaskResponses)
+	h.mux.HandleFunc(TasksPath, h.tasks)
+	h.mux.HandleFunc("/", h.notFound)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) versions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, NewError(ErrorCodeMethodNotAllowed, "expected GET"))
+		return
+	}
+	writeReply(w, Versions{Versions: []string{Version}})
+}
+
+func (h *Handler) signedTaskResponses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, NewError(ErrorCodeMethodNotAllowed, "expected POST"))
+		return
+	}
+
+	var request SignedTaskResponse
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, NewError(ErrorCodeInvalidRequest, "invalid signed task response: "+err.Error()))
+		return
+	}
+	signedTaskResponse, err := request.ToSignedTaskResponse()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	state, err := h.service.ProcessSignedTaskResponse(r.Context(), signedTaskResponse)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeReply(w, SignedTaskResponseReply{Status: state})
+}
+
+func (h *Handler) tasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, NewError(ErrorCodeMethodNotAllowed, "expected GET"))
+		return
+	}
+
+	encoded := strings.TrimPrefix(r.URL.Path, TasksPath)
+	batchIdentifierHash, err := hexutil.Decode(encoded)
+	if err != nil || len(batchIdentifierHash) != 32 {
+		writeError(w, NewError(ErrorCodeInvalidRequest, "expected a 32 bytes batch identifier hash as 0x prefixed hex, got "+encoded))
+		return
+	}
+
+	status, err := h.service.GetTaskStatus([32]byte(batchIdentifierHash))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeReply(w, status)
+}
+
+// notFound tells apart the paths under another version of the API from the unknown ones
+func (h *Handler) notFound(w http.ResponseWriter, r *http.Request) {
+	version, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(version) > 1 && version[0] == 'v' && version != Version {
+		writeError(w, NewError(ErrorCodeUnsupportedVersion, "unsupported API version "+version+", the aggregator serves "+Version))
+		return
+	}
+	writeError(w, NewError(ErrorCodeNotFound, "unknown path "+r.URL.Path))
+}
+
+func writeReply(w http.ResponseWriter, reply any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = NewError(ErrorCodeInternal, err.Error())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Code.httpStatus())
+	json.NewEncoder(w).Encode(errorReply{Error: apiErr})
+}
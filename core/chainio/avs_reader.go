@@ -15,6 +15,7 @@ import (
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
 	sdkavsregistry "github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
 	"github.com/Layr-Labs/eigensdk-go/logging"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
 )
 
 type AvsReader struct {
@@ -71,6 +72,54 @@ func (r *AvsReader) IsOperatorRegistered(address ethcommon.Address) (bool, error
 	return r.ChainReader.IsOperatorRegistered(&bind.CallOpts{}, address)
 }
 
+// OperatorRegistration is the state of an operator in the registry coordinator of the AVS
+type OperatorRegistration struct {
+	Address    ethcommon.Address
+	Registered bool
+	// OperatorId is zero if the operator never registered
+	OperatorId [32]byte
+	// QuorumBitmap has a bit set for each quorum the operator is registered in
+	QuorumBitmap *big.Int
+	// Stakes is the current stake of the operator in each quorum it's registered in
+	Stakes map[eigentypes.QuorumNum]*big.Int
+}
+
+// GetOperatorRegistration queries the registration of the operator with address, along with its stake in each quorum
+func (r *AvsReader) GetOperatorRegistration(address ethcommon.Address) (OperatorRegistration, error) {
+	registration := OperatorRegistration{Address: address, QuorumBitmap: new(big.Int)}
+
+	registered, err := r.ChainReader.IsOperatorRegistered(&bind.CallOpts{}, address)
+	if err != nil {
+		return registration, err
+	}
+	registration.Registered = registered
+
+	registration.OperatorId, err = r.ChainReader.GetOperatorId(&bind.CallOpts{}, address)
+	if err != nil {
+		return registration, err
+	}
+
+	quorums, err := r.ChainReader.QueryRegistrationDetail(&bind.CallOpts{}, address)
+	if err != nil {
+		return registration, err
+	}
+	for quorum, inQuorum := range quorums {
+		if inQuorum {
+			registration.QuorumBitmap.SetBit(registration.QuorumBitmap, quorum, 1)
+		}
+	}
+
+	if !registered {
+		return registration, nil
+	}
+	stakes, err := r.ChainReader.GetOperatorStakeInQuorumsOfOperatorAtCurrentBlock(&bind.CallOpts{}, registration.OperatorId)
+	if err != nil {
+		return registration, err
+	}
+	registration.Stakes = stakes
+	return registration, nil
+}
+
 func (r *AvsReader) DisabledVerifiers() (*big.Int, error) {
 	return r.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.DisabledVerifiers(&bind.CallOpts{})
 }
@@ -109,6 +158,15 @@ func (r *AvsReader) GetNotRespondedTasksFrom(fromBlock uint64) ([]servicemanager
 	return tasks, nil
 }
 
+// Returns whether the batch with the given identifier hash was already responded on chain
+func (r *AvsReader) IsBatchResponded(batchIdentifierHash [32]byte) (bool, error) {
+	state, err := r.AvsContractBindings.ServiceManager.ContractAlignedLayerServiceManagerCaller.BatchesState(nil, batchIdentifierHash)
+	if err != nil {
+		return false, err
+	}
+	return state.Responded, nil
+}
+
 // This function is a helper to get a task hash of aproximately nBlocksOld blocks ago
 func (r *AvsReader) GetOldTaskHash(nBlocksOld uint64, interval uint64) (*[32]byte, error) {
 	latestBlock, err := r.AvsContractBindings.ethClient.BlockNumber(context.Background())
@@ -150,3 +208,18 @@ func (r *AvsReader) GetOldTaskHash(nBlocksOld uint64, interval uint64) (*[32]byt
 	batchIdentifierHash := *(*[32]byte)(crypto.Keccak256(batchIdentifier))
 	return &batchIdentifierHash, nil
 }
+
+// Returns all the "NewBatchV3" logs of the batches with the given merkle root, starting from the given block number
+func (r *AvsReader) GetNewBatchV3Logs(batchMerkleRoot [32]byte, fromBlock uint64) ([]servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	logs, err := r.AvsContractBindings.ServiceManager.FilterNewBatchV3(&bind.FilterOpts{Start: fromBlock, End: nil, Context: context.Background()}, [][32]byte{batchMerkleRoot})
+	if err != nil {
+		return nil, err
+	}
+	defer logs.Close()
+
+	var batches []servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	for logs.Next() {
+		batches = append(batches, *logs.Event)
+	}
+	return batches, logs.Error()
+}
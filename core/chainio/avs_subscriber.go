@@ -61,18 +61,20 @@ func NewAvsSubscriberFromConfig(baseConfig *config.BaseConfig) (*AvsSubscriber,
 	}, nil
 }
 
-func (s *AvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) (chan error, error) {
+// SubscribeToNewTasksV2 forwards new V2 batches to newTaskCreatedChan until ctx is done, resubscribing on errors.
+// The returned channel receives the errors of failed resubscriptions.
+func (s *AvsSubscriber) SubscribeToNewTasksV2(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) (chan error, error) {
 	// Create a new channel to receive new tasks
 	internalChannel := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2)
 
 	// Subscribe to new tasks
-	sub, err := SubscribeToNewTasksV2Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
+	sub, err := SubscribeToNewTasksV2Retryable(&bind.WatchOpts{Context: ctx}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
 	if err != nil {
 		s.logger.Error("Primary failed to subscribe to new AlignedLayer V2 tasks after %d retries", retry.NetworkNumRetries, "err", err)
 		return nil, err
 	}
 
-	subFallback, err := SubscribeToNewTasksV2Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
+	subFallback, err := SubscribeToNewTasksV2Retryable(&bind.WatchOpts{Context: ctx}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
 	if err != nil {
 		s.logger.Error("Fallback failed to subscribe to new AlignedLayer V2 tasks after %d retries", retry.NetworkNumRetries, "err", err)
 		return nil, err
@@ -91,16 +93,18 @@ func (s *AvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicema
 		batchesSet := make(map[[32]byte]struct{})
 		for {
 			select {
+			case <-ctx.Done():
+				return
 			case newBatch := <-internalChannel:
-				s.processNewBatchV2(newBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
+				s.processNewBatchV2(ctx, newBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
 			case <-pollLatestBatchTicker.C:
-				latestBatch, err := s.getLatestNotRespondedTaskFromEthereumV2()
+				latestBatch, err := s.getLatestNotRespondedTaskFromEthereumV2(ctx)
 				if err != nil {
 					s.logger.Debug("Failed to get latest task from blockchain", "err", err)
 					continue
 				}
 				if latestBatch != nil {
-					s.processNewBatchV2(latestBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
+					s.processNewBatchV2(ctx, latestBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
 				}
 			}
 		}
@@ -111,19 +115,29 @@ func (s *AvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicema
 	go func() {
 		for {
 			select {
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				subFallback.Unsubscribe()
+				return
 			case err := <-sub.Err():
 				s.logger.Warn("Error in new task subscription", "err", err)
 				sub.Unsubscribe()
-				sub, err = SubscribeToNewTasksV2Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
+				sub, err = SubscribeToNewTasksV2Retryable(&bind.WatchOpts{Context: ctx}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
 				if err != nil {
-					errorChannel <- err
+					select {
+					case errorChannel <- err:
+					case <-ctx.Done():
+					}
 				}
 			case err := <-subFallback.Err():
 				s.logger.Warn("Error in fallback new task subscription", "err", err)
 				subFallback.Unsubscribe()
-				subFallback, err = SubscribeToNewTasksV2Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
+				subFallback, err = SubscribeToNewTasksV2Retryable(&bind.WatchOpts{Context: ctx}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
 				if err != nil {
-					errorChannel <- err
+					select {
+					case errorChannel <- err:
+					case <-ctx.Done():
+					}
 				}
 			}
 		}
@@ -132,18 +146,20 @@ func (s *AvsSubscriber) SubscribeToNewTasksV2(newTaskCreatedChan chan *servicema
 	return errorChannel, nil
 }
 
-func (s *AvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) (chan error, error) {
+// SubscribeToNewTasksV3 forwards new V3 batches to newTaskCreatedChan until ctx is done, resubscribing on errors.
+// The returned channel receives the errors of failed resubscriptions.
+func (s *AvsSubscriber) SubscribeToNewTasksV3(ctx context.Context, newTaskCreatedChan chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) (chan error, error) {
 	// Create a new channel to receive new tasks
 	internalChannel := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3)
 
 	// Subscribe to new tasks
-	sub, err := SubscribeToNewTasksV3Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
+	sub, err := SubscribeToNewTasksV3Retryable(&bind.WatchOpts{Context: ctx}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
 	if err != nil {
 		s.logger.Error("Primary failed to subscribe to new AlignedLayer V3 tasks after %d retries", MaxRetries, "err", err)
 		return nil, err
 	}
 
-	subFallback, err := SubscribeToNewTasksV3Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
+	subFallback, err := SubscribeToNewTasksV3Retryable(&bind.WatchOpts{Context: ctx}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
 	if err != nil {
 		s.logger.Error("Fallback failed to subscribe to new AlignedLayer V3 tasks after %d retries", MaxRetries, "err", err)
 		return nil, err
@@ -162,16 +178,18 @@ func (s *AvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicema
 		batchesSet := make(map[[32]byte]struct{})
 		for {
 			select {
+			case <-ctx.Done():
+				return
 			case newBatch := <-internalChannel:
-				s.processNewBatchV3(newBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
+				s.processNewBatchV3(ctx, newBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
 			case <-pollLatestBatchTicker.C:
-				latestBatch, err := s.getLatestNotRespondedTaskFromEthereumV3()
+				latestBatch, err := s.getLatestNotRespondedTaskFromEthereumV3(ctx)
 				if err != nil {
 					s.logger.Debug("Failed to get latest task from blockchain", "err", err)
 					continue
 				}
 				if latestBatch != nil {
-					s.processNewBatchV3(latestBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
+					s.processNewBatchV3(ctx, latestBatch, batchesSet, newBatchMutex, newTaskCreatedChan)
 				}
 			}
 		}
@@ -182,19 +200,29 @@ func (s *AvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicema
 	go func() {
 		for {
 			select {
+			case <-ctx.Done():
+				sub.Unsubscribe()
+				subFallback.Unsubscribe()
+				return
 			case err := <-sub.Err():
 				s.logger.Warn("Error in new task subscription", "err", err)
 				sub.Unsubscribe()
-				sub, err = SubscribeToNewTasksV3Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
+				sub, err = SubscribeToNewTasksV3Retryable(&bind.WatchOpts{Context: ctx}, s.AvsContractBindings.ServiceManager, internalChannel, nil, retry.NetworkRetryParams())
 				if err != nil {
-					errorChannel <- err
+					select {
+					case errorChannel <- err:
+					case <-ctx.Done():
+					}
 				}
 			case err := <-subFallback.Err():
 				s.logger.Warn("Error in fallback new task subscription", "err", err)
 				subFallback.Unsubscribe()
-				subFallback, err = SubscribeToNewTasksV3Retryable(&bind.WatchOpts{}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
+				subFallback, err = SubscribeToNewTasksV3Retryable(&bind.WatchOpts{Context: ctx}, s.AvsContractBindings.ServiceManagerFallback, internalChannel, nil, retry.NetworkRetryParams())
 				if err != nil {
-					errorChannel <- err
+					select {
+					case errorChannel <- err:
+					case <-ctx.Done():
+					}
 				}
 			}
 		}
@@ -203,7 +231,7 @@ func (s *AvsSubscriber) SubscribeToNewTasksV3(newTaskCreatedChan chan *servicema
 	return errorChannel, nil
 }
 
-func (s *AvsSubscriber) processNewBatchV2(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, batchesSet map[[32]byte]struct{}, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) {
+func (s *AvsSubscriber) processNewBatchV2(ctx context.Context, batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, batchesSet map[[32]byte]struct{}, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) {
 	newBatchMutex.Lock()
 	defer newBatchMutex.Unlock()
 
@@ -217,7 +245,11 @@ func (s *AvsSubscriber) processNewBatchV2(batch *servicemanager.ContractAlignedL
 			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
 
 		batchesSet[batchIdentifierHash] = struct{}{}
-		newTaskCreatedChan <- batch
+		select {
+		case newTaskCreatedChan <- batch:
+		case <-ctx.Done():
+			return
+		}
 
 		// Remove the batch from the set after RemoveBatchFromSetInterval time
 		go func() {
@@ -229,7 +261,7 @@ func (s *AvsSubscriber) processNewBatchV2(batch *servicemanager.ContractAlignedL
 	}
 }
 
-func (s *AvsSubscriber) processNewBatchV3(batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, batchesSet map[[32]byte]struct{}, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
+func (s *AvsSubscriber) processNewBatchV3(ctx context.Context, batch *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, batchesSet map[[32]byte]struct{}, newBatchMutex *sync.Mutex, newTaskCreatedChan chan<- *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
 	newBatchMutex.Lock()
 	defer newBatchMutex.Unlock()
 
@@ -243,7 +275,11 @@ func (s *AvsSubscriber) processNewBatchV3(batch *servicemanager.ContractAlignedL
 			"batchIdentifierHash", hex.EncodeToString(batchIdentifierHash[:]))
 
 		batchesSet[batchIdentifierHash] = struct{}{}
-		newTaskCreatedChan <- batch
+		select {
+		case newTaskCreatedChan <- batch:
+		case <-ctx.Done():
+			return
+		}
 
 		// Remove the batch from the set after RemoveBatchFromSetInterval time
 		go func() {
@@ -256,9 +292,9 @@ func (s *AvsSubscriber) processNewBatchV3(batch *servicemanager.ContractAlignedL
 }
 
 // getLatestNotRespondedTaskFromEthereum queries the blockchain for the latest not responded task using the FilterNewBatch method.
-func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV2() (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, error) {
+func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV2(ctx context.Context) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, error) {
 
-	latestBlock, err := s.BlockNumberRetryable(context.Background(), retry.NetworkRetryParams())
+	latestBlock, err := s.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
 	if err != nil {
 		return nil, err
 	}
@@ -271,7 +307,7 @@ func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV2() (*servicemanag
 		fromBlock = latestBlock - BlockInterval
 	}
 
-	logs, err := s.FilterBatchV2Retryable(&bind.FilterOpts{Start: fromBlock, End: nil, Context: context.Background()}, nil, retry.NetworkRetryParams())
+	logs, err := s.FilterBatchV2Retryable(&bind.FilterOpts{Start: fromBlock, End: nil, Context: ctx}, nil, retry.NetworkRetryParams())
 	if err != nil {
 		return nil, err
 	}
@@ -306,8 +342,8 @@ func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV2() (*servicemanag
 }
 
 // getLatestNotRespondedTaskFromEthereum queries the blockchain for the latest not responded task using the FilterNewBatch method.
-func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV3() (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
-	latestBlock, err := s.BlockNumberRetryable(context.Background(), retry.NetworkRetryParams())
+func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV3(ctx context.Context) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	latestBlock, err := s.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
 	if err != nil {
 		return nil, err
 	}
@@ -320,7 +356,7 @@ func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV3() (*servicemanag
 		fromBlock = latestBlock - BlockInterval
 	}
 
-	logs, err := s.FilterBatchV3Retryable(&bind.FilterOpts{Start: fromBlock, End: nil, Context: context.Background()}, nil, retry.NetworkRetryParams())
+	logs, err := s.FilterBatchV3Retryable(&bind.FilterOpts{Start: fromBlock, End: nil, Context: ctx}, nil, retry.NetworkRetryParams())
 	if err != nil {
 		return nil, err
 	}
@@ -354,8 +390,9 @@ func (s *AvsSubscriber) getLatestNotRespondedTaskFromEthereumV3() (*servicemanag
 	return lastLog, nil
 }
 
-func (s *AvsSubscriber) WaitForOneBlock(startBlock uint64) error {
-	currentBlock, err := s.BlockNumberRetryable(context.Background(), retry.NetworkRetryParams())
+// WaitForOneBlock waits for a block after startBlock to be mined, or for ctx to be done
+func (s *AvsSubscriber) WaitForOneBlock(ctx context.Context, startBlock uint64) error {
+	currentBlock, err := s.BlockNumberRetryable(ctx, retry.NetworkRetryParams())
 	if err != nil {
 		return err
 	}
@@ -363,14 +400,18 @@ func (s *AvsSubscriber) WaitForOneBlock(startBlock uint64) error {
 	if currentBlock <= startBlock { // should really be == but just in case
 		// Subscribe to new head
 		c := make(chan *types.Header)
-		sub, err := s.SubscribeNewHeadRetryable(context.Background(), c, retry.NetworkRetryParams())
+		sub, err := s.SubscribeNewHeadRetryable(ctx, c, retry.NetworkRetryParams())
 		if err != nil {
 			return err
 		}
+		defer sub.Unsubscribe()
 
 		// Read channel for the new block
-		<-c
-		(sub).Unsubscribe()
+		select {
+		case <-c:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
 	return nil
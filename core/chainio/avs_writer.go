@@ -7,46 +7,48 @@ import (
 	"math/big"
 	"time"
 
-	"github.com/Layr-Labs/eigensdk-go/chainio/clients"
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/avsregistry"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/elcontracts"
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/eth"
+	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
+	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
 	"github.com/Layr-Labs/eigensdk-go/logging"
+	eigenmetrics "github.com/Layr-Labs/eigensdk-go/metrics"
 	"github.com/Layr-Labs/eigensdk-go/signer"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	retry "github.com/yetanotherco/aligned_layer/core"
 	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/ethsigner"
 	"github.com/yetanotherco/aligned_layer/core/utils"
 	"github.com/yetanotherco/aligned_layer/metrics"
 )
 
 type AvsWriter struct {
 	*avsregistry.ChainWriter
+	// ElChainWriter sends the transactions to the EigenLayer core contracts, like the delegation manager
+	ElChainWriter       *elcontracts.ChainWriter
 	AvsContractBindings *AvsServiceBindings
 	logger              logging.Logger
 	Signer              signer.Signer
 	Client              eth.InstrumentedClient
 	ClientFallback      eth.InstrumentedClient
 	metrics             *metrics.Metrics
+	// The clients used to register with the signature of an external signer, see RegisterOperatorWithTypedDataSigner
+	txMgr               txmgr.TxManager
+	elChainReader       *elcontracts.ChainReader
+	avsRegistryBindings *avsregistry.ContractBindings
+	chainId             *big.Int
 }
 
 func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.EcdsaConfig, metrics *metrics.Metrics) (*AvsWriter, error) {
 
-	buildAllConfig := clients.BuildAllConfig{
-		EthHttpUrl:                 baseConfig.EthRpcUrl,
-		EthWsUrl:                   baseConfig.EthWsUrl,
-		RegistryCoordinatorAddr:    baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr.String(),
-		OperatorStateRetrieverAddr: baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr.String(),
-		AvsName:                    "AlignedLayer",
-		PromMetricsIpPortAddress:   baseConfig.EigenMetricsIpPortAddress,
-	}
-
-	clients, err := clients.BuildAll(buildAllConfig, ecdsaConfig.PrivateKey, baseConfig.Logger)
-
+	clients, err := buildChainClients(baseConfig, ecdsaConfig)
 	if err != nil {
-		baseConfig.Logger.Error("Cannot build signer config", "err", err)
+		baseConfig.Logger.Error("Cannot build chain writers", "err", err)
 		return nil, err
 	}
 
@@ -57,22 +59,74 @@ func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.E
 		return nil, err
 	}
 
-	privateKeySigner, err := signer.NewPrivateKeySigner(ecdsaConfig.PrivateKey, baseConfig.ChainId)
-	if err != nil {
-		baseConfig.Logger.Error("Cannot create signer", "err", err)
-		return nil, err
-	}
-
-	chainWriter := clients.AvsRegistryChainWriter
-
 	return &AvsWriter{
-		ChainWriter:         chainWriter,
+		ChainWriter:         clients.avsRegistryWriter,
+		ElChainWriter:       clients.elChainWriter,
 		AvsContractBindings: avsServiceBindings,
 		logger:              baseConfig.Logger,
-		Signer:              privateKeySigner,
+		Signer:              ecdsaConfig.Signer,
 		Client:              baseConfig.EthRpcClient,
 		ClientFallback:      baseConfig.EthRpcClientFallback,
 		metrics:             metrics,
+		txMgr:               clients.txMgr,
+		elChainReader:       clients.elChainReader,
+		avsRegistryBindings: clients.avsRegistryBindings,
+		chainId:             baseConfig.ChainId,
+	}, nil
+}
+
+// chainClients are the clients of the AVS registry and EigenLayer contracts used by AvsWriter
+type chainClients struct {
+	avsRegistryWriter   *avsregistry.ChainWriter
+	avsRegistryBindings *avsregistry.ContractBindings
+	elChainWriter       *elcontracts.ChainWriter
+	elChainReader       *elcontracts.ChainReader
+	txMgr               txmgr.TxManager
+}
+
+// buildChainClients builds the clients of the AVS registry and EigenLayer contracts, sending their transactions
+// with the signer of ecdsaConfig, either the keystore or an external signer
+func buildChainClients(baseConfig *config.BaseConfig, ecdsaConfig *config.EcdsaConfig) (*chainClients, error) {
+	txWallet, err := wallet.NewPrivateKeyWallet(&baseConfig.EthRpcClient, ecdsaConfig.SignerV2, ecdsaConfig.Address, baseConfig.Logger)
+	if err != nil {
+		return nil, err
+	}
+	txMgr := txmgr.NewSimpleTxManager(txWallet, &baseConfig.EthRpcClient, baseConfig.Logger, ecdsaConfig.Address)
+
+	_, _, avsRegistryChainWriter, avsRegistryContractBindings, err := avsregistry.BuildClients(
+		avsregistry.Config{
+			RegistryCoordinatorAddress:    baseConfig.AlignedLayerDeploymentConfig.AlignedLayerRegistryCoordinatorAddr,
+			OperatorStateRetrieverAddress: baseConfig.AlignedLayerDeploymentConfig.AlignedLayerOperatorStateRetrieverAddr,
+		},
+		&baseConfig.EthRpcClient,
+		&baseConfig.EthWsClient,
+		txMgr,
+		baseConfig.Logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	eigenMetrics := eigenmetrics.NewEigenMetrics("AlignedLayer", baseConfig.EigenMetricsIpPortAddress, prometheus.NewRegistry(), baseConfig.Logger)
+	elChainReader, elChainWriter, _, err := elcontracts.BuildClients(
+		elcontracts.Config{
+			DelegationManagerAddress: avsRegistryContractBindings.DelegationManagerAddr,
+			AvsDirectoryAddress:      avsRegistryContractBindings.AvsDirectoryAddr,
+		},
+		&baseConfig.EthRpcClient,
+		txMgr,
+		baseConfig.Logger,
+		eigenMetrics,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &chainClients{
+		avsRegistryWriter:   avsRegistryChainWriter,
+		avsRegistryBindings: avsRegistryContractBindings,
+		elChainWriter:       elChainWriter,
+		elChainReader:       elChainReader,
+		txMgr:               txMgr,
 	}, nil
 }
 
@@ -89,8 +143,11 @@ func NewAvsWriterFromConfig(baseConfig *config.BaseConfig, ecdsaConfig *config.E
 //   - If no receipt is found, but the batch state indicates the response has already been processed, it exits
 //     without an error (returning `nil, nil`).
 //   - An error if the process encounters a fatal issue (e.g., permanent failure in verifying balances or state).
-func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, gasBumpPercentage uint, gasBumpIncrementalPercentage uint, gasBumpPercentageLimit uint, timeToWaitBeforeBump time.Duration, metrics *metrics.Metrics, onSetGasPrice func(*big.Int)) (*types.Receipt, error) {
-	txOpts := *w.Signer.GetTxOpts()
+//
+// Once ctx is done no new transaction is sent, and the error of ctx is returned
+// if none of the sent transactions was included yet.
+func (w *AvsWriter) SendAggregatedResponse(ctx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, nonSignerStakesAndSignature servicemanager.IBLSSignatureCheckerNonSignerStakesAndSignature, gasBumpPercentage uint, gasBumpIncrementalPercentage uint, gasBumpPercentageLimit uint, timeToWaitBeforeBump time.Duration, metrics *metrics.Metrics, onSetGasPrice func(*big.Int)) (*types.Receipt, error) {
+	txOpts := *ethsigner.TxOptsWithContext(ctx, w.Signer)
 	txOpts.NoSend = true // simulate the transaction
 	simTx, err := w.RespondToTaskV2Retryable(&txOpts, batchMerkleRoot, senderAddress, nonSignerStakesAndSignature, retry.SendToChainRetryParams())
 	if err != nil {
@@ -146,9 +203,9 @@ func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMe
 		if i > 0 {
 			w.logger.Infof("Trying to get old sent transaction receipt before sending a new transaction", "merkle root", batchMerkleRootHashString)
 			for _, tx := range sentTxs {
-				receipt, _ := w.Client.TransactionReceipt(context.Background(), tx.Hash())
+				receipt, _ := w.Client.TransactionReceipt(ctx, tx.Hash())
 				if receipt == nil {
-					receipt, _ = w.ClientFallback.TransactionReceipt(context.Background(), tx.Hash())
+					receipt, _ = w.ClientFallback.TransactionReceipt(ctx, tx.Hash())
 					if receipt != nil {
 						w.updateAggregatorGasCostMetrics(receipt, batchIdentifierHash)
 						return receipt, nil
@@ -156,7 +213,7 @@ func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMe
 				}
 			}
 			w.logger.Infof("Receipts for old transactions not found, will check if the batch state has been responded", "merkle root", batchMerkleRootHashString)
-			batchState, _ := w.BatchesStateRetryable(&bind.CallOpts{}, batchIdentifierHash, retry.NetworkRetryParams())
+			batchState, _ := w.BatchesStateRetryable(&bind.CallOpts{Context: ctx}, batchIdentifierHash, retry.NetworkRetryParams())
 			if batchState.Responded {
 				w.logger.Infof("Batch state has been already responded", "merkle root", batchMerkleRootHashString)
 				return nil, nil
@@ -166,6 +223,11 @@ func (w *AvsWriter) SendAggregatedResponse(batchIdentifierHash [32]byte, batchMe
 			metrics.IncBumpedGasPriceForAggregatedResponse()
 		}
 
+		if err := ctx.Err(); err != nil {
+			w.logger.Infof("Not sending a new RespondToTask transaction, shutting down", "merkle root", batchMerkleRootHashString)
+			return nil, retry.PermanentError{Inner: err}
+		}
+
 		// We compare both Aggregator funds and Batcher balance in Aligned against respondToTaskFeeLimit
 		// Both are required to have some balance, more details inside the function
 		err = w.checkAggAndBatcherHaveEnoughBalance(simTx, txOpts, batchIdentifierHash, senderAddress)
@@ -0,0 +1,140 @@
+package chainio
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	chainioutils "github.com/Layr-Labs/eigensdk-go/chainio/utils"
+	regcoord "github.com/Layr-Labs/eigensdk-go/contracts/bindings/RegistryCoordinator"
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// OperatorAvsRegistrationSigValidity is how long the AVS registration signature of the operator is valid for
+const OperatorAvsRegistrationSigValidity = 60 * 60 // 1 hour, in seconds
+
+// TypedDataSigner signs EIP-712 typed data with the key of Address, like ethsigner.ExternalSigner
+type TypedDataSigner interface {
+	Address() common.Address
+	SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error)
+}
+
+// operatorAvsRegistrationTypedData is the OperatorAVSRegistration message the AVSDirectory of EigenLayer
+// expects the operator to sign, in the EIP712 domain of the AVSDirectory
+func operatorAvsRegistrationTypedData(chainId *big.Int, avsDirectory common.Address, operator common.Address, avs common.Address, salt [32]byte, expiry *big.Int) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"OperatorAVSRegistration": {
+				{Name: "operator", Type: "address"},
+				{Name: "avs", Type: "address"},
+				{Name: "salt", Type: "bytes32"},
+				{Name: "expiry", Type: "uint256"},
+			},
+		},
+		PrimaryType: "OperatorAVSRegistration",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "EigenLayer",
+			ChainId:           (*math.HexOrDecimal256)(chainId),
+			VerifyingContract: avsDirectory.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"operator": operator.Hex(),
+			"avs":      avs.Hex(),
+			"salt":     hexutil.Encode(salt[:]),
+			"expiry":   expiry.String(),
+		},
+	}
+}
+
+// RegisterOperatorWithTypedDataSigner registers the operator like RegisterOperator of the AVS registry writer,
+// for an operator whose ECDSA key is held by typedDataSigner instead of being available as a private key.
+// The AVS registration message is signed as EIP-712 typed data, and checked against the digest
+// of the AVSDirectory before sending the transaction.
+func (w *AvsWriter) RegisterOperatorWithTypedDataSigner(
+	ctx context.Context,
+	typedDataSigner TypedDataSigner,
+	blsKeyPair *bls.KeyPair,
+	quorumNumbers eigentypes.QuorumNums,
+	socket string,
+	operatorToAvsRegistrationSigSalt [32]byte,
+) (*types.Receipt, error) {
+	operatorAddr := typedDataSigner.Address()
+	bindings := w.avsRegistryBindings
+	w.logger.Info("Registering operator with the AVS registry coordinator", "operator", operatorAddr, "quorumNumbers", quorumNumbers, "socket", socket)
+
+	// params to register the BLS pubkey with the BLS apk registry
+	g1HashedMsgToSign, err := bindings.RegistryCoordinator.PubkeyRegistrationMessageHash(&bind.CallOpts{Context: ctx}, operatorAddr)
+	if err != nil {
+		return nil, err
+	}
+	signedMsg := chainioutils.ConvertToBN254G1Point(
+		blsKeyPair.SignHashedToCurveMessage(chainioutils.ConvertBn254GethToGnark(g1HashedMsgToSign)).G1Point,
+	)
+	pubkeyRegParams := regcoord.IBLSApkRegistryPubkeyRegistrationParams{
+		PubkeyRegistrationSignature: signedMsg,
+		PubkeyG1:                    chainioutils.ConvertToBN254G1Point(blsKeyPair.GetPubKeyG1()),
+		PubkeyG2:                    chainioutils.ConvertToBN254G2Point(blsKeyPair.GetPubKeyG2()),
+	}
+
+	// params to register the operator in the operator-avs mapping of the AVSDirectory
+	header, err := w.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	expiry := new(big.Int).SetUint64(header.Time + OperatorAvsRegistrationSigValidity)
+	typedData := operatorAvsRegistrationTypedData(w.chainId, bindings.AvsDirectoryAddr, operatorAddr, bindings.ServiceManagerAddr, operatorToAvsRegistrationSigSalt, expiry)
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	expectedDigest, err := w.elChainReader.CalculateOperatorAVSRegistrationDigestHash(ctx, operatorAddr, bindings.ServiceManagerAddr, operatorToAvsRegistrationSigSalt, expiry)
+	if err != nil {
+		return nil, err
+	}
+	if common.BytesToHash(digest) != common.Hash(expectedDigest) {
+		return nil, fmt.Errorf("the AVS registration digest 0x%x doesn't match the one of the AVSDirectory 0x%x", digest, expectedDigest)
+	}
+	operatorSignature, err := typedDataSigner.SignTypedData(ctx, typedData)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign the AVS registration: %w", err)
+	}
+	operatorSignatureWithSaltAndExpiry := regcoord.ISignatureUtilsSignatureWithSaltAndExpiry{
+		Signature: operatorSignature,
+		Salt:      operatorToAvsRegistrationSigSalt,
+		Expiry:    expiry,
+	}
+
+	noSendTxOpts, err := w.txMgr.GetNoSendTxOpts()
+	if err != nil {
+		return nil, err
+	}
+	noSendTxOpts.Context = ctx
+	tx, err := bindings.RegistryCoordinator.RegisterOperator(
+		noSendTxOpts,
+		quorumNumbers.UnderlyingType(),
+		socket,
+		pubkeyRegParams,
+		operatorSignatureWithSaltAndExpiry,
+	)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := w.txMgr.Send(ctx, tx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send tx with err: %w", err)
+	}
+	w.logger.Info("Successfully registered operator with the AVS registry coordinator", "txHash", receipt.TxHash.String(), "operator", operatorAddr)
+	return receipt, nil
+}
@@ -0,0 +1,46 @@
+package chainio
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// TestOperatorAvsRegistrationDigest checks the typed data hashes as AVSDirectory.calculateOperatorAVSRegistrationDigestHash
+func TestOperatorAvsRegistrationDigest(t *testing.T) {
+	chainId := big.NewInt(17000)
+	avsDirectory := common.HexToAddress("0x055733000064333CaDDbC92763c58BF0192fFeBf")
+	operator := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	avs := common.HexToAddress("0x58F280BeBE9B34c9939C3C39e0890C81f163B623")
+	salt := [32]byte{1, 2, 3}
+	expiry := big.NewInt(1718000000)
+
+	digest, _, err := apitypes.TypedDataAndHash(operatorAvsRegistrationTypedData(chainId, avsDirectory, operator, avs, salt, expiry))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	word := func(value []byte) []byte { return common.LeftPadBytes(value, 32) }
+	domainSeparator := crypto.Keccak256(
+		crypto.Keccak256([]byte("EIP712Domain(string name,uint256 chainId,address verifyingContract)")),
+		crypto.Keccak256([]byte("EigenLayer")),
+		word(chainId.Bytes()),
+		word(avsDirectory.Bytes()),
+	)
+	structHash := crypto.Keccak256(
+		crypto.Keccak256([]byte("OperatorAVSRegistration(address operator,address avs,bytes32 salt,uint256 expiry)")),
+		word(operator.Bytes()),
+		word(avs.Bytes()),
+		salt[:],
+		word(expiry.Bytes()),
+	)
+	expected := crypto.Keccak256([]byte("\x19\x01"), domainSeparator, structHash)
+
+	if !bytes.Equal(digest, expected) {
+		t.Errorf("expected the digest 0x%x of the AVSDirectory, got 0x%x", expected, digest)
+	}
+}
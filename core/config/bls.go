@@ -4,19 +4,35 @@ import (
 	"errors"
 	"log"
 	"os"
+	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
 	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 type BlsConfig struct {
-	KeyPair *bls.KeyPair
+	// KeyPair is nil if there's no keystore because a remote signer holds the key
+	KeyPair      *bls.KeyPair
+	RemoteSigner RemoteBlsSignerConfig
+}
+
+// RemoteBlsSignerConfig configures a signing service holding the BLS key, used instead of the keystore when Url is set
+type RemoteBlsSignerConfig struct {
+	Url       string `yaml:"url"`
+	AuthToken string `yaml:"auth_token"`
+	// CaCertPath is the CA certificate of the signer, if not trusted by the system
+	CaCertPath string `yaml:"ca_cert_path"`
+	// ClientCertPath and ClientKeyPath authenticate the operator with TLS client certificates
+	ClientCertPath string        `yaml:"client_cert_path"`
+	ClientKeyPath  string        `yaml:"client_key_path"`
+	Timeout        time.Duration `yaml:"timeout"`
 }
 
 type BlsConfigFromYaml struct {
 	Bls struct {
-		PrivateKeyStorePath     string `yaml:"private_key_store_path"`
-		PrivateKeyStorePassword string `yaml:"private_key_store_password"`
+		PrivateKeyStorePath     string                `yaml:"private_key_store_path"`
+		PrivateKeyStorePassword string                `yaml:"private_key_store_password"`
+		RemoteSigner            RemoteBlsSignerConfig `yaml:"remote_signer"`
 	} `yaml:"bls"`
 }
 
@@ -32,6 +48,9 @@ func NewBlsConfig(blsConfigFilePath string) *BlsConfig {
 	}
 
 	if blsConfigFromYaml.Bls.PrivateKeyStorePath == "" {
+		if blsConfigFromYaml.Bls.RemoteSigner.Url != "" {
+			return &BlsConfig{RemoteSigner: blsConfigFromYaml.Bls.RemoteSigner}
+		}
 		log.Fatal("Bls private key store path is empty")
 	}
 
@@ -41,6 +60,7 @@ func NewBlsConfig(blsConfigFilePath string) *BlsConfig {
 	}
 
 	return &BlsConfig{
-		KeyPair: blsKeyPair,
+		KeyPair:      blsKeyPair,
+		RemoteSigner: blsConfigFromYaml.Bls.RemoteSigner,
 	}
 }
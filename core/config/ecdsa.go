@@ -9,18 +9,26 @@ import (
 
 	ecdsa2 "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
 	"github.com/Layr-Labs/eigensdk-go/signer"
+	"github.com/Layr-Labs/eigensdk-go/signerv2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/yetanotherco/aligned_layer/core/ethsigner"
 	"github.com/yetanotherco/aligned_layer/core/utils"
 )
 
 type EcdsaConfig struct {
+	// PrivateKey is nil if an external signer holds the key
 	PrivateKey *ecdsa.PrivateKey
 	Signer     signer.Signer
+	// SignerV2 signs the transactions of the EigenLayer clients, sent from Address
+	SignerV2 signerv2.SignerFn
+	Address  common.Address
 }
 
 type EcdsaConfigFromYaml struct {
 	Ecdsa struct {
-		PrivateKeyStorePath     string `yaml:"private_key_store_path"`
-		PrivateKeyStorePassword string `yaml:"private_key_store_password"`
+		PrivateKeyStorePath     string           `yaml:"private_key_store_path"`
+		PrivateKeyStorePassword string           `yaml:"private_key_store_password"`
+		ExternalSigner          ethsigner.Config `yaml:"external_signer"`
 	} `yaml:"ecdsa"`
 }
 
@@ -35,6 +43,18 @@ func NewEcdsaConfig(ecdsaConfigFilePath string, chainId *big.Int) *EcdsaConfig {
 		log.Fatal("Error reading ecdsa config: ", err)
 	}
 
+	if ecdsaConfigFromYaml.Ecdsa.ExternalSigner.Url != "" {
+		externalSigner, err := ethsigner.NewExternalSigner(ecdsaConfigFromYaml.Ecdsa.ExternalSigner, chainId)
+		if err != nil {
+			log.Fatal("Error creating external signer: ", err)
+		}
+		return &EcdsaConfig{
+			Signer:   externalSigner,
+			SignerV2: externalSigner.SignerV2(),
+			Address:  externalSigner.Address(),
+		}
+	}
+
 	if ecdsaConfigFromYaml.Ecdsa.PrivateKeyStorePath == "" {
 		log.Fatal("Ecdsa private key store path is empty")
 	}
@@ -49,8 +69,15 @@ func NewEcdsaConfig(ecdsaConfigFilePath string, chainId *big.Int) *EcdsaConfig {
 		log.Fatal("Error creating private key signer: ", err)
 	}
 
+	signerV2, address, err := signerv2.SignerFromConfig(signerv2.Config{PrivateKey: ecdsaKeyPair}, chainId)
+	if err != nil {
+		log.Fatal("Error creating private key signer: ", err)
+	}
+
 	return &EcdsaConfig{
 		PrivateKey: ecdsaKeyPair,
 		Signer:     privateKeySigner,
+		SignerV2:   signerV2,
+		Address:    address,
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 	"os"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/yetanotherco/aligned_layer/core/utils"
@@ -25,25 +26,123 @@ type OperatorConfig struct {
 		RegisterOperatorOnStartup     bool
 		EnableMetrics                 bool
 		MetricsIpPortAddress          string
+		StatusIpPortAddress           string
 		MaxBatchSize                  int64
 		LastProcessedBatchFilePath    string
+		TaskJournalFilePath           string
+		UnfinishedTaskRetention       time.Duration
+		VerificationMaxWorkers        int
+		VerificationWorkersLimits     map[string]int
+		VerificationMaxAbandoned      int
+		VerificationCacheSize         int
+		VerificationCacheDir          string
+		VerificationCacheDiskEntries  int
+		VerifyingKeyCacheMaxBytes     int64
+		VerificationTimeout           time.Duration
+		VerificationTimeouts          map[string]time.Duration
+		VerificationSandbox           bool
+		VerificationSandboxWorkers    int
+		VerificationSandboxMaxMemory  int64
+		VerificationSandboxCPUSeconds int64
+		BatchFetchers                 BatchFetchersConfig
+		BatchMirrors                  BatchMirrorsConfig
+		BatchCache                    BatchCacheConfig
+		Aggregators                   AggregatorsConfig
 	}
 }
 
+// BatchFetcherConfig is the timeout and retry policy of a batch data source. Zero values mean the defaults.
+type BatchFetcherConfig struct {
+	Timeout    time.Duration `yaml:"timeout"`
+	MaxRetries int           `yaml:"max_retries"`
+	RetryDelay time.Duration `yaml:"retry_delay"`
+}
+
+type ContentGatewayFetcherConfig struct {
+	BatchFetcherConfig `yaml:",inline"`
+	Url                string `yaml:"url"`
+}
+
+// FileFetcherConfig configures the file:// batch data source, which reads batches from the local filesystem.
+// It's meant for local testing, and disabled unless Enabled is set.
+type FileFetcherConfig struct {
+	BatchFetcherConfig `yaml:",inline"`
+	Enabled            bool `yaml:"enabled"`
+}
+
+type S3FetcherConfig struct {
+	BatchFetcherConfig `yaml:",inline"`
+	Endpoint           string `yaml:"endpoint"`
+	Region             string `yaml:"region"`
+	AccessKeyId        string `yaml:"access_key_id"`
+	SecretAccessKey    string `yaml:"secret_access_key"`
+}
+
+// BatchFetchersConfig configures the data sources batches are downloaded from, by BatchDataPointer scheme
+type BatchFetchersConfig struct {
+	Http           BatchFetcherConfig          `yaml:"http"`
+	File           FileFetcherConfig           `yaml:"file"`
+	ContentGateway ContentGatewayFetcherConfig `yaml:"content_gateway"`
+	S3             S3FetcherConfig             `yaml:"s3"`
+	// PartialDownloadsDir stores interrupted downloads to resume them
+	PartialDownloadsDir string `yaml:"partial_downloads_dir"`
+}
+
+// BatchMirrorsConfig lists base URLs serving copies of the batches, tried as "failover" (the default) or "race"
+type BatchMirrorsConfig struct {
+	Urls     []string `yaml:"urls"`
+	Strategy string   `yaml:"strategy"`
+}
+
+// BatchCacheConfig enables the on-disk cache of downloaded batches when Dir is set. MaxBytes defaults to 1 GiB.
+type BatchCacheConfig struct {
+	Dir      string `yaml:"dir"`
+	MaxBytes int64  `yaml:"max_bytes"`
+}
+
+// AggregatorsConfig lists more aggregators receiving the signed task responses, after `aggregator_rpc_server_ip_port_address`.
+// Responses are delivered to "all" of them (the default) or to the first one accepting them, in "priority" order,
+// over their "json" API (the default) or the legacy "gob" RPC.
+type AggregatorsConfig struct {
+	Addresses []string `yaml:"addresses"`
+	Delivery  string   `yaml:"delivery"`
+	Protocol  string   `yaml:"protocol"`
+}
+
 type OperatorConfigFromYaml struct {
 	Operator struct {
-		AggregatorServerIpPortAddress string         `yaml:"aggregator_rpc_server_ip_port_address"`
-		OperatorTrackerIpPortAddress  string         `yaml:"operator_tracker_ip_port_address"`
-		Address                       common.Address `yaml:"address"`
-		EarningsReceiverAddress       common.Address `yaml:"earnings_receiver_address"`
-		DelegationApproverAddress     common.Address `yaml:"delegation_approver_address"`
-		StakerOptOutWindowBlocks      int            `yaml:"staker_opt_out_window_blocks"`
-		MetadataUrl                   string         `yaml:"metadata_url"`
-		RegisterOperatorOnStartup     bool           `yaml:"register_operator_on_startup"`
-		EnableMetrics                 bool           `yaml:"enable_metrics"`
-		MetricsIpPortAddress          string         `yaml:"metrics_ip_port_address"`
-		MaxBatchSize                  int64          `yaml:"max_batch_size"`
-		LastProcessedBatchFilePath    string         `yaml:"last_processed_batch_filepath"`
+		AggregatorServerIpPortAddress string                   `yaml:"aggregator_rpc_server_ip_port_address"`
+		OperatorTrackerIpPortAddress  string                   `yaml:"operator_tracker_ip_port_address"`
+		Address                       common.Address           `yaml:"address"`
+		EarningsReceiverAddress       common.Address           `yaml:"earnings_receiver_address"`
+		DelegationApproverAddress     common.Address           `yaml:"delegation_approver_address"`
+		StakerOptOutWindowBlocks      int                      `yaml:"staker_opt_out_window_blocks"`
+		MetadataUrl                   string                   `yaml:"metadata_url"`
+		RegisterOperatorOnStartup     bool                     `yaml:"register_operator_on_startup"`
+		EnableMetrics                 bool                     `yaml:"enable_metrics"`
+		MetricsIpPortAddress          string                   `yaml:"metrics_ip_port_address"`
+		StatusIpPortAddress           string                   `yaml:"status_ip_port_address"`
+		MaxBatchSize                  int64                    `yaml:"max_batch_size"`
+		LastProcessedBatchFilePath    string                   `yaml:"last_processed_batch_filepath"`
+		TaskJournalFilePath           string                   `yaml:"task_journal_filepath"`
+		UnfinishedTaskRetention       time.Duration            `yaml:"unfinished_task_retention"`
+		VerificationMaxWorkers        int                      `yaml:"verification_max_workers"`
+		VerificationWorkersLimits     map[string]int           `yaml:"verification_workers_limits"`
+		VerificationMaxAbandoned      int                      `yaml:"verification_max_abandoned_verifiers"`
+		VerificationCacheSize         int                      `yaml:"verification_cache_size"`
+		VerificationCacheDir          string                   `yaml:"verification_cache_dir"`
+		VerificationCacheDiskEntries  int                      `yaml:"verification_cache_disk_entries"`
+		VerifyingKeyCacheMaxBytes     int64                    `yaml:"verifying_key_cache_max_bytes"`
+		VerificationTimeout           time.Duration            `yaml:"verification_timeout"`
+		VerificationTimeouts          map[string]time.Duration `yaml:"verification_timeouts"`
+		VerificationSandbox           bool                     `yaml:"verification_sandbox"`
+		VerificationSandboxWorkers    int                      `yaml:"verification_sandbox_workers"`
+		VerificationSandboxMaxMemory  int64                    `yaml:"verification_sandbox_max_memory_bytes"`
+		VerificationSandboxCPUSeconds int64                    `yaml:"verification_sandbox_cpu_seconds"`
+		BatchFetchers                 BatchFetchersConfig      `yaml:"batch_fetchers"`
+		BatchMirrors                  BatchMirrorsConfig       `yaml:"batch_mirrors"`
+		BatchCache                    BatchCacheConfig         `yaml:"batch_cache"`
+		Aggregators                   AggregatorsConfig        `yaml:"aggregators"`
 	} `yaml:"operator"`
 	BlsConfigFromYaml   BlsConfigFromYaml   `yaml:"bls"`
 }
@@ -85,8 +184,28 @@ func NewOperatorConfig(configFilePath string) *OperatorConfig {
 			RegisterOperatorOnStartup     bool
 			EnableMetrics                 bool
 			MetricsIpPortAddress          string
+			StatusIpPortAddress           string
 			MaxBatchSize                  int64
 			LastProcessedBatchFilePath    string
+			TaskJournalFilePath           string
+			UnfinishedTaskRetention       time.Duration
+			VerificationMaxWorkers        int
+			VerificationWorkersLimits     map[string]int
+			VerificationMaxAbandoned      int
+			VerificationCacheSize         int
+			VerificationCacheDir          string
+			VerificationCacheDiskEntries  int
+			VerifyingKeyCacheMaxBytes     int64
+			VerificationTimeout           time.Duration
+			VerificationTimeouts          map[string]time.Duration
+			VerificationSandbox           bool
+			VerificationSandboxWorkers    int
+			VerificationSandboxMaxMemory  int64
+			VerificationSandboxCPUSeconds int64
+			BatchFetchers                 BatchFetchersConfig
+			BatchMirrors                  BatchMirrorsConfig
+			BatchCache                    BatchCacheConfig
+			Aggregators                   AggregatorsConfig
 		}(operatorConfigFromYaml.Operator),
 	}
 }
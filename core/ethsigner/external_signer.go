@@ -0,0 +1,244 @@
+package ethsigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/signer"
+	"github.com/Layr-Labs/eigensdk-go/signerv2"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+// DefaultTimeout bounds each request to the external signer
+const DefaultTimeout = 10 * time.Second
+
+var ErrInvalidSignedTransaction = errors.New("invalid signed transaction")
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Config configures an external signer holding the key of Address, used instead of the keystore when Url is set
+type Config struct {
+	Url     string         `yaml:"url"`
+	Address common.Address `yaml:"address"`
+	// AuthToken is sent as `Authorization: Bearer <token>`, if set
+	AuthToken string `yaml:"auth_token"`
+	// CaCertPath is the CA certificate of the signer, if not trusted by the system
+	CaCertPath string `yaml:"ca_cert_path"`
+	// ClientCertPath and ClientKeyPath authenticate with TLS client certificates
+	ClientCertPath string        `yaml:"client_cert_path"`
+	ClientKeyPath  string        `yaml:"client_key_path"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+// ExternalSigner signs transactions with the `eth_signTransaction` JSON-RPC method of an external signer,
+// like Web3Signer or Clef, so the private key never sits in this process. EIP-712 typed data, like the
+// AVS registration of an operator, is signed with `eth_signTypedData`.
+// Every signed transaction and signature is checked to be the requested one, signed by Address, before being used.
+type ExternalSigner struct {
+	url       string
+	address   common.Address
+	authToken string
+	chainId   *big.Int
+	client    *http.Client
+	requestId atomic.Uint64
+}
+
+var _ signer.Signer = (*ExternalSigner)(nil)
+
+// NewExternalSigner returns the signer of signerConfig, for transactions of the chain chainId.
+// The signer must be served over https, unless it's on a loopback address.
+func NewExternalSigner(signerConfig Config, chainId *big.Int) (*ExternalSigner, error) {
+	signerUrl, err := url.Parse(signerConfig.Url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid external signer url: %w", err)
+	}
+	if signerUrl.Scheme != "https" && !(signerUrl.Scheme == "http" && utils.IsLoopbackHost(signerUrl.Hostname())) {
+		return nil, fmt.Errorf("external signer url must be https, got %s", signerConfig.Url)
+	}
+	if signerConfig.Address == (common.Address{}) {
+		return nil, fmt.Errorf("the address of the external signer is not set")
+	}
+
+	tlsConfig, err := utils.NewTLSClientConfig(signerConfig.CaCertPath, signerConfig.ClientCertPath, signerConfig.ClientKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS config of the external signer: %w", err)
+	}
+	timeout := signerConfig.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &ExternalSigner{
+		url:       signerConfig.Url,
+		address:   signerConfig.Address,
+		authToken: signerConfig.AuthToken,
+		chainId:   chainId,
+		client:    &http.Client{Transport: transport, Timeout: timeout},
+	}, nil
+}
+
+// Address is the account the signer signs for
+func (s *ExternalSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTransaction sends tx to the signer and returns it signed
+func (s *ExternalSigner) SignTransaction(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
+	var result hexutil.Bytes
+	if err := s.call(ctx, "eth_signTransaction", []any{newTransactionArgs(s.address, s.chainId, tx)}, &result); err != nil {
+		return nil, err
+	}
+
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignedTransaction, err)
+	}
+	chainSigner := types.LatestSignerForChainID(s.chainId)
+	if chainSigner.Hash(signedTx) != chainSigner.Hash(tx) {
+		return nil, fmt.Errorf("%w: it's not the requested transaction", ErrInvalidSignedTransaction)
+	}
+	if sender, err := types.Sender(chainSigner, signedTx); err != nil || sender != s.address {
+		return nil, fmt.Errorf("%w: it's not signed by %s", ErrInvalidSignedTransaction, s.address)
+	}
+	return signedTx, nil
+}
+
+// SignTypedData returns the signature of the EIP-712 typed data by Address, in the [R || S || V] format
+// with V being 27 or 28, as expected by Ethereum contracts
+func (s *ExternalSigner) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid typed data: %w", err)
+	}
+	var signature hexutil.Bytes
+	if err := s.call(ctx, "eth_signTypedData", []any{s.address, typedData}, &signature); err != nil {
+		return nil, err
+	}
+
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidSignature, crypto.SignatureLength, len(signature))
+	}
+	// Signers return V as 27 or 28, while crypto expects 0 or 1
+	recoverable := append([]byte(nil), signature...)
+	if recoverable[crypto.RecoveryIDOffset] >= 27 {
+		recoverable[crypto.RecoveryIDOffset] -= 27
+	}
+	publicKey, err := crypto.SigToPub(digest, recoverable)
+	if err != nil || crypto.PubkeyToAddress(*publicKey) != s.address {
+		return nil, fmt.Errorf("%w: it's not signed by %s", ErrInvalidSignature, s.address)
+	}
+	recoverable[crypto.RecoveryIDOffset] += 27
+	return recoverable, nil
+}
+
+// GetTxOpts returns new transaction options signing with the external signer.
+// Transactions are signed with the Context of the returned options at the time they are sent.
+func (s *ExternalSigner) GetTxOpts() *bind.TransactOpts {
+	txOpts := &bind.TransactOpts{From: s.address, Context: context.Background()}
+	txOpts.Signer = func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return s.signerFn(txOpts.Context)(address, tx)
+	}
+	return txOpts
+}
+
+// TxOptsWithContext returns a copy of the transaction options of txSigner bound to ctx.
+// The options of an ExternalSigner are built anew, since its SignerFn signs with the Context of the options it was created with.
+func TxOptsWithContext(ctx context.Context, txSigner signer.Signer) *bind.TransactOpts {
+	if externalSigner, ok := txSigner.(*ExternalSigner); ok {
+		txOpts := externalSigner.GetTxOpts()
+		txOpts.Context = ctx
+		return txOpts
+	}
+	txOpts := *txSigner.GetTxOpts()
+	txOpts.Context = ctx
+	return &txOpts
+}
+
+func (s *ExternalSigner) SendToExternal(ctx context.Context, tx *types.Transaction) (common.Hash, error) {
+	return common.Hash{}, errors.New("this signer does not support external signing")
+}
+
+// SignerV2 returns the signer for the transaction managers of the EigenLayer clients
+func (s *ExternalSigner) SignerV2() signerv2.SignerFn {
+	return func(ctx context.Context, address common.Address) (bind.SignerFn, error) {
+		return s.signerFn(ctx), nil
+	}
+}
+
+func (s *ExternalSigner) signerFn(ctx context.Context) bind.SignerFn {
+	return func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if address != s.address {
+			return nil, bind.ErrNotAuthorized
+		}
+		return s.SignTransaction(ctx, tx)
+	}
+}
+
+type jsonRpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type jsonRpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonRpcResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonRpcError   `json:"error,omitempty"`
+}
+
+func (s *ExternalSigner) call(ctx context.Context, method string, params any, result any) error {
+	body, err := json.Marshal(jsonRpcRequest{JsonRpc: "2.0", Id: s.requestId.Add(1), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if s.authToken != "" {
+		request.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("external signer responded %s", response.Status)
+	}
+
+	var rpcResponse jsonRpcResponse
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&rpcResponse); err != nil {
+		return fmt.Errorf("invalid response of the external signer: %w", err)
+	}
+	if rpcResponse.Error != nil {
+		return fmt.Errorf("external signer failed to %s: %s (code %d)", method, rpcResponse.Error.Message, rpcResponse.Error.Code)
+	}
+	return json.Unmarshal(rpcResponse.Result, result)
+}
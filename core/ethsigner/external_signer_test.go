@@ -0,0 +1,224 @@
+package ethsigner
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+var testChainId = big.NewInt(17000)
+
+// newTestSigner serves handler over TLS and returns an external signer for address trusting it
+func newTestSigner(t *testing.T, handler http.Handler, address common.Address, authToken string) *ExternalSigner {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	caCertPath := filepath.Join(t.TempDir(), "ca.pem")
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caCertPath, caCert, 0600); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewExternalSigner(Config{Url: server.URL, Address: address, AuthToken: authToken, CaCertPath: caCertPath}, testChainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestExternalSignerSignsTransactions(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	signer := newTestSigner(t, NewLocalSignerServer(privateKey, "token"), address, "token")
+
+	to := common.HexToAddress("0x7969c5eD335650692Bc04293B07F5BF2e7A673C0")
+	txs := map[string]*types.Transaction{
+		"legacy": types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &to, Value: big.NewInt(5), Data: []byte{1, 2}}),
+		"dynamic fee": types.NewTx(&types.DynamicFeeTx{
+			ChainID: testChainId, Nonce: 2, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(20), Gas: 50000, To: &to, Data: []byte{3},
+		}),
+		"contract creation": types.NewTx(&types.LegacyTx{Nonce: 3, GasPrice: big.NewInt(10), Gas: 100000, Data: []byte{4}}),
+	}
+	for name, tx := range txs {
+		signedTx, err := signer.GetTxOpts().Signer(address, tx)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(testChainId), signedTx)
+		if err != nil || sender != address {
+			t.Errorf("%s: expected the transaction to be signed by %s, got %s (%v)", name, address, sender, err)
+		}
+		if signedTx.Nonce() != tx.Nonce() || signedTx.Type() != tx.Type() {
+			t.Errorf("%s: expected the signed transaction to be the requested one", name)
+		}
+	}
+
+	if _, err := signer.GetTxOpts().Signer(to, txs["legacy"]); !errors.Is(err, bind.ErrNotAuthorized) {
+		t.Errorf("expected transactions from other accounts not to be signed, got %v", err)
+	}
+}
+
+func TestExternalSignerAuthentication(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	signer := newTestSigner(t, NewLocalSignerServer(privateKey, "token"), address, "wrong")
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &address})
+	if _, err := signer.SignTransaction(context.Background(), tx); err == nil {
+		t.Error("expected the signer to reject a wrong auth token")
+	}
+
+	if _, err := NewExternalSigner(Config{Url: "http://signer.example.com:9000", Address: address}, testChainId); err == nil {
+		t.Error("expected an external signer over plain http to be rejected")
+	}
+}
+
+// signingHandler signs every eth_signTransaction request with privateKey, once modified by modify
+func signingHandler(privateKey *ecdsa.PrivateKey, modify func(*transactionArgs)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Id     uint64            `json:"id"`
+			Params []transactionArgs `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Params) != 1 {
+			writeJsonRpcError(w, request.Id, errCodeInvalidParams, "invalid request")
+			return
+		}
+		args := request.Params[0]
+		modify(&args)
+		tx, err := args.toTransaction()
+		if err != nil {
+			writeJsonRpcError(w, request.Id, errCodeInvalidParams, err.Error())
+			return
+		}
+		signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(args.ChainId.ToInt()), privateKey)
+		if err != nil {
+			writeJsonRpcError(w, request.Id, errCodeInvalidParams, err.Error())
+			return
+		}
+		encoded, _ := signedTx.MarshalBinary()
+		writeJsonRpcResult(w, request.Id, hexutil.Bytes(encoded))
+	})
+}
+
+func TestExternalSignerRejectsOtherTransactions(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &address})
+
+	tampering := newTestSigner(t, signingHandler(privateKey, func(args *transactionArgs) { args.Nonce++ }), address, "")
+	if _, err := tampering.SignTransaction(context.Background(), tx); !errors.Is(err, ErrInvalidSignedTransaction) {
+		t.Errorf("expected a different transaction to be rejected, got %v", err)
+	}
+
+	otherAccount := newTestSigner(t, signingHandler(otherKey, func(args *transactionArgs) {}), address, "")
+	if _, err := otherAccount.SignTransaction(context.Background(), tx); !errors.Is(err, ErrInvalidSignedTransaction) {
+		t.Errorf("expected a transaction signed by another account to be rejected, got %v", err)
+	}
+}
+
+func TestExternalSignerSignsWithTheContextOfTheTxOpts(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	signer := newTestSigner(t, NewLocalSignerServer(privateKey, ""), address, "")
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000, To: &address})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	txOpts := signer.GetTxOpts()
+	txOpts.Context = ctx
+	if _, err := txOpts.Signer(address, tx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context set in the options to be used, got %v", err)
+	}
+
+	copied := *TxOptsWithContext(ctx, signer)
+	if _, err := copied.Signer(address, tx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context of TxOptsWithContext to be used, got %v", err)
+	}
+	if _, err := TxOptsWithContext(context.Background(), signer).Signer(address, tx); err != nil {
+		t.Errorf("expected the transaction to be signed, got %v", err)
+	}
+}
+
+func TestExternalSignerSignsTypedData(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {{Name: "name", Type: "string"}, {Name: "chainId", Type: "uint256"}},
+			"Registration": {{Name: "operator", Type: "address"}, {Name: "salt", Type: "bytes32"}},
+		},
+		PrimaryType: "Registration",
+		Domain:      apitypes.TypedDataDomain{Name: "Test", ChainId: (*math.HexOrDecimal256)(testChainId)},
+		Message:     apitypes.TypedDataMessage{"operator": address.Hex(), "salt": hexutil.Encode(make([]byte, 32))},
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := newTestSigner(t, NewLocalSignerServer(privateKey, ""), address, "")
+	signature, err := signer.SignTypedData(context.Background(), typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := signature[crypto.RecoveryIDOffset]; v != 27 && v != 28 {
+		t.Errorf("expected V to be 27 or 28, got %d", v)
+	}
+	signature[crypto.RecoveryIDOffset] -= 27
+	if publicKey, err := crypto.SigToPub(digest, signature); err != nil || crypto.PubkeyToAddress(*publicKey) != address {
+		t.Errorf("expected the typed data to be signed by %s: %v", address, err)
+	}
+
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSignature, err := crypto.Sign(digest, otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAccount := newTestSigner(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJsonRpcResult(w, 1, hexutil.Bytes(otherSignature))
+	}), address, "")
+	if _, err := otherAccount.SignTypedData(context.Background(), typedData); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected a signature by another account to be rejected, got %v", err)
+	}
+}
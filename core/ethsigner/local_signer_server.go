@@ -0,0 +1,149 @@
+package ethsigner
+
+import (
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// The JSON-RPC error codes of LocalSignerServer
+const (
+	errCodeParse          = -32700
+	errCodeInvalidRequest = -32600
+	errCodeMethodNotFound = -32601
+	errCodeInvalidParams  = -32602
+)
+
+// LocalSignerServer serves the `eth_signTransaction`, `eth_signTypedData` and `eth_accounts` JSON-RPC methods
+// of an external signer, signing with a local private key. Requests must carry "Authorization: Bearer <authToken>"
+// if authToken is set. It's a stand-in for an external signer in tests and devnets: it signs anything it's sent.
+type LocalSignerServer struct {
+	privateKey *ecdsa.PrivateKey
+	authToken  string
+}
+
+func NewLocalSignerServer(privateKey *ecdsa.PrivateKey, authToken string) *LocalSignerServer {
+	return &LocalSignerServer{privateKey: privateKey, authToken: authToken}
+}
+
+func (s *LocalSignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.authToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) != 1 {
+		http.Error(w, "invalid auth token", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Id     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&request); err != nil {
+		writeJsonRpcError(w, 0, errCodeParse, "invalid request: "+err.Error())
+		return
+	}
+
+	switch request.Method {
+	case "eth_accounts":
+		writeJsonRpcResult(w, request.Id, []string{crypto.PubkeyToAddress(s.privateKey.PublicKey).Hex()})
+	case "eth_signTransaction":
+		s.signTransaction(w, request.Id, request.Params)
+	case "eth_signTypedData":
+		s.signTypedData(w, request.Id, request.Params)
+	case "":
+		writeJsonRpcError(w, request.Id, errCodeInvalidRequest, "method is required")
+	default:
+		writeJsonRpcError(w, request.Id, errCodeMethodNotFound, "method not found: "+request.Method)
+	}
+}
+
+func (s *LocalSignerServer) signTransaction(w http.ResponseWriter, id uint64, params []json.RawMessage) {
+	if len(params) != 1 {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "expected the transaction as the only parameter")
+		return
+	}
+	var args transactionArgs
+	if err := json.Unmarshal(params[0], &args); err != nil {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "invalid transaction: "+err.Error())
+		return
+	}
+	if args.From != crypto.PubkeyToAddress(s.privateKey.PublicKey) {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "unknown account "+args.From.Hex())
+		return
+	}
+	tx, err := args.toTransaction()
+	if err != nil {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "invalid transaction: "+err.Error())
+		return
+	}
+
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(args.ChainId.ToInt()), s.privateKey)
+	if err != nil {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "could not sign transaction: "+err.Error())
+		return
+	}
+	encoded, err := signedTx.MarshalBinary()
+	if err != nil {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "could not encode transaction: "+err.Error())
+		return
+	}
+	writeJsonRpcResult(w, id, hexutil.Bytes(encoded))
+}
+
+func (s *LocalSignerServer) signTypedData(w http.ResponseWriter, id uint64, params []json.RawMessage) {
+	if len(params) != 2 {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "expected the account and the typed data as parameters")
+		return
+	}
+	var account common.Address
+	if err := json.Unmarshal(params[0], &account); err != nil || account != crypto.PubkeyToAddress(s.privateKey.PublicKey) {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "unknown account "+account.Hex())
+		return
+	}
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(params[1], &typedData); err != nil {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "invalid typed data: "+err.Error())
+		return
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "invalid typed data: "+err.Error())
+		return
+	}
+
+	signature, err := crypto.Sign(digest, s.privateKey)
+	if err != nil {
+		writeJsonRpcError(w, id, errCodeInvalidParams, "could not sign typed data: "+err.Error())
+		return
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	writeJsonRpcResult(w, id, hexutil.Bytes(signature))
+}
+
+func writeJsonRpcResult(w http.ResponseWriter, id uint64, result any) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		writeJsonRpcError(w, id, errCodeInvalidParams, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jsonRpcResponse{JsonRpc: "2.0", Id: id, Result: encoded})
+}
+
+func writeJsonRpcError(w http.ResponseWriter, id uint64, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jsonRpcResponse{JsonRpc: "2.0", Id: id, Error: &jsonRpcError{Code: code, Message: message}})
+}
@@ -0,0 +1,82 @@
+package ethsigner
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// transactionArgs is the transaction parameter of `eth_signTransaction`, as in the Ethereum JSON-RPC API.
+// Legacy transactions set GasPrice, and EIP-1559 ones MaxFeePerGas and MaxPriorityFeePerGas.
+type transactionArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainId              *hexutil.Big    `json:"chainId"`
+}
+
+func newTransactionArgs(from common.Address, chainId *big.Int, tx *types.Transaction) transactionArgs {
+	args := transactionArgs{
+		From:    from,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   (*hexutil.Big)(tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainId: (*hexutil.Big)(chainId),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+	return args
+}
+
+// toTransaction builds the unsigned transaction of args
+func (args transactionArgs) toTransaction() (*types.Transaction, error) {
+	if args.ChainId == nil {
+		return nil, errors.New("chainId is required")
+	}
+	value := new(big.Int)
+	if args.Value != nil {
+		value = args.Value.ToInt()
+	}
+
+	if args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil {
+		if args.MaxFeePerGas == nil || args.MaxPriorityFeePerGas == nil || args.GasPrice != nil {
+			return nil, errors.New("either gasPrice, or maxFeePerGas and maxPriorityFeePerGas are required")
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   args.ChainId.ToInt(),
+			Nonce:     uint64(args.Nonce),
+			GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+			GasFeeCap: args.MaxFeePerGas.ToInt(),
+			Gas:       uint64(args.Gas),
+			To:        args.To,
+			Value:     value,
+			Data:      args.Data,
+		}), nil
+	}
+	if args.GasPrice == nil {
+		return nil, errors.New("either gasPrice, or maxFeePerGas and maxPriorityFeePerGas are required")
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    uint64(args.Nonce),
+		GasPrice: args.GasPrice.ToInt(),
+		Gas:      uint64(args.Gas),
+		To:       args.To,
+		Value:    value,
+		Data:     args.Data,
+	}), nil
+}
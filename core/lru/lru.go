@@ -0,0 +1,131 @@
+// Package lru is a generic least recently used cache with a cost budget
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache is a thread safe least recently used cache.
+// Each entry has a cost, and the least recently used entries are evicted
+// when the total cost goes over maxCost.
+type Cache[K comparable, V any] struct {
+	maxCost   int64
+	totalCost int64
+	entries   map[K]*list.Element
+	order     *list.List
+	onEvict   func(key K, value V)
+	mutex     sync.Mutex
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key   K
+	value V
+	cost  int64
+}
+
+func New[K comparable, V any](maxCost int64) *Cache[K, V] {
+	return &Cache[K, V]{
+		maxCost: maxCost,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry[K, V]).value, true
+}
+
+// Add inserts or updates an entry. Entries costing more than maxCost are not stored.
+func (c *Cache[K, V]) Add(key K, value V, cost int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cost > c.maxCost {
+		return
+	}
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry[K, V])
+		c.totalCost += cost - entry.cost
+		entry.value = value
+		entry.cost = cost
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[key] = c.order.PushFront(&cacheEntry[K, V]{key: key, value: value, cost: cost})
+		c.totalCost += cost
+	}
+
+	c.evict()
+}
+
+// OnEvict sets a callback invoked with every entry evicted to fit the budget, with the mutex held
+func (c *Cache[K, V]) OnEvict(callback func(key K, value V)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onEvict = callback
+}
+
+// Remove deletes an entry, returning whether it was stored
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	c.order.Remove(element)
+	delete(c.entries, key)
+	c.totalCost -= element.Value.(*cacheEntry[K, V]).cost
+	return true
+}
+
+// SetMaxCost changes the cost budget, evicting entries if the current ones exceed it
+func (c *Cache[K, V]) SetMaxCost(maxCost int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maxCost = maxCost
+	c.evict()
+}
+
+// evict removes the least recently used entries until the total cost fits the budget.
+// Must be called with the mutex held.
+func (c *Cache[K, V]) evict() {
+	for c.totalCost > c.maxCost && c.order.Len() > 0 {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*cacheEntry[K, V])
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.totalCost -= entry.cost
+		if c.onEvict != nil {
+			c.onEvict(entry.key, entry.value)
+		}
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+// Cost returns the sum of the costs of the stored entries
+func (c *Cache[K, V]) Cost() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.totalCost
+}
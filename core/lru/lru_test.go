@@ -0,0 +1,30 @@
+package lru
+
+import (
+	"testing"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := New[string, int](3)
+	cache.Add("a", 1, 1)
+	cache.Add("b", 2, 1)
+	cache.Add("c", 3, 1)
+
+	// Touch "a" so "b" becomes the least recently used entry
+	cache.Get("a")
+	cache.Add("d", 4, 1)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("b should have been evicted")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("%s should still be cached", key)
+		}
+	}
+
+	cache.Add("big", 5, 3)
+	if cache.Len() != 1 || cache.Cost() != 3 {
+		t.Errorf("expected only the costly entry to remain, got %d entries with cost %d", cache.Len(), cache.Cost())
+	}
+}
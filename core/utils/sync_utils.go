@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// WaitTimeout waits for wg for up to timeout. Returns false if the timeout passed first.
+func WaitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+// Package vkcache caches parsed gnark verifying keys, so popular circuits are only deserialized once.
+// It's shared by the gnark verifiers of the operator and the pre-verification of the batcher.
+package vkcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/yetanotherco/aligned_layer/core/lru"
+)
+
+// DefaultMaxBytes is the memory budget of a cache when none is configured
+const DefaultMaxBytes = 64 * 1024 * 1024 // 64 MiB
+
+// ErrInvalidVerifyingKey is returned when the verifying key bytes could not be parsed
+var ErrInvalidVerifyingKey = errors.New("invalid verifying key")
+
+type scheme uint8
+
+const (
+	plonkScheme scheme = iota
+	groth16Scheme
+)
+
+type cacheKey struct {
+	scheme scheme
+	curve  ecc.ID
+	hash   [32]byte
+}
+
+// Cache keeps parsed gnark verifying keys, indexed by scheme, curve and hash of their serialized bytes.
+// The budget is measured in serialized bytes, and least recently used keys are evicted when it is exceeded.
+type Cache struct {
+	keys *lru.Cache[cacheKey, any]
+}
+
+func New(maxBytes int64) *Cache {
+	return &Cache{keys: lru.New[cacheKey, any](maxBytes)}
+}
+
+// SetMaxBytes changes the memory budget, evicting keys if needed
+func (c *Cache) SetMaxBytes(maxBytes int64) {
+	c.keys.SetMaxCost(maxBytes)
+}
+
+// Len returns the number of parsed keys stored
+func (c *Cache) Len() int {
+	return c.keys.Len()
+}
+
+// PlonkVerifyingKey returns the parsed PLONK verifying key for verificationKeyBytes, parsing it on cache misses
+func (c *Cache) PlonkVerifyingKey(verificationKeyBytes []byte, curve ecc.ID) (plonk.VerifyingKey, error) {
+	key := cacheKey{scheme: plonkScheme, curve: curve, hash: sha256.Sum256(verificationKeyBytes)}
+	if verificationKey, ok := c.keys.Get(key); ok {
+		return verificationKey.(plonk.VerifyingKey), nil
+	}
+
+	verificationKey := plonk.NewVerifyingKey(curve)
+	if _, err := verificationKey.ReadFrom(bytes.NewReader(verificationKeyBytes)); err != nil {
+		return nil, fmt.Errorf("%w: could not read PLONK verifying key from bytes: %v", ErrInvalidVerifyingKey, err)
+	}
+	c.keys.Add(key, verificationKey, int64(len(verificationKeyBytes)))
+
+	return verificationKey, nil
+}
+
+// Groth16VerifyingKey returns the parsed Groth16 verifying key for verificationKeyBytes, parsing it on cache misses
+func (c *Cache) Groth16VerifyingKey(verificationKeyBytes []byte, curve ecc.ID) (groth16.VerifyingKey, error) {
+	key := cacheKey{scheme: groth16Scheme, curve: curve, hash: sha256.Sum256(verificationKeyBytes)}
+	if verificationKey, ok := c.keys.Get(key); ok {
+		return verificationKey.(groth16.VerifyingKey), nil
+	}
+
+	verificationKey := groth16.NewVerifyingKey(curve)
+	if _, err := verificationKey.ReadFrom(bytes.NewReader(verificationKeyBytes)); err != nil {
+		return nil, fmt.Errorf("%w: could not read Groth16 verifying key from bytes: %v", ErrInvalidVerifyingKey, err)
+	}
+	c.keys.Add(key, verificationKey, int64(len(verificationKeyBytes)))
+
+	return verificationKey, nil
+}
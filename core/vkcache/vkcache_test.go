@@ -0,0 +1,50 @@
+package vkcache
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+func TestCacheReusesParsedKeys(t *testing.T) {
+	verificationKey, err := os.ReadFile("../../scripts/test_files/gnark_plonk_bn254_script/plonk.vk")
+	if err != nil {
+		t.Fatalf("could not open verification key file: %s", err)
+	}
+	cache := New(DefaultMaxBytes)
+
+	first, err := cache.PlonkVerifyingKey(verificationKey, ecc.BN254)
+	if err != nil {
+		t.Fatalf("could not parse verifying key: %s", err)
+	}
+	second, err := cache.PlonkVerifyingKey(verificationKey, ecc.BN254)
+	if err != nil {
+		t.Fatalf("could not parse verifying key: %s", err)
+	}
+	if first != second {
+		t.Errorf("expected the cached verifying key to be reused")
+	}
+
+	// Shrinking the budget below the key size evicts it, so it's parsed again
+	cache.SetMaxBytes(int64(len(verificationKey)) - 1)
+	third, err := cache.PlonkVerifyingKey(verificationKey, ecc.BN254)
+	if err != nil {
+		t.Fatalf("could not parse verifying key: %s", err)
+	}
+	if third == first {
+		t.Errorf("expected the verifying key to be evicted")
+	}
+}
+
+func TestCacheRejectsMalformedKeys(t *testing.T) {
+	cache := New(DefaultMaxBytes)
+
+	if _, err := cache.Groth16VerifyingKey([]byte{1, 2, 3}, ecc.BN254); !errors.Is(err, ErrInvalidVerifyingKey) {
+		t.Errorf("expected an invalid verifying key error, got %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("malformed verifying keys should not be cached")
+	}
+}
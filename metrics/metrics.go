@@ -18,6 +18,29 @@ type Metrics struct {
 	numAggregatedResponses                 prometheus.Counter
 	numAggregatorReceivedTasks             prometheus.Counter
 	numOperatorTaskResponses               prometheus.Counter
+	numOperatorProofVerdicts               *prometheus.CounterVec
+	operatorVerificationQueueDepth         *prometheus.GaugeVec
+	operatorAbandonedVerifiers             prometheus.Gauge
+	operatorVerificationCacheHits          *prometheus.CounterVec
+	operatorVerificationCacheMisses        prometheus.Counter
+	operatorVerifierWorkerRestarts         *prometheus.CounterVec
+	operatorBatchMirrorDownloads           *prometheus.CounterVec
+	operatorBatchMirrorDownloadLatency     *prometheus.HistogramVec
+	operatorAggregatorDeliveries           *prometheus.CounterVec
+	operatorAggregatorDeliveryLatency      *prometheus.HistogramVec
+	operatorAggregatorReconnections        *prometheus.CounterVec
+	operatorBatchCacheHits                 prometheus.Counter
+	operatorBatchCacheMisses               prometheus.Counter
+	operatorBatchCacheEvictions            prometheus.Counter
+	operatorBatchCacheSize                 prometheus.Gauge
+	operatorBatchDownloadSize              prometheus.Histogram
+	operatorBatchDownloadLatency           prometheus.Histogram
+	operatorBatchDecodeLatency             prometheus.Histogram
+	operatorBatchMerkleCheckLatency        prometheus.Histogram
+	operatorVerificationLatency            *prometheus.HistogramVec
+	numOperatorBatchesSigned               prometheus.Counter
+	numOperatorBatchesRejected             prometheus.Counter
+	numOperatorResponseSendFailures        prometheus.Counter
 	aggregatorGasCostPaidForBatcherTotal   prometheus.Gauge
 	aggregatorNumTimesPaidForBatcher       prometheus.Counter
 	numBumpedGasPriceForAggregatedResponse prometheus.Counter
@@ -42,6 +65,128 @@ func NewMetrics(ipPortAddress string, reg prometheus.Registerer, logger logging.
 			Name:      "operator_responses_count",
 			Help:      "Number of proof verified by the operator and sent to the Aligned Service Manager",
 		}),
+		numOperatorProofVerdicts: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_proof_verdicts_count",
+			Help:      "Number of proofs verified by the operator, by proving system and verification outcome",
+		}, []string{"proving_system", "outcome"}),
+		operatorVerificationQueueDepth: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_queue_depth",
+			Help:      "Number of proofs waiting for a verification worker, by proving system",
+		}, []string{"proving_system"}),
+		operatorAbandonedVerifiers: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_abandoned_verifiers",
+			Help:      "Number of verifiers still running after their verification timed out",
+		}),
+		operatorVerificationCacheHits: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_cache_hits_count",
+			Help:      "Number of proof verifications served from the verification cache, by cache tier",
+		}, []string{"tier"}),
+		operatorVerificationCacheMisses: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_cache_misses_count",
+			Help:      "Number of proof verifications not found in the verification cache",
+		}),
+		operatorVerifierWorkerRestarts: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verifier_worker_restarts_count",
+			Help:      "Number of sandboxed verifier workers restarted, by reason",
+		}, []string{"reason"}),
+		operatorBatchMirrorDownloads: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_mirror_downloads_count",
+			Help:      "Number of batch downloads, by mirror and result",
+		}, []string{"mirror", "result"}),
+		operatorBatchMirrorDownloadLatency: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_mirror_download_latency_seconds",
+			Help:      "Latency of batch downloads, by mirror",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10),
+		}, []string{"mirror"}),
+		operatorAggregatorDeliveries: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_aggregator_deliveries_count",
+			Help:      "Number of attempts to deliver signed task responses, by aggregator and result",
+		}, []string{"aggregator", "result"}),
+		operatorAggregatorDeliveryLatency: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_aggregator_delivery_latency_seconds",
+			Help:      "Latency of the attempts to deliver signed task responses, by aggregator",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"aggregator"}),
+		operatorAggregatorReconnections: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_aggregator_reconnections_count",
+			Help:      "Number of reconnections to the aggregators, by aggregator and result",
+		}, []string{"aggregator", "result"}),
+		operatorBatchCacheHits: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_cache_hits_count",
+			Help:      "Number of batches read from the batch cache instead of downloaded",
+		}),
+		operatorBatchCacheMisses: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_cache_misses_count",
+			Help:      "Number of batches not found in the batch cache",
+		}),
+		operatorBatchCacheEvictions: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_cache_evictions_count",
+			Help:      "Number of batches evicted from the batch cache to fit its max size",
+		}),
+		operatorBatchCacheSize: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_cache_size_bytes",
+			Help:      "Size of the batches stored in the batch cache",
+		}),
+		operatorBatchDownloadSize: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_download_size_bytes",
+			Help:      "Size of the batches downloaded by the operator",
+			Buckets:   prometheus.ExponentialBuckets(1<<10, 4, 10),
+		}),
+		operatorBatchDownloadLatency: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_download_latency_seconds",
+			Help:      "Time spent waiting for the data of the batches downloaded by the operator, excluding the time spent verifying them",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}),
+		operatorBatchDecodeLatency: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_decode_latency_seconds",
+			Help:      "Time spent decoding the entries of a batch, excluding reading it and verifying its proofs",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		operatorBatchMerkleCheckLatency: promauto.With(reg).NewHistogram(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batch_merkle_check_latency_seconds",
+			Help:      "Time spent hashing the entries of a batch and computing its merkle root",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 14),
+		}),
+		operatorVerificationLatency: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_verification_latency_seconds",
+			Help:      "Latency of the proof verifications run by the operator, by proving system. Cached verdicts are not included",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 16),
+		}, []string{"proving_system"}),
+		numOperatorBatchesSigned: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batches_signed_count",
+			Help:      "Number of verified batches signed by the operator",
+		}),
+		numOperatorBatchesRejected: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_batches_rejected_count",
+			Help:      "Number of batches not signed by the operator because a proof did not verify",
+		}),
+		numOperatorResponseSendFailures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace: alignedNamespace,
+			Name:      "operator_response_send_failures_count",
+			Help:      "Number of signed batches the operator could not send to the aggregator",
+		}),
 		numAggregatorReceivedTasks: promauto.With(reg).NewCounter(prometheus.CounterOpts{
 			Namespace: alignedNamespace,
 			Name:      "aggregator_received_tasks_count",
@@ -123,6 +268,95 @@ func (m *Metrics) IncOperatorTaskResponses() {
 	m.numOperatorTaskResponses.Inc()
 }
 
+func (m *Metrics) IncOperatorProofVerdict(provingSystem string, outcome string) {
+	m.numOperatorProofVerdicts.WithLabelValues(provingSystem, outcome).Inc()
+}
+
+func (m *Metrics) SetOperatorVerificationQueueDepth(provingSystem string, depth int) {
+	m.operatorVerificationQueueDepth.WithLabelValues(provingSystem).Set(float64(depth))
+}
+
+func (m *Metrics) SetOperatorAbandonedVerifiers(abandoned int) {
+	m.operatorAbandonedVerifiers.Set(float64(abandoned))
+}
+
+func (m *Metrics) IncOperatorVerificationCacheHits(tier string) {
+	m.operatorVerificationCacheHits.WithLabelValues(tier).Inc()
+}
+
+func (m *Metrics) IncOperatorVerificationCacheMisses() {
+	m.operatorVerificationCacheMisses.Inc()
+}
+
+func (m *Metrics) IncOperatorVerifierWorkerRestarts(reason string) {
+	m.operatorVerifierWorkerRestarts.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) IncOperatorBatchMirrorDownload(mirror string, result string) {
+	m.operatorBatchMirrorDownloads.WithLabelValues(mirror, result).Inc()
+}
+
+func (m *Metrics) ObserveOperatorBatchMirrorDownloadLatency(mirror string, elapsed time.Duration) {
+	m.operatorBatchMirrorDownloadLatency.WithLabelValues(mirror).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) IncOperatorAggregatorDelivery(aggregator string, result string) {
+	m.operatorAggregatorDeliveries.WithLabelValues(aggregator, result).Inc()
+}
+
+func (m *Metrics) ObserveOperatorAggregatorDeliveryLatency(aggregator string, elapsed time.Duration) {
+	m.operatorAggregatorDeliveryLatency.WithLabelValues(aggregator).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) IncOperatorAggregatorReconnection(aggregator string, result string) {
+	m.operatorAggregatorReconnections.WithLabelValues(aggregator, result).Inc()
+}
+
+func (m *Metrics) IncOperatorBatchCacheHits() {
+	m.operatorBatchCacheHits.Inc()
+}
+
+func (m *Metrics) IncOperatorBatchCacheMisses() {
+	m.operatorBatchCacheMisses.Inc()
+}
+
+func (m *Metrics) AddOperatorBatchCacheEvictions(evicted int) {
+	m.operatorBatchCacheEvictions.Add(float64(evicted))
+}
+
+func (m *Metrics) SetOperatorBatchCacheSize(bytes int64) {
+	m.operatorBatchCacheSize.Set(float64(bytes))
+}
+
+func (m *Metrics) ObserveOperatorBatchDownload(bytes int64, elapsed time.Duration) {
+	m.operatorBatchDownloadSize.Observe(float64(bytes))
+	m.operatorBatchDownloadLatency.Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) ObserveOperatorBatchDecodeLatency(elapsed time.Duration) {
+	m.operatorBatchDecodeLatency.Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) ObserveOperatorBatchMerkleCheckLatency(elapsed time.Duration) {
+	m.operatorBatchMerkleCheckLatency.Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) ObserveOperatorVerificationLatency(provingSystem string, elapsed time.Duration) {
+	m.operatorVerificationLatency.WithLabelValues(provingSystem).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) IncOperatorBatchesSigned() {
+	m.numOperatorBatchesSigned.Inc()
+}
+
+func (m *Metrics) IncOperatorBatchesRejected() {
+	m.numOperatorBatchesRejected.Inc()
+}
+
+func (m *Metrics) IncOperatorResponseSendFailures() {
+	m.numOperatorResponseSendFailures.Inc()
+}
+
 func (m *Metrics) IncAggregatorPaidForBatcher() {
 	m.aggregatorNumTimesPaidForBatcher.Inc()
 }
@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var PurgeMerkleRootFlag = &cli.StringFlag{
+	Name:  "merkle-root",
+	Usage: "Merkle root of the batch to purge, in hex. If not set, every batch is purged",
+}
+
+var BatchCacheCommand = &cli.Command{
+	Name:  "batch-cache",
+	Usage: "List or purge the batches stored in the batch cache",
+	Subcommands: []*cli.Command{
+		{
+			Name:        "list",
+			Usage:       "List the cached batches, most recently used first",
+			Description: "CLI command to list the batches stored in the `batch_cache.dir` directory of the operator config",
+			Flags:       []cli.Flag{config.ConfigFileFlag},
+			Action:      listBatchCacheMain,
+		},
+		{
+			Name:        "purge",
+			Usage:       "Delete cached batches",
+			Description: "CLI command to delete batches from the `batch_cache.dir` directory of the operator config. The running operator downloads them again when needed",
+			Flags:       []cli.Flag{config.ConfigFileFlag, PurgeMerkleRootFlag},
+			Action:      purgeBatchCacheMain,
+		},
+	},
+}
+
+// readBatchCacheConfig reads the `batch_cache` field of the operator config, and only that field
+// so it doesn't need to connect to the chain
+func readBatchCacheConfig(ctx *cli.Context) (dir string, maxBytes int64, err error) {
+	var operatorConfig config.OperatorConfigFromYaml
+	if err := utils.ReadYamlConfig(ctx.String(config.ConfigFileFlag.Name), &operatorConfig); err != nil {
+		return "", 0, err
+	}
+	batchCacheConfig := operatorConfig.Operator.BatchCache
+	if batchCacheConfig.Dir == "" {
+		return "", 0, fmt.Errorf("the batch cache is not enabled: `batch_cache.dir` is not set")
+	}
+	return batchCacheConfig.Dir, batchCacheConfig.MaxBytes, nil
+}
+
+// listBatchCacheMain only reads the cache directory: opening the cache would evict batches over
+// `batch_cache.max_bytes` and delete leftover downloads of a running operator
+func listBatchCacheMain(ctx *cli.Context) error {
+	dir, _, err := readBatchCacheConfig(ctx)
+	if err != nil {
+		return err
+	}
+	batches, err := operator.ListBatchCache(dir)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "MERKLE ROOT\tSIZE\tLAST USED")
+	var totalSize int64
+	for _, batch := range batches {
+		totalSize += batch.Size
+		fmt.Fprintf(writer, "0x%x\t%d\t%s\n", batch.MerkleRoot, batch.Size, batch.LastUsed.Format(time.RFC3339))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d batches, %d bytes\n", len(batches), totalSize)
+	return nil
+}
+
+func purgeBatchCacheMain(ctx *cli.Context) error {
+	dir, maxBytes, err := readBatchCacheConfig(ctx)
+	if err != nil {
+		return err
+	}
+	batchCache, err := operator.NewBatchCache(dir, maxBytes)
+	if err != nil {
+		return err
+	}
+
+	if merkleRoot := ctx.String(PurgeMerkleRootFlag.Name); merkleRoot != "" {
+		batchMerkleRoot, err := parseMerkleRoot(merkleRoot)
+		if err != nil {
+			return err
+		}
+		if err := batchCache.Remove(batchMerkleRoot); err != nil {
+			return err
+		}
+		fmt.Printf("Purged batch 0x%x\n", batchMerkleRoot)
+		return nil
+	}
+
+	purged, err := batchCache.Purge()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Purged %d batches\n", purged)
+	return nil
+}
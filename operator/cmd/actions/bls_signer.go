@@ -0,0 +1,83 @@
+package actions
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/urfave/cli/v2"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var (
+	BlsSignerAddressFlag = &cli.StringFlag{
+		Name:  "address",
+		Usage: "Address the signer listens on",
+		Value: "localhost:9000",
+	}
+	BlsSignerAuthTokenFlag = &cli.StringFlag{
+		Name:    "auth-token",
+		Usage:   "Token the operator must send as `Authorization: Bearer <token>`. If not set, requests are not authenticated",
+		EnvVars: []string{"BLS_SIGNER_AUTH_TOKEN"},
+	}
+	BlsSignerTLSCertFlag = &cli.StringFlag{
+		Name:  "tls-cert",
+		Usage: "Path to the TLS certificate of the signer. If not set, the signer is served over plain HTTP",
+	}
+	BlsSignerTLSKeyFlag = &cli.StringFlag{
+		Name:  "tls-key",
+		Usage: "Path to the TLS private key of the signer",
+	}
+)
+
+var BlsSignerCommand = &cli.Command{
+	Name:        "bls-signer",
+	Usage:       "Run a local stand-in for a remote BLS signer",
+	Description: "CLI command to serve the remote BLS signer API with the key of a BLS keystore, for tests and devnets. It signs any message it's sent, so it's no replacement for a hardened signing service",
+	Flags: []cli.Flag{
+		KeystoreFlag,
+		BlsSignerAddressFlag,
+		BlsSignerAuthTokenFlag,
+		BlsSignerTLSCertFlag,
+		BlsSignerTLSKeyFlag,
+	},
+	Action: blsSignerMain,
+}
+
+func blsSignerMain(ctx *cli.Context) error {
+	password, err := readSecret(KeystorePasswordEnvVar, "Keystore password")
+	if err != nil {
+		return err
+	}
+	keyPair, err := bls.ReadPrivateKeyFromFile(ctx.String(KeystoreFlag.Name), password)
+	if err != nil {
+		return err
+	}
+
+	runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:    ctx.String(BlsSignerAddressFlag.Name),
+		Handler: operator.NewBlsSignerServer(keyPair, ctx.String(BlsSignerAuthTokenFlag.Name)),
+	}
+	go func() {
+		<-runCtx.Done()
+		server.Close()
+	}()
+
+	log.Printf("BLS signer listening on %s", server.Addr)
+	if ctx.IsSet(BlsSignerTLSCertFlag.Name) {
+		err = server.ListenAndServeTLS(ctx.String(BlsSignerTLSCertFlag.Name), ctx.String(BlsSignerTLSKeyFlag.Name))
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
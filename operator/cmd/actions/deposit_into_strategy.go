@@ -9,7 +9,6 @@ import (
 	"github.com/Layr-Labs/eigensdk-go/chainio/clients/wallet"
 	"github.com/Layr-Labs/eigensdk-go/chainio/txmgr"
 	"github.com/Layr-Labs/eigensdk-go/metrics"
-	"github.com/Layr-Labs/eigensdk-go/signerv2"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/core/config"
@@ -63,14 +62,8 @@ func depositIntoStrategyMain(ctx *cli.Context) error {
 	delegationManagerAddr := opConfig.BaseConfig.EigenLayerDeploymentConfig.DelegationManagerAddr
 	avsDirectoryAddr := opConfig.BaseConfig.EigenLayerDeploymentConfig.AVSDirectoryAddr
 
-	signerConfig := signerv2.Config{
-		PrivateKey: ecdsaConfig.PrivateKey,
-	}
-	signerFn, _, err := signerv2.SignerFromConfig(signerConfig, opConfig.BaseConfig.ChainId)
-	if err != nil {
-		return err
-	}
-	w, err := wallet.NewPrivateKeyWallet(&opConfig.BaseConfig.EthRpcClient, signerFn,
+	// Signs with the keystore or the external signer of the config
+	w, err := wallet.NewPrivateKeyWallet(&opConfig.BaseConfig.EthRpcClient, ecdsaConfig.SignerV2,
 		opConfig.Operator.Address, opConfig.BaseConfig.Logger)
 
 	if err != nil {
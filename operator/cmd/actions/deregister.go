@@ -0,0 +1,29 @@
+package actions
+
+import (
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var DeregisterCommand = &cli.Command{
+	Name:        "deregister",
+	Usage:       "Deregister operator from Aligned Layer",
+	Description: "CLI command to deregister the operator from the Aligned Layer quorums",
+	Flags:       []cli.Flag{config.ConfigFileFlag},
+	Action:      deregisterOperatorMain,
+}
+
+func deregisterOperatorMain(ctx *cli.Context) error {
+	operatorConfig := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	ecdsaConfig := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), operatorConfig.BaseConfig.ChainId)
+
+	err := operator.DeregisterOperator(ctx.Context, operatorConfig, ecdsaConfig)
+	if err != nil {
+		operatorConfig.BaseConfig.Logger.Error("Failed to deregister operator", "err", err)
+		return err
+	}
+
+	operatorConfig.BaseConfig.Logger.Info("Operator deregistered", "address", operatorConfig.Operator.Address)
+	return nil
+}
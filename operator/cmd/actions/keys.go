@@ -0,0 +1,247 @@
+package actions
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+	"golang.org/x/term"
+)
+
+var (
+	KeyTypeFlag = &cli.StringFlag{
+		Name:     "key-type",
+		Usage:    "Type of the key: bls or ecdsa",
+		Required: true,
+	}
+	KeystoreFlag = &cli.StringFlag{
+		Name:     "keystore",
+		Usage:    "Path to the keystore file",
+		Required: true,
+	}
+	InsecureEmptyPasswordFlag = &cli.BoolFlag{
+		Name:  "insecure-empty-password",
+		Usage: "Allow encrypting the keystore with an empty password",
+	}
+)
+
+// Secrets are never taken from flags, which other users can read from the process list.
+// They are read from these environment variables, or prompted for if not set.
+const (
+	KeystorePasswordEnvVar    = "KEYSTORE_PASSWORD"
+	NewKeystorePasswordEnvVar = "NEW_KEYSTORE_PASSWORD"
+	PrivateKeyEnvVar          = "PRIVATE_KEY"
+)
+
+var KeysCommand = &cli.Command{
+	Name:  "keys",
+	Usage: "Create and inspect the BLS and ECDSA keystores of the operator",
+	Subcommands: []*cli.Command{
+		{
+			Name:        "generate",
+			Usage:       "Generate a new keystore",
+			Description: "CLI command to generate a random key and store it encrypted in a new keystore. An existing keystore is never overwritten",
+			Flags:       []cli.Flag{KeyTypeFlag, KeystoreFlag, InsecureEmptyPasswordFlag},
+			Action:      generateKeyMain,
+		},
+		{
+			Name:        "import",
+			Usage:       "Import a private key into a new keystore",
+			Description: "CLI command to store a private key in hex encrypted in a new keystore. An existing keystore is never overwritten",
+			Flags:       []cli.Flag{KeyTypeFlag, KeystoreFlag, InsecureEmptyPasswordFlag},
+			Action:      importKeyMain,
+		},
+		{
+			Name:        "change-password",
+			Usage:       "Change the password of a keystore",
+			Description: "CLI command to encrypt an existing keystore with a new password",
+			Flags:       []cli.Flag{KeyTypeFlag, KeystoreFlag, InsecureEmptyPasswordFlag},
+			Action:      changeKeyPasswordMain,
+		},
+		{
+			Name:        "show",
+			Usage:       "Print the public information of a keystore",
+			Description: "CLI command to print the operator ID and G1/G2 public keys of a BLS keystore, or the address of an ECDSA keystore",
+			Flags:       []cli.Flag{KeyTypeFlag, KeystoreFlag},
+			Action:      showKeyMain,
+		},
+	},
+}
+
+func generateKeyMain(ctx *cli.Context) error {
+	keyType, err := operator.ParseKeyType(ctx.String(KeyTypeFlag.Name))
+	if err != nil {
+		return err
+	}
+	path := ctx.String(KeystoreFlag.Name)
+	password, err := readNewPassword(ctx, KeystorePasswordEnvVar, "Keystore password")
+	if err != nil {
+		return err
+	}
+
+	switch keyType {
+	case operator.KeyTypeBls:
+		keyPair, err := operator.GenerateBlsKeystore(path, password)
+		if err != nil {
+			return err
+		}
+		return printBlsKey(path, keyPair)
+	default:
+		privateKey, err := operator.GenerateEcdsaKeystore(path, password)
+		if err != nil {
+			return err
+		}
+		return printEcdsaKey(path, privateKey)
+	}
+}
+
+func importKeyMain(ctx *cli.Context) error {
+	keyType, err := operator.ParseKeyType(ctx.String(KeyTypeFlag.Name))
+	if err != nil {
+		return err
+	}
+	path := ctx.String(KeystoreFlag.Name)
+	privateKeyHex, err := readSecret(PrivateKeyEnvVar, "Private key to import, in hex")
+	if err != nil {
+		return err
+	}
+	password, err := readNewPassword(ctx, KeystorePasswordEnvVar, "Keystore password")
+	if err != nil {
+		return err
+	}
+
+	switch keyType {
+	case operator.KeyTypeBls:
+		keyPair, err := operator.ImportBlsKeystore(path, privateKeyHex, password)
+		if err != nil {
+			return err
+		}
+		return printBlsKey(path, keyPair)
+	default:
+		privateKey, err := operator.ImportEcdsaKeystore(path, privateKeyHex, password)
+		if err != nil {
+			return err
+		}
+		return printEcdsaKey(path, privateKey)
+	}
+}
+
+func changeKeyPasswordMain(ctx *cli.Context) error {
+	keyType, err := operator.ParseKeyType(ctx.String(KeyTypeFlag.Name))
+	if err != nil {
+		return err
+	}
+	path := ctx.String(KeystoreFlag.Name)
+	password, err := readSecret(KeystorePasswordEnvVar, "Current keystore password")
+	if err != nil {
+		return err
+	}
+	newPassword, err := readNewPassword(ctx, NewKeystorePasswordEnvVar, "New keystore password")
+	if err != nil {
+		return err
+	}
+
+	switch keyType {
+	case operator.KeyTypeBls:
+		_, err = operator.ChangeBlsKeystorePassword(path, password, newPassword)
+	default:
+		_, err = operator.ChangeEcdsaKeystorePassword(path, password, newPassword)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Password of %s changed\n", path)
+	return nil
+}
+
+func showKeyMain(ctx *cli.Context) error {
+	keyType, err := operator.ParseKeyType(ctx.String(KeyTypeFlag.Name))
+	if err != nil {
+		return err
+	}
+	path := ctx.String(KeystoreFlag.Name)
+	password, err := readSecret(KeystorePasswordEnvVar, "Keystore password")
+	if err != nil {
+		return err
+	}
+
+	switch keyType {
+	case operator.KeyTypeBls:
+		keyPair, err := bls.ReadPrivateKeyFromFile(path, password)
+		if err != nil {
+			return err
+		}
+		return printBlsKey(path, keyPair)
+	default:
+		privateKey, err := sdkecdsa.ReadKey(path, password)
+		if err != nil {
+			return err
+		}
+		return printEcdsaKey(path, privateKey)
+	}
+}
+
+// readSecret reads a secret from envVar or, if it's not set, prompts for it without echoing it
+func readSecret(envVar string, prompt string) (string, error) {
+	if secret, ok := os.LookupEnv(envVar); ok {
+		return secret, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("%s is not set and there's no terminal to prompt for it", envVar)
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %w", envVar, err)
+	}
+	return string(secret), nil
+}
+
+// readNewPassword reads the password to encrypt a keystore with, asking for it twice if it's prompted for.
+// An empty password is refused unless --insecure-empty-password is set.
+func readNewPassword(ctx *cli.Context, envVar string, prompt string) (string, error) {
+	_, fromEnv := os.LookupEnv(envVar)
+	password, err := readSecret(envVar, prompt)
+	if err != nil {
+		return "", err
+	}
+	if password == "" && !ctx.Bool(InsecureEmptyPasswordFlag.Name) {
+		return "", fmt.Errorf("refusing to encrypt the keystore with an empty password, pass --%s to allow it", InsecureEmptyPasswordFlag.Name)
+	}
+	if !fromEnv {
+		confirmation, err := readSecret(envVar, "Repeat "+strings.ToLower(prompt[:1])+prompt[1:])
+		if err != nil {
+			return "", err
+		}
+		if confirmation != password {
+			return "", errors.New("the passwords don't match")
+		}
+	}
+	return password, nil
+}
+
+func printBlsKey(path string, keyPair *bls.KeyPair) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Keystore:\t%s\n", path)
+	fmt.Fprintf(writer, "Operator ID:\t0x%x\n", eigentypes.OperatorIdFromKeyPair(keyPair))
+	fmt.Fprintf(writer, "G1 pubkey:\t%s\n", keyPair.GetPubKeyG1())
+	fmt.Fprintf(writer, "G2 pubkey:\t%s\n", keyPair.GetPubKeyG2())
+	return writer.Flush()
+}
+
+func printEcdsaKey(path string, privateKey *ecdsa.PrivateKey) error {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Keystore:\t%s\n", path)
+	fmt.Fprintf(writer, "Address:\t%s\n", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	return writer.Flush()
+}
@@ -0,0 +1,28 @@
+package actions
+
+import (
+	"fmt"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var OperatorIdCommand = &cli.Command{
+	Name:        "operator-id",
+	Usage:       "Print the operator ID",
+	Description: "CLI command to print the operator ID derived from the BLS key of the operator config, from its keystore or remote signer. It doesn't connect to the chain",
+	Flags:       []cli.Flag{config.ConfigFileFlag},
+	Action:      operatorIdMain,
+}
+
+func operatorIdMain(ctx *cli.Context) error {
+	blsConfig := config.NewBlsConfig(ctx.String(config.ConfigFileFlag.Name))
+	blsSigner, err := operator.NewBlsSignerFromConfig(ctx.Context, blsConfig)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("0x%x\n", eigentypes.OperatorIdFromG1Pubkey(blsSigner.PubKeyG1()))
+	return nil
+}
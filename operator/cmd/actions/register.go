@@ -26,6 +26,10 @@ func registerOperatorMain(ctx *cli.Context) error {
 	operatorConfig := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
 	ecdsaConfig := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), operatorConfig.BaseConfig.ChainId)
 
+	if operatorConfig.BlsConfig.KeyPair == nil {
+		return operator.ErrBlsKeystoreRequired
+	}
+
 	quorumNumbers := []byte{0}
 
 	// Generate salt and expiry
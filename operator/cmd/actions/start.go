@@ -1,8 +1,10 @@
 package actions
 
 import (
-	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/urfave/cli/v2"
 	"github.com/yetanotherco/aligned_layer/core/config"
@@ -39,13 +41,17 @@ func operatorMain(ctx *cli.Context) error {
 		return err
 	}
 
+	// Stop on SIGINT or SIGTERM, letting the batches in flight finish
+	runCtx, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	operator.Logger.Info("Operator starting...")
-	err = operator.Start(context.Background())
+	err = operator.Start(runCtx)
 	if err != nil {
 		return err
 	}
 
-	log.Println("Operator started")
+	log.Println("Operator stopped")
 
 	return nil
 }
@@ -0,0 +1,71 @@
+package actions
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var StatusCommand = &cli.Command{
+	Name:        "status",
+	Usage:       "Print the registration status and stake of the operator",
+	Description: "CLI command to print whether the operator is registered in Aligned Layer, its operator ID, quorum bitmap and current stake in each quorum",
+	Flags:       []cli.Flag{config.ConfigFileFlag},
+	Action:      statusMain,
+}
+
+func statusMain(ctx *cli.Context) error {
+	operatorConfig := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+
+	avsReader, err := chainio.NewAvsReaderFromConfig(operatorConfig.BaseConfig)
+	if err != nil {
+		return err
+	}
+	registration, err := avsReader.GetOperatorRegistration(operatorConfig.Operator.Address)
+	if err != nil {
+		return err
+	}
+
+	// The operator ID is derived from the BLS key, so it can be checked against the registered one
+	blsSigner, err := operator.NewBlsSignerFromConfig(ctx.Context, operatorConfig.BlsConfig)
+	if err != nil {
+		return err
+	}
+	operatorId := eigentypes.OperatorIdFromG1Pubkey(blsSigner.PubKeyG1())
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Address:\t%s\n", registration.Address.Hex())
+	fmt.Fprintf(writer, "Registered:\t%t\n", registration.Registered)
+	fmt.Fprintf(writer, "Operator ID:\t0x%x\n", operatorId)
+	if registration.OperatorId != [32]byte{} && registration.OperatorId != operatorId {
+		fmt.Fprintf(writer, "Registered operator ID:\t0x%x (does not match the BLS key)\n", registration.OperatorId)
+	}
+	fmt.Fprintf(writer, "Quorum bitmap:\t0x%s\n", registration.QuorumBitmap.Text(16))
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if !registration.Registered {
+		return nil
+	}
+
+	quorums := make([]eigentypes.QuorumNum, 0, len(registration.Stakes))
+	for quorum := range registration.Stakes {
+		quorums = append(quorums, quorum)
+	}
+	sort.Slice(quorums, func(i, j int) bool { return quorums[i] < quorums[j] })
+
+	fmt.Println()
+	writer = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "QUORUM\tSTAKE")
+	for _, quorum := range quorums {
+		fmt.Fprintf(writer, "%d\t%s\n", quorum, registration.Stakes[quorum])
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var (
+	SocketFlag = &cli.StringFlag{
+		Name:     "socket",
+		Usage:    "New socket of the operator in the registry coordinator",
+		Required: true,
+	}
+	MetadataURIFlag = &cli.StringFlag{
+		Name:  "metadata-uri",
+		Usage: "New metadata URI of the operator. If not set, the metadata URI is left as is",
+	}
+)
+
+var UpdateSocketCommand = &cli.Command{
+	Name:        "update-socket",
+	Usage:       "Update the registered socket and metadata URI of the operator",
+	Description: "CLI command to update the socket of a registered operator and, if --metadata-uri is set, its metadata URI",
+	Flags:       []cli.Flag{config.ConfigFileFlag, SocketFlag, MetadataURIFlag},
+	Action:      updateSocketMain,
+}
+
+func updateSocketMain(ctx *cli.Context) error {
+	operatorConfig := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	ecdsaConfig := config.NewEcdsaConfig(ctx.String(config.ConfigFileFlag.Name), operatorConfig.BaseConfig.ChainId)
+
+	socket := ctx.String(SocketFlag.Name)
+	err := operator.UpdateOperatorSocket(ctx.Context, operatorConfig, ecdsaConfig, socket)
+	if err != nil {
+		operatorConfig.BaseConfig.Logger.Error("Failed to update operator socket", "err", err)
+		return err
+	}
+	operatorConfig.BaseConfig.Logger.Info("Operator socket updated", "socket", socket)
+
+	if !ctx.IsSet(MetadataURIFlag.Name) {
+		return nil
+	}
+	metadataURI := ctx.String(MetadataURIFlag.Name)
+	err = operator.UpdateOperatorMetadataURI(ctx.Context, operatorConfig, ecdsaConfig, metadataURI)
+	if err != nil {
+		operatorConfig.BaseConfig.Logger.Error("Failed to update operator metadata URI", "err", err)
+		return err
+	}
+	operatorConfig.BaseConfig.Logger.Info("Operator metadata URI updated", "metadata_uri", metadataURI)
+	return nil
+}
@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"os"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/urfave/cli/v2"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var (
+	WorkerMaxMemoryFlag = &cli.Int64Flag{
+		Name:  "max-memory-bytes",
+		Usage: "Address space limit of the worker, 0 for no limit",
+	}
+	WorkerCPUSecondsFlag = &cli.Int64Flag{
+		Name:  "cpu-seconds",
+		Usage: "CPU time limit for a single proof, 0 for no limit",
+	}
+)
+
+// VerifierWorkerCommand runs a sandboxed verifier worker.
+// It's spawned by the operator itself when `verification_sandbox` is enabled, not meant to be run by hand.
+var VerifierWorkerCommand = &cli.Command{
+	Name:   operator.VerifierWorkerCommandName,
+	Usage:  "Run a sandboxed verifier worker, used internally by the operator",
+	Hidden: true,
+	Flags:  []cli.Flag{WorkerMaxMemoryFlag, WorkerCPUSecondsFlag},
+	Action: verifierWorkerMain,
+}
+
+func verifierWorkerMain(ctx *cli.Context) error {
+	logger, err := logging.NewZapLogger(logging.Production)
+	if err != nil {
+		return err
+	}
+
+	requests := os.NewFile(operator.VerifierWorkerRequestsFd, "requests")
+	responses := os.NewFile(operator.VerifierWorkerResponsesFd, "responses")
+	limits := operator.VerifierWorkerLimits{
+		MaxMemoryBytes:     ctx.Int64(WorkerMaxMemoryFlag.Name),
+		CPUSecondsPerProof: ctx.Int64(WorkerCPUSecondsFlag.Name),
+	}
+
+	return operator.RunVerifierWorker(requests, responses, operator.DefaultVerifierRegistry(), limits, logger)
+}
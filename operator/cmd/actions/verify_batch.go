@@ -0,0 +1,154 @@
+package actions
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	gnarklogger "github.com/consensys/gnark/logger"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var (
+	BatchMerkleRootFlag = &cli.StringFlag{
+		Name:     "merkle-root",
+		Usage:    "Merkle root of the batch to verify, in hex",
+		Required: true,
+	}
+	BatchSenderFlag = &cli.StringFlag{
+		Name:  "sender",
+		Usage: "Address of the batcher that submitted the batch, needed if several batches share the merkle root",
+	}
+	FromBlockFlag = &cli.Uint64Flag{
+		Name:  "from-block",
+		Usage: "Block to start looking for the batch from",
+	}
+	OutputFormatFlag = &cli.StringFlag{
+		Name:  "output",
+		Usage: "Output format: table or json",
+		Value: "table",
+	}
+)
+
+var VerifyBatchCommand = &cli.Command{
+	Name:        "verify-batch",
+	Usage:       "Verify again every proof of an on-chain batch",
+	Description: "CLI command to download and verify a batch like the operator does, without signing it nor contacting the aggregator",
+	Flags: []cli.Flag{
+		config.ConfigFileFlag,
+		BatchMerkleRootFlag,
+		BatchSenderFlag,
+		FromBlockFlag,
+		OutputFormatFlag,
+	},
+	Action: verifyBatchMain,
+}
+
+func verifyBatchMain(ctx *cli.Context) error {
+	outputFormat := ctx.String(OutputFormatFlag.Name)
+	if outputFormat != "table" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format: %s", outputFormat)
+	}
+	// The results are printed to stdout, keep the gnark logs out of them
+	gnarklogger.SetOutput(os.Stderr)
+
+	batchMerkleRoot, err := parseMerkleRoot(ctx.String(BatchMerkleRootFlag.Name))
+	if err != nil {
+		return err
+	}
+	var senderAddress *ethcommon.Address
+	if sender := ctx.String(BatchSenderFlag.Name); sender != "" {
+		if !ethcommon.IsHexAddress(sender) {
+			return fmt.Errorf("invalid sender address: %s", sender)
+		}
+		address := ethcommon.HexToAddress(sender)
+		senderAddress = &address
+	}
+
+	operatorConfig := config.NewOperatorConfig(ctx.String(config.ConfigFileFlag.Name))
+	avsReader, err := chainio.NewAvsReaderFromConfig(operatorConfig.BaseConfig)
+	if err != nil {
+		return err
+	}
+	batchVerifier, err := operator.NewBatchVerifier(*operatorConfig, avsReader, operatorConfig.BaseConfig.Logger)
+	if err != nil {
+		return err
+	}
+
+	newBatchLog, err := batchVerifier.FindBatch(batchMerkleRoot, senderAddress, ctx.Uint64(FromBlockFlag.Name))
+	if err != nil {
+		return err
+	}
+	report, err := batchVerifier.VerifyOnChainBatch(context.Background(), newBatchLog)
+	if err != nil {
+		return err
+	}
+
+	if err := printBatchReport(report, outputFormat); err != nil {
+		return err
+	}
+	if !report.Valid() {
+		return fmt.Errorf("batch 0x%x did not verify", batchMerkleRoot)
+	}
+	return nil
+}
+
+func parseMerkleRoot(merkleRoot string) ([32]byte, error) {
+	bytes, err := hex.DecodeString(strings.TrimPrefix(merkleRoot, "0x"))
+	if err != nil || len(bytes) != 32 {
+		return [32]byte{}, fmt.Errorf("invalid merkle root: %s", merkleRoot)
+	}
+	return [32]byte(bytes), nil
+}
+
+func printBatchReport(report operator.BatchReport, outputFormat string) error {
+	if outputFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if report.BatchMerkleRoot != (ethcommon.Hash{}) {
+		fmt.Printf("Batch merkle root:     %s\n", report.BatchMerkleRoot.Hex())
+	}
+	// Batches verified from a file have no on-chain data
+	if report.BlockNumber != 0 {
+		fmt.Printf("Sender address:        %s\n", report.SenderAddress.Hex())
+		fmt.Printf("Batch identifier hash: %s\n", report.BatchIdentifierHash.Hex())
+		fmt.Printf("Block number:          %d\n", report.BlockNumber)
+	}
+	fmt.Printf("Batch data pointer:    %s\n", report.BatchDataPointer)
+	if report.BlockNumber != 0 {
+		fmt.Printf("Responded on chain:    %t\n", report.Responded)
+	}
+	fmt.Println()
+	printVerdicts(report.Verdicts)
+	return nil
+}
+
+func printVerdicts(verdicts []operator.ProofVerdict) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "INDEX\tPROVING SYSTEM\tOUTCOME\tDURATION\tERROR")
+	failed := 0
+	for _, verdict := range verdicts {
+		provingSystem, err := common.ProvingSystemIdToString(verdict.ProvingSystemId)
+		if err != nil {
+			provingSystem = fmt.Sprintf("%d", verdict.ProvingSystemId)
+		}
+		if !verdict.IsValid() {
+			failed++
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", verdict.Index, provingSystem, verdict.Outcome, verdict.Duration, verdict.Error)
+	}
+	writer.Flush()
+	fmt.Printf("\n%d proofs, %d failed\n", len(verdicts), failed)
+}
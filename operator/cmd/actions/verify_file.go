@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	gnarklogger "github.com/consensys/gnark/logger"
+	"github.com/urfave/cli/v2"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	operator "github.com/yetanotherco/aligned_layer/operator/pkg"
+)
+
+var (
+	BatchFileFlag = &cli.StringFlag{
+		Name:     "file",
+		Usage:    "Path to a batch file, serialized as CBOR or JSON",
+		Required: true,
+	}
+	OptionalBatchMerkleRootFlag = &cli.StringFlag{
+		Name:  "merkle-root",
+		Usage: "Expected merkle root of the batch, in hex. If set, it's checked with the merkle tree library of the batcher before verifying the proofs, and again while verifying them",
+	}
+)
+
+var VerifyFileCommand = &cli.Command{
+	Name:        "verify-file",
+	Usage:       "Verify every proof of a local batch file",
+	Description: "CLI command to verify a batch file with the operator verifiers, without any chain or aggregator configuration",
+	Flags: []cli.Flag{
+		BatchFileFlag,
+		OptionalBatchMerkleRootFlag,
+		OutputFormatFlag,
+	},
+	Action: verifyFileMain,
+}
+
+func verifyFileMain(ctx *cli.Context) error {
+	outputFormat := ctx.String(OutputFormatFlag.Name)
+	if outputFormat != "table" && outputFormat != "json" {
+		return fmt.Errorf("invalid output format: %s", outputFormat)
+	}
+	gnarklogger.SetOutput(os.Stderr)
+
+	var expectedMerkleRoot *[32]byte
+	if merkleRoot := ctx.String(OptionalBatchMerkleRootFlag.Name); merkleRoot != "" {
+		batchMerkleRoot, err := parseMerkleRoot(merkleRoot)
+		if err != nil {
+			return err
+		}
+		expectedMerkleRoot = &batchMerkleRoot
+	}
+
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		return err
+	}
+	batchVerifier, err := operator.NewBatchVerifier(config.OperatorConfig{}, nil, logger)
+	if err != nil {
+		return err
+	}
+
+	report, err := batchVerifier.VerifyBatchFile(context.Background(), ctx.String(BatchFileFlag.Name), expectedMerkleRoot)
+	if err != nil {
+		return err
+	}
+
+	if err := printBatchReport(report, outputFormat); err != nil {
+		return err
+	}
+	if !report.Valid() {
+		return fmt.Errorf("batch file %s did not verify", report.BatchDataPointer)
+	}
+	return nil
+}
@@ -18,8 +18,18 @@ func main() {
 		Name: "Aligned Layer Node Operator",
 		Commands: []*cli.Command{
 			actions.RegisterCommand,
+			actions.DeregisterCommand,
+			actions.StatusCommand,
+			actions.UpdateSocketCommand,
+			actions.OperatorIdCommand,
+			actions.KeysCommand,
+			actions.BlsSignerCommand,
 			actions.StartCommand,
 			actions.DepositIntoStrategyCommand,
+			actions.VerifyBatchCommand,
+			actions.VerifyFileCommand,
+			actions.VerifierWorkerCommand,
+			actions.BatchCacheCommand,
 		},
 		Version: Version,
 	}
@@ -0,0 +1,212 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/core/aggregatorapi"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// AggregatorDelivery is how signed task responses are delivered to the aggregators
+type AggregatorDelivery string
+
+const (
+	// AggregatorDeliveryAll sends every response to all the aggregators at the same time
+	AggregatorDeliveryAll AggregatorDelivery = "all"
+	// AggregatorDeliveryPriority sends every response to the first aggregator, in order, accepting it
+	AggregatorDeliveryPriority AggregatorDelivery = "priority"
+)
+
+// AggregatorFanOut delivers signed task responses to several aggregators, so redundant aggregators
+// get them all. Each aggregator has its own connection and retry state, see AggregatorRpcClient.
+type AggregatorFanOut struct {
+	aggregators []*AggregatorRpcClient
+	delivery    AggregatorDelivery
+	logger      logging.Logger
+}
+
+// NewAggregatorFanOut connects to the aggregators at aggregatorIpPortAddrs. The aggregators that can't
+// be reached yet are connected to on the first response; it only fails if none of them can be reached.
+func NewAggregatorFanOut(aggregatorIpPortAddrs []string, delivery AggregatorDelivery, protocol AggregatorProtocol, logger logging.Logger) (*AggregatorFanOut, error) {
+	if delivery == "" {
+		delivery = AggregatorDeliveryAll
+	}
+	if delivery != AggregatorDeliveryAll && delivery != AggregatorDeliveryPriority {
+		return nil, fmt.Errorf("invalid aggregators delivery: %s", delivery)
+	}
+	if protocol == "" {
+		protocol = AggregatorProtocolJson
+	}
+	if protocol != AggregatorProtocolJson && protocol != AggregatorProtocolGob {
+		return nil, fmt.Errorf("invalid aggregators protocol: %s", protocol)
+	}
+	if len(aggregatorIpPortAddrs) == 0 {
+		return nil, errors.New("no aggregator address")
+	}
+
+	aggregators := make([]*AggregatorRpcClient, len(aggregatorIpPortAddrs))
+	var errs []error
+	for i, aggregatorIpPortAddr := range aggregatorIpPortAddrs {
+		aggregators[i] = newAggregatorRpcClient(aggregatorIpPortAddr, protocol, logger)
+		if err := aggregators[i].connect(); err != nil {
+			logger.Warn("Could not connect to aggregator", "aggregator", aggregatorIpPortAddr, "err", err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(aggregators) {
+		return nil, fmt.Errorf("could not connect to any aggregator: %w", errors.Join(errs...))
+	}
+
+	return &AggregatorFanOut{
+		aggregators: aggregators,
+		delivery:    delivery,
+		logger:      logger,
+	}, nil
+}
+
+// OnDelivery sets a callback invoked after every attempt to deliver a response to an aggregator,
+// with AggregatorDeliveryAccepted or AggregatorDeliveryError as result
+func (f *AggregatorFanOut) OnDelivery(callback func(aggregator string, result string, latency time.Duration)) {
+	for _, aggregator := range f.aggregators {
+		aggregator.onDelivery = callback
+	}
+}
+
+// OnReconnect sets a callback invoked after every reconnection to an aggregator, with its error if it failed
+func (f *AggregatorFanOut) OnReconnect(callback func(aggregator string, err error)) {
+	for _, aggregator := range f.aggregators {
+		aggregator.onReconnect = callback
+	}
+}
+
+// SendSignedTaskResponseToAggregator delivers the signed task response as configured. Returns an error
+// if no aggregator accepted it, or the cause of ctx once it's done.
+func (f *AggregatorFanOut) SendSignedTaskResponseToAggregator(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) error {
+	if f.delivery == AggregatorDeliveryPriority {
+		return f.sendInPriorityOrder(ctx, signedTaskResponse)
+	}
+	return f.sendToAll(ctx, signedTaskResponse)
+}
+
+// sendToAll sends the response to every aggregator, each with its own retries,
+// and succeeds if any of them accepted it
+func (f *AggregatorFanOut) sendToAll(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) error {
+	errs := make([]error, len(f.aggregators))
+	var wg sync.WaitGroup
+	for i, aggregator := range f.aggregators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = aggregator.SendSignedTaskResponseToAggregator(ctx, signedTaskResponse)
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	var accepted bool
+	for i, err := range errs {
+		if err == nil {
+			accepted = true
+		} else if len(f.aggregators) > 1 {
+			f.logger.Warn("Signed task response not delivered to aggregator", "aggregator", f.aggregators[i].aggregatorIpPortAddr, "err", err)
+		}
+	}
+	if accepted {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// sendInPriorityOrder tries the aggregators in order until one accepts the response, skipping the ones waiting
+// to be retried after a failure and the ones that rejected it for good. If all of them failed, it waits for
+// the first one to be retried, up to MaxRetries times.
+func (f *AggregatorFanOut) sendInPriorityOrder(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) error {
+	var errs []error
+	rejected := make([]bool, len(f.aggregators))
+	for retries := 0; retries < MaxRetries; {
+		now := time.Now()
+		nextAttempt := time.Time{}
+		tried := false
+		for i, aggregator := range f.aggregators {
+			if rejected[i] {
+				continue
+			}
+			if retryAt := aggregator.nextAttempt(); retryAt.After(now) {
+				if nextAttempt.IsZero() || retryAt.Before(nextAttempt) {
+					nextAttempt = retryAt
+				}
+				continue
+			}
+			tried = true
+			err := aggregator.attempt(ctx, signedTaskResponse)
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			if err == nil {
+				return nil
+			}
+			rejected[i] = !aggregatorapi.IsRetryable(err)
+			errs = append(errs, err)
+		}
+		if !slices.Contains(rejected, false) {
+			return fmt.Errorf("signed task response rejected by every aggregator: %w", errors.Join(errs...))
+		}
+		if tried {
+			retries++
+			continue
+		}
+		if err := sleepContext(ctx, time.Until(nextAttempt)); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("signed task response not accepted by any aggregator after %d retries: %w", MaxRetries, errors.Join(errs...))
+}
+
+// Ping checks that any of the aggregators accepts connections
+func (f *AggregatorFanOut) Ping(timeout time.Duration) error {
+	var errs []error
+	for _, aggregator := range f.aggregators {
+		err := aggregator.Ping(timeout)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the connections to every aggregator
+func (f *AggregatorFanOut) Close() error {
+	var errs []error
+	for _, aggregator := range f.aggregators {
+		if err := aggregator.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// aggregatorAddressesFromConfig is the `aggregator_rpc_server_ip_port_address` followed by
+// the `aggregators.addresses` of the config
+func aggregatorAddressesFromConfig(configuration config.OperatorConfig) []string {
+	var addresses []string
+	if configuration.Operator.AggregatorServerIpPortAddress != "" {
+		addresses = append(addresses, configuration.Operator.AggregatorServerIpPortAddress)
+	}
+	return append(addresses, configuration.Operator.Aggregators.Addresses...)
+}
+
+func newAggregatorFanOutFromConfig(configuration config.OperatorConfig, logger logging.Logger) (*AggregatorFanOut, error) {
+	aggregatorsConfig := configuration.Operator.Aggregators
+	return NewAggregatorFanOut(aggregatorAddressesFromConfig(configuration), AggregatorDelivery(aggregatorsConfig.Delivery),
+		AggregatorProtocol(aggregatorsConfig.Protocol), logger)
+}
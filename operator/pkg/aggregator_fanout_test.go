@@ -0,0 +1,273 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/rpc"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/yetanotherco/aligned_layer/core/aggregatorapi"
+	"github.com/yetanotherco/aligned_layer/core/types"
+)
+
+// countingAggregator counts the signed task responses it receives, rejecting them if err is set
+type countingAggregator struct {
+	received atomic.Int32
+	err      error
+}
+
+func (a *countingAggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *types.SignedTaskResponse, reply *uint8) error {
+	a.received.Add(1)
+	return a.err
+}
+
+// newTestAggregator serves aggregator over RPC and returns its address
+func newTestAggregator(t *testing.T, aggregator *countingAggregator) string {
+	t.Helper()
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("Aggregator", aggregator); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(rpcServer)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// unreachableAddress returns an address nothing listens on
+func unreachableAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
+
+// deliveryResults records the results reported by the OnDelivery callback, by aggregator
+type deliveryResults struct {
+	mutex   sync.Mutex
+	results map[string][]string
+}
+
+func recordDeliveries(fanOut *AggregatorFanOut) *deliveryResults {
+	deliveries := &deliveryResults{results: make(map[string][]string)}
+	fanOut.OnDelivery(func(aggregator string, result string, latency time.Duration) {
+		deliveries.mutex.Lock()
+		defer deliveries.mutex.Unlock()
+		deliveries.results[aggregator] = append(deliveries.results[aggregator], result)
+	})
+	return deliveries
+}
+
+func (d *deliveryResults) of(aggregator string) []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.results[aggregator]
+}
+
+func TestAggregatorFanOutDeliversToAll(t *testing.T) {
+	first, second := &countingAggregator{}, &countingAggregator{}
+	addresses := []string{newTestAggregator(t, first), newTestAggregator(t, second)}
+
+	fanOut, err := NewAggregatorFanOut(addresses, AggregatorDeliveryAll, AggregatorProtocolGob, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries := recordDeliveries(fanOut)
+
+	if err := fanOut.SendSignedTaskResponseToAggregator(context.Background(), &types.SignedTaskResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if first.received.Load() != 1 || second.received.Load() != 1 {
+		t.Errorf("expected the response delivered to both aggregators, got %d and %d", first.received.Load(), second.received.Load())
+	}
+	for _, address := range addresses {
+		if results := deliveries.of(address); len(results) != 1 || results[0] != AggregatorDeliveryAccepted {
+			t.Errorf("expected one accepted delivery to %s, got %v", address, results)
+		}
+	}
+}
+
+func TestAggregatorFanOutFailsOverInPriorityOrder(t *testing.T) {
+	failing, fallback := &countingAggregator{err: errors.New("not ready")}, &countingAggregator{}
+	addresses := []string{newTestAggregator(t, failing), newTestAggregator(t, fallback)}
+
+	fanOut, err := NewAggregatorFanOut(addresses, AggregatorDeliveryPriority, AggregatorProtocolGob, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries := recordDeliveries(fanOut)
+
+	// Fails over without waiting RetryInterval
+	ctx, cancel := context.WithTimeout(context.Background(), RetryInterval/2)
+	defer cancel()
+	if err := fanOut.SendSignedTaskResponseToAggregator(ctx, &types.SignedTaskResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if failing.received.Load() != 1 || fallback.received.Load() != 1 {
+		t.Errorf("expected the response tried on the first aggregator and delivered to the second, got %d and %d",
+			failing.received.Load(), fallback.received.Load())
+	}
+	if results := deliveries.of(addresses[0]); len(results) != 1 || results[0] != AggregatorDeliveryError {
+		t.Errorf("expected one failed delivery to the first aggregator, got %v", results)
+	}
+
+	// The first aggregator is waiting to be retried, so the next response goes straight to the second one
+	if err := fanOut.SendSignedTaskResponseToAggregator(ctx, &types.SignedTaskResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if failing.received.Load() != 1 || fallback.received.Load() != 2 {
+		t.Errorf("expected the first aggregator to be skipped while waiting to be retried, got %d and %d",
+			failing.received.Load(), fallback.received.Load())
+	}
+}
+
+func TestAggregatorFanOutConnectsToReachableAggregators(t *testing.T) {
+	aggregator := &countingAggregator{}
+	unreachable := unreachableAddress(t)
+	addresses := []string{unreachable, newTestAggregator(t, aggregator)}
+
+	fanOut, err := NewAggregatorFanOut(addresses, AggregatorDeliveryPriority, AggregatorProtocolGob, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("expected the fan-out to be created with a reachable aggregator, got %v", err)
+	}
+	var reconnections atomic.Int32
+	fanOut.OnReconnect(func(aggregator string, err error) {
+		if aggregator == unreachable && err != nil {
+			reconnections.Add(1)
+		}
+	})
+
+	if err := fanOut.SendSignedTaskResponseToAggregator(context.Background(), &types.SignedTaskResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if aggregator.received.Load() != 1 {
+		t.Errorf("expected the response delivered to the reachable aggregator, got %d", aggregator.received.Load())
+	}
+	if reconnections.Load() != 1 {
+		t.Errorf("expected one failed reconnection to the unreachable aggregator, got %d", reconnections.Load())
+	}
+	if err := fanOut.Ping(AggregatorDialTimeout); err != nil {
+		t.Errorf("expected the ping to succeed with a reachable aggregator, got %v", err)
+	}
+
+	if _, err := NewAggregatorFanOut([]string{unreachable}, AggregatorDeliveryAll, AggregatorProtocolGob, newTestLogger(t)); err == nil {
+		t.Error("expected the fan-out not to be created without any reachable aggregator")
+	}
+	if _, err := NewAggregatorFanOut(addresses, "random", AggregatorProtocolGob, newTestLogger(t)); err == nil {
+		t.Error("expected an invalid delivery to be rejected")
+	}
+}
+
+// jsonAggregator serves the JSON API, counting the signed task responses it receives and replying err if set
+type jsonAggregator struct {
+	received atomic.Int32
+	err      error
+	// hang makes the aggregator never reply
+	hang bool
+}
+
+func (a *jsonAggregator) ProcessSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) (aggregatorapi.TaskState, error) {
+	a.received.Add(1)
+	if a.hang {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	if a.err != nil {
+		return "", a.err
+	}
+	return aggregatorapi.TaskStatePending, nil
+}
+
+func (a *jsonAggregator) GetTaskStatus(batchIdentifierHash [32]byte) (*aggregatorapi.TaskStatus, error) {
+	return nil, aggregatorapi.NewError(aggregatorapi.ErrorCodeTaskNotFound, "task not found")
+}
+
+func newTestJsonAggregator(t *testing.T, aggregator *jsonAggregator) string {
+	t.Helper()
+	server := httptest.NewServer(aggregatorapi.NewHandler(aggregator))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestAggregatorFanOutOverJsonApi(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedTaskResponse := &types.SignedTaskResponse{BlsSignature: *keyPair.SignMessage([32]byte{1})}
+
+	rejecting := &jsonAggregator{err: aggregatorapi.NewError(aggregatorapi.ErrorCodeSignatureRejected, "already signed")}
+	accepting := &jsonAggregator{}
+	addresses := []string{newTestJsonAggregator(t, rejecting), newTestJsonAggregator(t, accepting)}
+
+	fanOut, err := NewAggregatorFanOut(addresses, AggregatorDeliveryPriority, AggregatorProtocolJson, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fanOut.SendSignedTaskResponseToAggregator(context.Background(), signedTaskResponse); err != nil {
+		t.Fatal(err)
+	}
+	if rejecting.received.Load() != 1 || accepting.received.Load() != 1 {
+		t.Errorf("expected the response rejected by the first aggregator and delivered to the second, got %d and %d",
+			rejecting.received.Load(), accepting.received.Load())
+	}
+
+	// Responses rejected for good are not retried
+	onlyRejecting, err := NewAggregatorFanOut(addresses[:1], AggregatorDeliveryAll, AggregatorProtocolJson, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), RetryInterval/2)
+	defer cancel()
+	err = onlyRejecting.SendSignedTaskResponseToAggregator(ctx, signedTaskResponse)
+	var apiErr *aggregatorapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != aggregatorapi.ErrorCodeSignatureRejected {
+		t.Errorf("expected the rejection of the aggregator, got %v", err)
+	}
+	if rejecting.received.Load() != 2 {
+		t.Errorf("expected the rejected response not to be retried, got %d attempts", rejecting.received.Load()-1)
+	}
+
+	// Aggregators only serving the gob RPC are reported on startup
+	gobOnly := newTestAggregator(t, &countingAggregator{})
+	if _, err := NewAggregatorFanOut([]string{gobOnly}, AggregatorDeliveryAll, AggregatorProtocolJson, newTestLogger(t)); err == nil {
+		t.Error("expected the fan-out not to be created without any aggregator serving the JSON API")
+	}
+}
+
+func TestAggregatorRpcClientTimesOutEachAttempt(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedTaskResponse := &types.SignedTaskResponse{BlsSignature: *keyPair.SignMessage([32]byte{1})}
+
+	hanging := &jsonAggregator{hang: true}
+	client := newAggregatorRpcClient(newTestJsonAggregator(t, hanging), AggregatorProtocolJson, newTestLogger(t))
+	client.requestTimeout = 100 * time.Millisecond
+	if err := client.connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	startTime := time.Now()
+	err = client.attempt(context.Background(), signedTaskResponse)
+	if err == nil || !aggregatorapi.IsRetryable(err) {
+		t.Errorf("expected a retryable error once the attempt timed out, got %v", err)
+	}
+	if elapsed := time.Since(startTime); elapsed > 5*time.Second {
+		t.Errorf("expected the attempt to time out, it took %v", elapsed)
+	}
+	if client.nextAttempt().Before(time.Now()) {
+		t.Error("expected the aggregator to be retried after RetryInterval")
+	}
+}
@@ -0,0 +1,248 @@
+package operator
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/lru"
+)
+
+// DefaultBatchCacheMaxBytes is the size of the batch cache when `batch_cache.max_bytes` is not set
+const DefaultBatchCacheMaxBytes = 1 << 30
+
+const batchCacheExtension = ".batch"
+
+// BatchCache stores downloaded batches on disk, keyed by merkle root, so batches processed again
+// (replayed on restart, delivered twice or received from both the V2 and V3 events) are not downloaded again.
+// Batches are only stored once their merkle root is verified, and are verified again when read,
+// so the cache doesn't need to be trusted. The least recently used batches are evicted when the
+// stored batches exceed the max size.
+type BatchCache struct {
+	dir      string
+	batches  *lru.Cache[[32]byte, struct{}]
+	onLookup func(hit bool)
+	onStore  func(evicted int, size int64)
+	// evicted counts the evictions of the batch being stored
+	evicted int
+	mutex   sync.Mutex
+}
+
+// CachedBatch describes a batch stored in the cache
+type CachedBatch struct {
+	MerkleRoot [32]byte
+	Size       int64
+	LastUsed   time.Time
+}
+
+// NewBatchCache opens the batch cache in dir, creating it if needed and loading the batches already stored,
+// most recently used first, up to maxBytes
+func NewBatchCache(dir string, maxBytes int64) (*BatchCache, error) {
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("invalid batch cache max bytes: %d", maxBytes)
+	}
+	if maxBytes == 0 {
+		maxBytes = DefaultBatchCacheMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create batch cache directory: %w", err)
+	}
+
+	cache := &BatchCache{dir: dir, batches: lru.New[[32]byte, struct{}](maxBytes)}
+	cache.batches.OnEvict(func(merkleRoot [32]byte, _ struct{}) {
+		os.Remove(cache.pathFor(merkleRoot))
+		cache.evicted++
+	})
+
+	// Batches left behind by a crash while downloading them. Recent ones may belong to a running operator.
+	tmpFiles, _ := filepath.Glob(filepath.Join(dir, "tmp-*"))
+	for _, tmpFile := range tmpFiles {
+		if info, err := os.Stat(tmpFile); err == nil && time.Since(info.ModTime()) > time.Hour {
+			os.Remove(tmpFile)
+		}
+	}
+
+	batches, err := cache.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(batches) - 1; i >= 0; i-- {
+		cache.batches.Add(batches[i].MerkleRoot, struct{}{}, batches[i].Size)
+	}
+	return cache, nil
+}
+
+// OnLookup sets a callback invoked on every lookup
+func (c *BatchCache) OnLookup(callback func(hit bool)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onLookup = callback
+}
+
+// OnStore sets a callback invoked after storing a batch, with the number of batches evicted for it
+// and the size of the stored batches
+func (c *BatchCache) OnStore(callback func(evicted int, size int64)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onStore = callback
+}
+
+// Open returns the stored batch with merkleRoot, marking it as the most recently used
+func (c *BatchCache) Open(merkleRoot [32]byte) (*os.File, bool) {
+	c.mutex.Lock()
+	onLookup := c.onLookup
+	c.mutex.Unlock()
+
+	var batch *os.File
+	if _, ok := c.batches.Get(merkleRoot); ok {
+		var err error
+		if batch, err = os.Open(c.pathFor(merkleRoot)); err != nil {
+			// Removed from outside the operator, like with the `batch-cache purge` command
+			c.batches.Remove(merkleRoot)
+			batch = nil
+		} else {
+			// The modification time keeps the order of use across restarts
+			now := time.Now()
+			os.Chtimes(batch.Name(), now, now)
+		}
+	}
+
+	if onLookup != nil {
+		onLookup(batch != nil)
+	}
+	return batch, batch != nil
+}
+
+// Create returns a pendingBatch to write a batch as it's downloaded, and store it once its merkle root is verified
+func (c *BatchCache) Create() (*pendingBatch, error) {
+	file, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	return &pendingBatch{cache: c, file: file}, nil
+}
+
+// Remove deletes the stored batch with merkleRoot, if any
+func (c *BatchCache) Remove(merkleRoot [32]byte) error {
+	c.batches.Remove(merkleRoot)
+	if err := os.Remove(c.pathFor(merkleRoot)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Purge deletes every stored batch, returning how many were deleted
+func (c *BatchCache) Purge() (int, error) {
+	batches, err := c.List()
+	if err != nil {
+		return 0, err
+	}
+	for _, batch := range batches {
+		if err := c.Remove(batch.MerkleRoot); err != nil {
+			return 0, err
+		}
+	}
+	return len(batches), nil
+}
+
+// List returns the stored batches, most recently used first
+func (c *BatchCache) List() ([]CachedBatch, error) {
+	return ListBatchCache(c.dir)
+}
+
+// ListBatchCache returns the batches stored in the batch cache in dir, most recently used first.
+// Unlike NewBatchCache it only reads the directory, so it doesn't change the cache of a running operator.
+func ListBatchCache(dir string) ([]CachedBatch, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []CachedBatch
+	for _, entry := range entries {
+		name := entry.Name()
+		merkleRoot, err := hex.DecodeString(strings.TrimSuffix(name, batchCacheExtension))
+		if !strings.HasSuffix(name, batchCacheExtension) || err != nil || len(merkleRoot) != 32 {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		batches = append(batches, CachedBatch{MerkleRoot: [32]byte(merkleRoot), Size: info.Size(), LastUsed: info.ModTime()})
+	}
+
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].LastUsed.After(batches[j].LastUsed)
+	})
+	return batches, nil
+}
+
+// Size returns the size of the stored batches, in bytes
+func (c *BatchCache) Size() int64 {
+	return c.batches.Cost()
+}
+
+func (c *BatchCache) pathFor(merkleRoot [32]byte) string {
+	return filepath.Join(c.dir, hex.EncodeToString(merkleRoot[:])+batchCacheExtension)
+}
+
+// pendingBatch is a batch being written to the cache as it's downloaded.
+// Writing never fails, so a full disk doesn't fail the download: the error is returned by Commit.
+type pendingBatch struct {
+	cache *BatchCache
+	file  *os.File
+	size  int64
+	err   error
+}
+
+func (p *pendingBatch) Write(b []byte) (int, error) {
+	if p.err == nil {
+		var n int
+		n, p.err = p.file.Write(b)
+		p.size += int64(n)
+	}
+	return len(b), nil
+}
+
+// Commit stores the written batch, which must have merkleRoot
+func (p *pendingBatch) Commit(merkleRoot [32]byte) error {
+	if err := errors.Join(p.err, p.file.Close()); err != nil {
+		os.Remove(p.file.Name())
+		return err
+	}
+
+	c := p.cache
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := os.Rename(p.file.Name(), c.pathFor(merkleRoot)); err != nil {
+		os.Remove(p.file.Name())
+		return err
+	}
+	c.evicted = 0
+	c.batches.Add(merkleRoot, struct{}{}, p.size)
+	// Batches larger than the whole cache are not stored
+	if _, ok := c.batches.Get(merkleRoot); !ok {
+		os.Remove(c.pathFor(merkleRoot))
+	}
+
+	if c.onStore != nil {
+		c.onStore(c.evicted, c.batches.Cost())
+	}
+	return nil
+}
+
+// Discard deletes the written batch if it was not committed
+func (p *pendingBatch) Discard() {
+	p.file.Close()
+	os.Remove(p.file.Name())
+}
@@ -0,0 +1,155 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+func storeTestBatch(t *testing.T, cache *BatchCache, merkleRoot [32]byte, batch []byte) {
+	pending, err := cache.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pending.Discard()
+	pending.Write(batch)
+	if err := pending.Commit(merkleRoot); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatchCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewBatchCache(dir, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evictions int
+	cache.OnStore(func(evicted int, size int64) {
+		evictions += evicted
+	})
+
+	a, b, c := [32]byte{1}, [32]byte{2}, [32]byte{3}
+	storeTestBatch(t, cache, a, bytes.Repeat([]byte{1}, 100))
+	storeTestBatch(t, cache, b, bytes.Repeat([]byte{2}, 100))
+	// Reading a makes b the least recently used batch
+	batch, ok := cache.Open(a)
+	if !ok {
+		t.Fatal("expected a to be cached")
+	}
+	batch.Close()
+	storeTestBatch(t, cache, c, bytes.Repeat([]byte{3}, 100))
+
+	if _, ok := cache.Open(b); ok || evictions != 1 {
+		t.Errorf("expected b to be evicted, got %d evictions", evictions)
+	}
+	if _, err := os.Stat(cache.pathFor(b)); !os.IsNotExist(err) {
+		t.Errorf("expected the file of b to be removed, got %v", err)
+	}
+	if cache.Size() != 200 {
+		t.Errorf("expected 200 cached bytes, got %d", cache.Size())
+	}
+
+	// The cached batches survive restarts
+	reopened, err := NewBatchCache(dir, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches, err := reopened.List()
+	if err != nil || len(batches) != 2 || reopened.Size() != 200 {
+		t.Fatalf("expected 2 cached batches after reopening the cache, got %v, %v", batches, err)
+	}
+
+	if purged, err := reopened.Purge(); err != nil || purged != 2 {
+		t.Errorf("expected 2 purged batches, got %d, %v", purged, err)
+	}
+	if _, ok := reopened.Open(a); ok {
+		t.Error("expected a to be purged")
+	}
+}
+
+func TestListBatchCacheLeavesTheCacheUntouched(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewBatchCache(dir, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := [32]byte{1}, [32]byte{2}
+	storeTestBatch(t, cache, a, bytes.Repeat([]byte{1}, 100))
+	storeTestBatch(t, cache, b, bytes.Repeat([]byte{2}, 100))
+	// A download of a running operator, older than the limit NewBatchCache deletes them at
+	tmpFile := filepath.Join(dir, "tmp-download")
+	if err := os.WriteFile(tmpFile, []byte{3}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(tmpFile, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	batches, err := ListBatchCache(dir)
+	if err != nil || len(batches) != 2 {
+		t.Fatalf("expected 2 cached batches, got %v, %v", batches, err)
+	}
+	for _, path := range []string{cache.pathFor(a), cache.pathFor(b), tmpFile} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be kept, got %v", path, err)
+		}
+	}
+}
+
+func TestOperatorReadsBatchesFromBatchCache(t *testing.T) {
+	batchBytes, merkleRoot := readTestBatch(t)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write(batchBytes)
+	}))
+	defer server.Close()
+
+	var configuration config.OperatorConfig
+	configuration.Operator.MaxBatchSize = 1 << 20
+	batchVerifier, err := NewBatchVerifier(configuration, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := batchVerifier.operator
+	if operator.batchCache, err = NewBatchCache(t.TempDir(), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	getVerdicts := func() {
+		t.Helper()
+		verdicts, err := operator.getBatchVerdictsFromDataService(context.Background(), server.URL+"/batch.cbor", merkleRoot, big.NewInt(0), false, nil)
+		if err != nil || len(verdicts) != 35 {
+			t.Fatalf("expected 35 verdicts, got %d, %v", len(verdicts), err)
+		}
+	}
+
+	getVerdicts()
+	getVerdicts()
+	if requests.Load() != 1 {
+		t.Errorf("expected the batch to be downloaded once, got %d downloads", requests.Load())
+	}
+
+	// A corrupted batch is downloaded again
+	if err := os.WriteFile(operator.batchCache.pathFor(merkleRoot), batchBytes[:100], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	getVerdicts()
+	if requests.Load() != 2 {
+		t.Errorf("expected the corrupted batch to be downloaded again, got %d downloads", requests.Load())
+	}
+	if cached, err := os.ReadFile(operator.batchCache.pathFor(merkleRoot)); err != nil || !bytes.Equal(cached, batchBytes) {
+		t.Errorf("expected the batch to be cached again, got %d bytes, %v", len(cached), err)
+	}
+}
@@ -0,0 +1,547 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// ErrBatchTooLarge is wrapped by fetchers when a batch is over the max batch size. It's not retried.
+var ErrBatchTooLarge = errors.New("batch exceeds max batch size")
+
+// ErrUnsupportedBatchDataPointer is returned for malformed pointers or with a scheme no fetcher is registered for.
+// It's not retried.
+var ErrUnsupportedBatchDataPointer = errors.New("unsupported batch data pointer")
+
+// ErrBatchNotResumable is returned by fetchers that can't continue an interrupted download. It's not retried.
+var ErrBatchNotResumable = errors.New("batch download can't be resumed")
+
+// ErrBatchChanged is returned when a download is resumed but the batch served is not the one started. It's not retried.
+var ErrBatchChanged = errors.New("batch changed while downloading it")
+
+// BatchFetcher downloads the serialized batch a BatchDataPointer points to.
+// Fetchers don't check the content: the merkle root of the batch is the only integrity check, whatever the source.
+type BatchFetcher interface {
+	// Open returns the batch at pointer as a stream, failing with ErrBatchTooLarge if it's known to be over maxSize bytes.
+	// The stream is only valid until ctx is done.
+	Open(ctx context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error)
+}
+
+// ResumableBatchFetcher is a BatchFetcher that can continue an interrupted download
+type ResumableBatchFetcher interface {
+	BatchFetcher
+	// OpenAt returns the batch at pointer from offset on, which must be the same batch opened before.
+	// Fails with ErrBatchChanged if the batch changed, or ErrBatchNotResumable if it can't be resumed.
+	OpenAt(ctx context.Context, pointer *url.URL, offset int64, maxSize int64) (io.ReadCloser, error)
+}
+
+// BatchFetcherPolicy is the timeout and retry policy of a fetcher
+type BatchFetcherPolicy struct {
+	// Timeout bounds each attempt, including reading the whole batch. The time the consumer of
+	// the batch spends between reads, like verifying the proofs already read, doesn't count.
+	Timeout time.Duration
+	// MaxRetries is the number of attempts, including the first one
+	MaxRetries int
+	// RetryDelay is the wait before the first retry, doubled on every following one
+	RetryDelay time.Duration
+}
+
+type registeredBatchFetcher struct {
+	fetcher BatchFetcher
+	policy  BatchFetcherPolicy
+}
+
+// BatchFetchers picks the fetcher of a BatchDataPointer by its URL scheme
+type BatchFetchers struct {
+	fetchers map[string]registeredBatchFetcher
+	logger   logging.Logger
+}
+
+func NewBatchFetchers(logger logging.Logger) *BatchFetchers {
+	return &BatchFetchers{
+		fetchers: make(map[string]registeredBatchFetcher),
+		logger:   logger,
+	}
+}
+
+// Register sets the fetcher and policy of a URL scheme, replacing any previous one
+func (f *BatchFetchers) Register(scheme string, fetcher BatchFetcher, policy BatchFetcherPolicy) {
+	f.fetchers[scheme] = registeredBatchFetcher{fetcher: fetcher, policy: policy}
+}
+
+// Open opens the batch at batchDataPointer with the fetcher of its scheme, retrying according to its policy.
+// If the download is interrupted and the fetcher is resumable, reading the returned stream resumes it where it stopped
+// with the remaining attempts of the policy. Reading fails if the batch turns out to be over maxSize bytes.
+func (f *BatchFetchers) Open(ctx context.Context, batchDataPointer string, maxSize int64) (io.ReadCloser, error) {
+	pointer, err := url.Parse(batchDataPointer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedBatchDataPointer, err)
+	}
+	registered, ok := f.fetchers[strings.ToLower(pointer.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown scheme %q", ErrUnsupportedBatchDataPointer, pointer.Scheme)
+	}
+
+	reader := &batchReader{
+		ctx:        ctx,
+		pointer:    pointer,
+		fetcher:    registered.fetcher,
+		policy:     registered.policy,
+		retryDelay: registered.policy.RetryDelay,
+		maxSize:    maxSize,
+		logger:     f.logger,
+	}
+	if err := reader.open(); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// batchReader reads at most maxSize bytes of a batch, failing with ErrBatchTooLarge if there's more.
+// Each attempt to download the batch has its own timeout, and ends once the reader is closed.
+// The timeout only runs while opening the batch and inside Read, so a consumer slowed down by
+// the verification of the proofs doesn't make the download time out.
+type batchReader struct {
+	ctx        context.Context
+	pointer    *url.URL
+	fetcher    BatchFetcher
+	policy     BatchFetcherPolicy
+	attempt    int
+	retryDelay time.Duration
+	batch      io.ReadCloser
+	deadline   *attemptDeadline
+	offset     int64
+	maxSize    int64
+	logger     logging.Logger
+}
+
+// open opens the batch from the current offset, retrying according to the policy
+func (r *batchReader) open() error {
+	for {
+		if r.attempt > 0 {
+			r.logger.Infof("Waiting for %s before retrying data fetch (attempt %d of %d)", r.retryDelay, r.attempt+1, r.policy.MaxRetries)
+			select {
+			case <-time.After(r.retryDelay):
+				// Wait before retrying
+			case <-r.ctx.Done():
+				return r.ctx.Err()
+			}
+			r.retryDelay *= 2 // Exponential backoff. Ex: 5s, 10s, 20s
+		}
+		r.attempt++
+
+		attemptCtx, deadline := newAttemptDeadline(r.ctx, r.policy.Timeout)
+		batch, err := r.openAt(attemptCtx)
+		if err == nil {
+			deadline.pause()
+			r.batch, r.deadline = batch, deadline
+			return nil
+		}
+		deadline.stop()
+
+		r.logger.Warnf("Error fetching batch from data service - (attempt %d): %v", r.attempt, err)
+		if !r.retryable(err) {
+			return err
+		}
+	}
+}
+
+func (r *batchReader) openAt(ctx context.Context) (io.ReadCloser, error) {
+	if r.offset == 0 {
+		return r.fetcher.Open(ctx, r.pointer, r.maxSize)
+	}
+	resumable, ok := r.fetcher.(ResumableBatchFetcher)
+	if !ok {
+		return nil, ErrBatchNotResumable
+	}
+	return resumable.OpenAt(ctx, r.pointer, r.offset, r.maxSize)
+}
+
+// retryable returns whether another attempt can follow the failed one
+func (r *batchReader) retryable(err error) bool {
+	if errors.Is(err, ErrBatchTooLarge) || errors.Is(err, ErrUnsupportedBatchDataPointer) || errors.Is(err, ErrBatchNotResumable) || errors.Is(err, ErrBatchChanged) {
+		return false
+	}
+	return r.attempt < r.policy.MaxRetries && r.ctx.Err() == nil
+}
+
+func (r *batchReader) Read(p []byte) (int, error) {
+	if r.offset > r.maxSize {
+		return 0, fmt.Errorf("%w %d", ErrBatchTooLarge, r.maxSize)
+	}
+	if r.batch == nil {
+		return 0, ErrBatchNotResumable
+	}
+	// One more byte than the remaining ones is read to check if the batch is larger than expected
+	if remaining := r.maxSize - r.offset; int64(len(p)) > remaining+1 {
+		p = p[:remaining+1]
+	}
+
+	r.deadline.resume()
+	n, err := r.batch.Read(p)
+	r.deadline.pause()
+	r.offset += int64(n)
+	if r.offset > r.maxSize {
+		return n - 1, fmt.Errorf("%w %d", ErrBatchTooLarge, r.maxSize)
+	}
+
+	_, resumable := r.fetcher.(ResumableBatchFetcher)
+	if err != nil && !errors.Is(err, io.EOF) && resumable && r.retryable(err) {
+		r.logger.Warnf("Batch download interrupted after %d bytes - (attempt %d): %v", r.offset, r.attempt, err)
+		r.closeAttempt()
+		err = r.open()
+	}
+	return n, err
+}
+
+func (r *batchReader) Close() error {
+	return r.closeAttempt()
+}
+
+func (r *batchReader) closeAttempt() error {
+	if r.batch == nil {
+		return nil
+	}
+	defer r.deadline.stop()
+	err := r.batch.Close()
+	r.batch = nil
+	return err
+}
+
+// errBatchAttemptTimeout cancels an attempt to download a batch that ran out of time
+var errBatchAttemptTimeout = fmt.Errorf("batch download attempt timed out: %w", context.DeadlineExceeded)
+
+// attemptDeadline cancels the context of an attempt once it ran for its timeout.
+// It can be paused, so the time the attempt is not in use doesn't count.
+type attemptDeadline struct {
+	remaining time.Duration
+	resumedAt time.Time
+	timer     *time.Timer
+	cancel    context.CancelCauseFunc
+}
+
+// newAttemptDeadline returns the context of an attempt with timeout, running
+func newAttemptDeadline(ctx context.Context, timeout time.Duration) (context.Context, *attemptDeadline) {
+	attemptCtx, cancel := context.WithCancelCause(ctx)
+	deadline := &attemptDeadline{remaining: timeout, resumedAt: time.Now(), cancel: cancel}
+	deadline.timer = time.AfterFunc(timeout, func() { cancel(errBatchAttemptTimeout) })
+	return attemptCtx, deadline
+}
+
+// pause stops counting time until resume is called
+func (d *attemptDeadline) pause() {
+	if d.timer.Stop() {
+		d.remaining -= time.Since(d.resumedAt)
+	}
+}
+
+// resume counts time again, cancelling the attempt once the remaining time runs out
+func (d *attemptDeadline) resume() {
+	d.resumedAt = time.Now()
+	d.timer.Reset(max(d.remaining, 0))
+}
+
+// stop cancels the attempt
+func (d *attemptDeadline) stop() {
+	d.timer.Stop()
+	d.cancel(context.Canceled)
+}
+
+// HttpBatchFetcher downloads batches with GET requests.
+// If it stores partial downloads, interrupted downloads are resumed with range requests.
+type HttpBatchFetcher struct {
+	client *http.Client
+	// prepare, if not nil, is called on every request before sending it
+	prepare func(req *http.Request) error
+	// partials, if not nil, stores downloads in progress to resume them
+	partials *PartialDownloads
+}
+
+// NewHttpBatchFetcher returns a fetcher resuming interrupted downloads from partials, or starting them over if it's nil
+func NewHttpBatchFetcher(client *http.Client, partials *PartialDownloads) *HttpBatchFetcher {
+	return &HttpBatchFetcher{client: client, partials: partials}
+}
+
+func (f *HttpBatchFetcher) Open(ctx context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error) {
+	return f.get(ctx, pointer.String(), 0, maxSize)
+}
+
+func (f *HttpBatchFetcher) OpenAt(ctx context.Context, pointer *url.URL, offset int64, maxSize int64) (io.ReadCloser, error) {
+	return f.get(ctx, pointer.String(), offset, maxSize)
+}
+
+// get returns the batch at batchURL from offset on. The data already stored is served from disk,
+// and only the rest is requested, checking with the ETag and length of the batch that it didn't change.
+func (f *HttpBatchFetcher) get(ctx context.Context, batchURL string, offset int64, maxSize int64) (io.ReadCloser, error) {
+	var partial *partialDownload
+	if f.partials != nil {
+		var err error
+		if partial, err = f.partials.acquire(batchURL); err != nil {
+			return nil, err
+		}
+	}
+	if partial == nil && offset > 0 {
+		return nil, ErrBatchNotResumable
+	}
+
+	resp, err := f.request(ctx, batchURL, partial)
+	if err != nil {
+		if partial != nil {
+			partial.release(true)
+		}
+		return nil, err
+	}
+	if partial == nil {
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("error getting batch from data service: %s", resp.Status)
+		}
+		if resp.ContentLength > maxSize {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%w: proof size %d exceeds max batch size %d", ErrBatchTooLarge, resp.ContentLength, maxSize)
+		}
+		return resp.Body, nil
+	}
+
+	if err := resumeFromResponse(resp, partial, offset, maxSize); err != nil {
+		resp.Body.Close()
+		changed := errors.Is(err, ErrBatchChanged)
+		partial.release(!changed && !errors.Is(err, ErrBatchTooLarge))
+		// Nothing was read yet, so a batch that changed since it was stored is downloaded again from the start
+		if changed && offset == 0 {
+			return f.get(ctx, batchURL, 0, maxSize)
+		}
+		return nil, err
+	}
+	return newPartialReader(partial, resp.Body, offset), nil
+}
+
+// request sends the GET request of a batch, only for the part not stored yet if partial can be resumed
+func (f *HttpBatchFetcher) request(ctx context.Context, batchURL string, partial *partialDownload) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, batchURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if partial != nil {
+		// Offsets refer to the batch as stored, not to a compressed representation of it
+		req.Header.Set("Accept-Encoding", "identity")
+		if partial.resumable() {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", partial.size))
+			if partial.meta.ETag != "" {
+				req.Header.Set("If-Range", partial.meta.ETag)
+			}
+		}
+	}
+	// Headers are set before preparing the request so they are signed
+	if f.prepare != nil {
+		if err := f.prepare(req); err != nil {
+			return nil, err
+		}
+	}
+	return f.client.Do(req)
+}
+
+// resumeFromResponse checks the response continues the batch stored in partial, restarting it if the whole batch is served
+func resumeFromResponse(resp *http.Response, partial *partialDownload, offset int64, maxSize int64) error {
+	etag := resp.Header.Get("ETag")
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, length, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		}
+		if start != partial.size || length != partial.meta.Length || (partial.meta.ETag != "" && etag != partial.meta.ETag) {
+			return fmt.Errorf("%w: expected bytes %d-/%d of %s, got %s of %s",
+				ErrBatchChanged, partial.size, partial.meta.Length, partial.meta.ETag, resp.Header.Get("Content-Range"), etag)
+		}
+
+	case http.StatusOK:
+		length := resp.ContentLength
+		if length > maxSize {
+			return fmt.Errorf("%w: proof size %d exceeds max batch size %d", ErrBatchTooLarge, length, maxSize)
+		}
+		// Without range support, the batch is downloaded again, and only the bytes after offset are returned.
+		// They only belong to the batch already read if it can be told apart from a different one.
+		if offset > 0 {
+			if partial.meta.ETag == "" || partial.meta.Length < 0 {
+				return fmt.Errorf("%w: the server doesn't support range requests and the batch has no ETag", ErrBatchNotResumable)
+			}
+			if etag != partial.meta.ETag || length != partial.meta.Length {
+				return fmt.Errorf("%w: expected %s of %d bytes, got %s of %d bytes", ErrBatchChanged, partial.meta.ETag, partial.meta.Length, etag, length)
+			}
+		}
+		if err := partial.restart(etag, length); err != nil {
+			return err
+		}
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		return fmt.Errorf("%w: the stored part is not in the batch served: %s", ErrBatchChanged, resp.Status)
+
+	default:
+		return fmt.Errorf("error getting batch from data service: %s", resp.Status)
+	}
+
+	if partial.meta.Length > maxSize {
+		return fmt.Errorf("%w: proof size %d exceeds max batch size %d", ErrBatchTooLarge, partial.meta.Length, maxSize)
+	}
+	return nil
+}
+
+// parseContentRange returns the first byte and total length of a `bytes <first>-<last>/<length>` Content-Range
+func parseContentRange(contentRange string) (int64, int64, error) {
+	var first, last, length int64
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &first, &last, &length); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q: %w", contentRange, err)
+	}
+	return first, length, nil
+}
+
+// FileBatchFetcher reads batches from `file://` URLs, for devnets and tests
+type FileBatchFetcher struct{}
+
+func (f *FileBatchFetcher) Open(ctx context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error) {
+	return os.Open(pointer.Path)
+}
+
+// ContentGatewayBatchFetcher downloads content addressed batches, `ipfs://<cid>[/<path>]`,
+// from an HTTP gateway at `<gateway>/ipfs/<cid>[/<path>]`
+type ContentGatewayBatchFetcher struct {
+	gatewayURL string
+	http       *HttpBatchFetcher
+}
+
+func NewContentGatewayBatchFetcher(gatewayURL string, client *http.Client, partials *PartialDownloads) *ContentGatewayBatchFetcher {
+	return &ContentGatewayBatchFetcher{
+		gatewayURL: strings.TrimSuffix(gatewayURL, "/"),
+		http:       NewHttpBatchFetcher(client, partials),
+	}
+}
+
+func (f *ContentGatewayBatchFetcher) Open(ctx context.Context, pointer *url.URL, maxSize int64) (io.ReadCloser, error) {
+	return f.OpenAt(ctx, pointer, 0, maxSize)
+}
+
+func (f *ContentGatewayBatchFetcher) OpenAt(ctx context.Context, pointer *url.URL, offset int64, maxSize int64) (io.ReadCloser, error) {
+	if pointer.Host == "" {
+		return nil, fmt.Errorf("%w: missing content identifier in %s", ErrUnsupportedBatchDataPointer, pointer)
+	}
+	return f.http.get(ctx, f.gatewayURL+"/"+pointer.Scheme+"/"+pointer.Host+pointer.EscapedPath(), offset, maxSize)
+}
+
+// Defaults of the `batch_fetchers` operator config fields
+const (
+	DefaultContentGatewayURL = "https://ipfs.io"
+	DefaultS3Region          = "us-east-1"
+	DefaultFileFetchTimeout  = 10 * time.Second
+)
+
+// DefaultPartialDownloadsDirName is the directory next to the task journal where partial downloads are stored
+// if `batch_fetchers.partial_downloads_dir` is not set
+const DefaultPartialDownloadsDirName = "partial_batches"
+
+// defaultPartialDownloadsDir stores partial downloads in the operator data directory, next to the task journal.
+// Without one, a new private temporary directory is used, so downloads are only resumed until the operator restarts.
+func defaultPartialDownloadsDir(configuration config.OperatorConfig) (string, error) {
+	dataFile := configuration.Operator.TaskJournalFilePath
+	if dataFile == "" {
+		dataFile = configuration.Operator.LastProcessedBatchFilePath
+	}
+	if dataFile != "" {
+		return filepath.Join(filepath.Dir(dataFile), DefaultPartialDownloadsDirName), nil
+	}
+	return os.MkdirTemp("", "aligned_partial_batches")
+}
+
+// newBatchFetchersFromConfig registers the http(s), ipfs, s3 and, if enabled, file fetchers
+// with the policies of the `batch_fetchers` operator config field
+func newBatchFetchersFromConfig(configuration config.OperatorConfig, logger logging.Logger) (*BatchFetchers, error) {
+	fetchersConfig := configuration.Operator.BatchFetchers
+	fetchers := NewBatchFetchers(logger)
+
+	httpPolicy, err := batchFetcherPolicy(fetchersConfig.Http, BatchDownloadTimeout, BatchDownloadMaxRetries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid `batch_fetchers.http` config: %w", err)
+	}
+	partialsDir := fetchersConfig.PartialDownloadsDir
+	if partialsDir == "" {
+		if partialsDir, err = defaultPartialDownloadsDir(configuration); err != nil {
+			return nil, fmt.Errorf("could not create the partial downloads directory: %w", err)
+		}
+	}
+	partials, err := NewPartialDownloads(partialsDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid `batch_fetchers.partial_downloads_dir` config: %w", err)
+	}
+
+	httpFetcher := NewHttpBatchFetcher(&http.Client{}, partials)
+	fetchers.Register("http", httpFetcher, httpPolicy)
+	fetchers.Register("https", httpFetcher, httpPolicy)
+
+	// A batch pointing to a local file would make the operator read it, so the file fetcher is opt-in
+	if fetchersConfig.File.Enabled {
+		filePolicy, err := batchFetcherPolicy(fetchersConfig.File.BatchFetcherConfig, DefaultFileFetchTimeout, 1)
+		if err != nil {
+			return nil, fmt.Errorf("invalid `batch_fetchers.file` config: %w", err)
+		}
+		logger.Warn("The file batch fetcher is enabled: batches can be read from the local filesystem. Only use it for local testing")
+		fetchers.Register("file", &FileBatchFetcher{}, filePolicy)
+	}
+
+	gatewayPolicy, err := batchFetcherPolicy(fetchersConfig.ContentGateway.BatchFetcherConfig, BatchDownloadTimeout, BatchDownloadMaxRetries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid `batch_fetchers.content_gateway` config: %w", err)
+	}
+	gatewayURL := fetchersConfig.ContentGateway.Url
+	if gatewayURL == "" {
+		gatewayURL = DefaultContentGatewayURL
+	}
+	fetchers.Register("ipfs", NewContentGatewayBatchFetcher(gatewayURL, &http.Client{}, partials), gatewayPolicy)
+
+	// The S3 fetcher is only available once an endpoint is configured
+	if s3Config := fetchersConfig.S3; s3Config.Endpoint != "" {
+		s3Policy, err := batchFetcherPolicy(s3Config.BatchFetcherConfig, BatchDownloadTimeout, BatchDownloadMaxRetries)
+		if err != nil {
+			return nil, fmt.Errorf("invalid `batch_fetchers.s3` config: %w", err)
+		}
+		region := s3Config.Region
+		if region == "" {
+			region = DefaultS3Region
+		}
+		fetchers.Register("s3", NewS3BatchFetcher(s3Config.Endpoint, region, s3Config.AccessKeyId, s3Config.SecretAccessKey, &http.Client{}, partials), s3Policy)
+	}
+
+	return fetchers, nil
+}
+
+func batchFetcherPolicy(fetcherConfig config.BatchFetcherConfig, defaultTimeout time.Duration, defaultMaxRetries int) (BatchFetcherPolicy, error) {
+	if fetcherConfig.Timeout < 0 || fetcherConfig.MaxRetries < 0 || fetcherConfig.RetryDelay < 0 {
+		return BatchFetcherPolicy{}, fmt.Errorf("negative timeout, max retries or retry delay")
+	}
+
+	policy := BatchFetcherPolicy{
+		Timeout:    fetcherConfig.Timeout,
+		MaxRetries: fetcherConfig.MaxRetries,
+		RetryDelay: fetcherConfig.RetryDelay,
+	}
+	if policy.Timeout == 0 {
+		policy.Timeout = defaultTimeout
+	}
+	if policy.MaxRetries == 0 {
+		policy.MaxRetries = defaultMaxRetries
+	}
+	if policy.RetryDelay == 0 {
+		policy.RetryDelay = BatchDownloadRetryDelay
+	}
+	return policy, nil
+}
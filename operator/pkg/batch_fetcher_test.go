@@ -0,0 +1,198 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+var testBatchFetcherPolicy = BatchFetcherPolicy{Timeout: time.Second, MaxRetries: 3, RetryDelay: time.Millisecond}
+
+func newTestBatchFetchers(t *testing.T, scheme string, fetcher BatchFetcher, policy BatchFetcherPolicy) *BatchFetchers {
+	fetchers := NewBatchFetchers(newTestLogger(t))
+	fetchers.Register(scheme, fetcher, policy)
+	return fetchers
+}
+
+// readBatch opens and reads a whole batch
+func readBatch(fetchers *BatchFetchers, batchDataPointer string, maxSize int64) ([]byte, error) {
+	batch, err := fetchers.Open(context.Background(), batchDataPointer, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	defer batch.Close()
+	return io.ReadAll(batch)
+}
+
+func TestHttpBatchFetcherRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("batch"))
+	}))
+	defer server.Close()
+
+	fetchers := newTestBatchFetchers(t, "http", NewHttpBatchFetcher(server.Client(), nil), testBatchFetcherPolicy)
+	batch, err := readBatch(fetchers, server.URL+"/batch", 1024)
+	if err != nil || string(batch) != "batch" {
+		t.Fatalf("expected the batch after retrying, got %q, %v", batch, err)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 requests, got %d", requests.Load())
+	}
+
+	requests.Store(-10)
+	if _, err := readBatch(fetchers, server.URL+"/batch", 1024); err == nil {
+		t.Error("expected an error once the retries are exhausted")
+	}
+}
+
+func TestHttpBatchFetcherTimesOutEachAttempt(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("batch"))
+	}))
+	defer server.Close()
+
+	policy := BatchFetcherPolicy{Timeout: 50 * time.Millisecond, MaxRetries: 2, RetryDelay: time.Millisecond}
+	fetchers := newTestBatchFetchers(t, "http", NewHttpBatchFetcher(server.Client(), nil), policy)
+	if batch, err := readBatch(fetchers, server.URL, 1024); err != nil || string(batch) != "batch" {
+		t.Errorf("expected the batch after the first attempt timed out, got %q, %v", batch, err)
+	}
+}
+
+func TestHttpBatchFetcherRejectsLargeBatches(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		// Flushing first sends the body chunked, without a Content-Length
+		w.(http.Flusher).Flush()
+		w.Write([]byte(strings.Repeat("a", 2048)))
+	}))
+	defer server.Close()
+
+	fetchers := newTestBatchFetchers(t, "http", NewHttpBatchFetcher(server.Client(), nil), testBatchFetcherPolicy)
+	_, err := readBatch(fetchers, server.URL, 1024)
+	if !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("expected ErrBatchTooLarge, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("batches too large should not be retried, got %d requests", requests.Load())
+	}
+}
+
+func TestFileBatchFetcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch")
+	os.WriteFile(path, []byte("batch"), 0o600)
+
+	fetchers := newTestBatchFetchers(t, "file", &FileBatchFetcher{}, testBatchFetcherPolicy)
+	if batch, err := readBatch(fetchers, "file://"+path, 1024); err != nil || string(batch) != "batch" {
+		t.Errorf("expected the batch, got %q, %v", batch, err)
+	}
+	if _, err := readBatch(fetchers, "s3://bucket/batch", 1024); !errors.Is(err, ErrUnsupportedBatchDataPointer) {
+		t.Errorf("expected ErrUnsupportedBatchDataPointer for an unregistered scheme, got %v", err)
+	}
+}
+
+func TestFileBatchFetcherIsOptIn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch")
+	os.WriteFile(path, []byte("batch"), 0o600)
+
+	var configuration config.OperatorConfig
+	configuration.Operator.BatchFetchers.PartialDownloadsDir = filepath.Join(t.TempDir(), "partials")
+	fetchers, err := newBatchFetchersFromConfig(configuration, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBatch(fetchers, "file://"+path, 1024); !errors.Is(err, ErrUnsupportedBatchDataPointer) {
+		t.Errorf("expected file:// to be unsupported by default, got %v", err)
+	}
+
+	configuration.Operator.BatchFetchers.File.Enabled = true
+	fetchers, err = newBatchFetchersFromConfig(configuration, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch, err := readBatch(fetchers, "file://"+path, 1024); err != nil || string(batch) != "batch" {
+		t.Errorf("expected the batch once enabled, got %q, %v", batch, err)
+	}
+}
+
+func TestPartialDownloadsDefaultToTheDataDir(t *testing.T) {
+	dataDir := t.TempDir()
+	var configuration config.OperatorConfig
+	configuration.Operator.TaskJournalFilePath = filepath.Join(dataDir, DefaultTaskJournalFileName)
+	if _, err := newBatchFetchersFromConfig(configuration, newTestLogger(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(filepath.Join(dataDir, DefaultPartialDownloadsDirName))
+	if err != nil {
+		t.Fatalf("expected the partial downloads next to the task journal: %v", err)
+	}
+	if info.Mode().Perm() != 0o700 {
+		t.Errorf("expected the partial downloads directory to be private, got %#o", info.Mode().Perm())
+	}
+}
+
+func TestContentGatewayBatchFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ipfs/bafybeigdyrzt/batch.cbor" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("batch"))
+	}))
+	defer server.Close()
+
+	fetchers := newTestBatchFetchers(t, "ipfs", NewContentGatewayBatchFetcher(server.URL+"/", server.Client(), nil), testBatchFetcherPolicy)
+	if batch, err := readBatch(fetchers, "ipfs://bafybeigdyrzt/batch.cbor", 1024); err != nil || string(batch) != "batch" {
+		t.Errorf("expected the batch, got %q, %v", batch, err)
+	}
+}
+
+func TestS3BatchFetcher(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		switch {
+		case r.URL.Path != "/batches/2024/batch.cbor":
+			w.WriteHeader(http.StatusNotFound)
+		case !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=minio/"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.Write([]byte("batch"))
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewS3BatchFetcher(server.URL, DefaultS3Region, "minio", "minio123", server.Client(), nil)
+	fetchers := newTestBatchFetchers(t, "s3", fetcher, testBatchFetcherPolicy)
+	if batch, err := readBatch(fetchers, "s3://batches/2024/batch.cbor", 1024); err != nil || string(batch) != "batch" {
+		t.Errorf("expected the batch, got %q, %v", batch, err)
+	}
+	if _, err := readBatch(fetchers, "s3://batches", 1024); !errors.Is(err, ErrUnsupportedBatchDataPointer) {
+		t.Errorf("expected ErrUnsupportedBatchDataPointer without a key, got %v", err)
+	}
+
+	anonymous := newTestBatchFetchers(t, "s3", NewS3BatchFetcher(server.URL, DefaultS3Region, "", "", server.Client(), nil), testBatchFetcherPolicy)
+	if _, err := readBatch(anonymous, "s3://batches/2024/batch.cbor", 1024); err == nil {
+		t.Error("expected anonymous requests to a private bucket to fail")
+	}
+}
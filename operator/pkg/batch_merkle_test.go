@@ -0,0 +1,138 @@
+package operator
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/operator/merkle_tree"
+)
+
+// sdkVerificationData mirrors the serde serialization of VerificationData in batcher/aligned-sdk:
+// byte vectors are arrays of integers, missing options are null and addresses are hex strings
+type sdkVerificationData struct {
+	ProvingSystem      string `cbor:"proving_system"`
+	Proof              []uint `cbor:"proof"`
+	PubInput           []uint `cbor:"pub_input"`
+	VerificationKey    []uint `cbor:"verification_key"`
+	VmProgramCode      []uint `cbor:"vm_program_code"`
+	ProofGeneratorAddr string `cbor:"proof_generator_addr"`
+}
+
+func sdkBytes(data []byte) []uint {
+	if data == nil {
+		return nil
+	}
+	values := make([]uint, len(data))
+	for i, b := range data {
+		values[i] = uint(b)
+	}
+	return values
+}
+
+// encodeSdkBatch serializes the batch as the batcher does, as a CBOR array of its entries
+func encodeSdkBatch(t *testing.T, entries []VerificationData) []byte {
+	t.Helper()
+	sdkEntries := make([]sdkVerificationData, len(entries))
+	for i, entry := range entries {
+		provingSystem, err := common.ProvingSystemIdToString(entry.ProvingSystemId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sdkEntries[i] = sdkVerificationData{
+			ProvingSystem:      provingSystem,
+			Proof:              sdkBytes(entry.Proof),
+			PubInput:           sdkBytes(entry.PubInput),
+			VerificationKey:    sdkBytes(entry.VerificationKey),
+			VmProgramCode:      sdkBytes(entry.VmProgramCode),
+			ProofGeneratorAddr: entry.ProofGeneratorAddr,
+		}
+	}
+	batch, err := cbor.Marshal(sdkEntries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return batch
+}
+
+func readTestFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../../scripts/test_files/" + path)
+	if err != nil {
+		t.Fatalf("could not read test file: %s", err)
+	}
+	return data
+}
+
+// TestBatchMerkleRootMatchesSdk checks the merkle root computed while streaming a batch against the one
+// of batcher/aligned-sdk, through the merkle tree library built from it
+func TestBatchMerkleRootMatchesSdk(t *testing.T) {
+	// The batch serialized by the batcher itself, which also tells whether the library really checks the root
+	batch, merkleRoot := readTestBatch(t)
+	if verified, err := merkle_tree.VerifyMerkleTreeBatch(batch, merkleRoot); err != nil || !verified {
+		t.Fatalf("expected the batcher test batch to verify with the sdk: %v", err)
+	}
+	merkleRoot[0] ^= 1
+	if verified, _ := merkle_tree.VerifyMerkleTreeBatch(batch, merkleRoot); verified {
+		t.Skip("the merkle tree library accepts any merkle root, build it with `make build_merkle_tree_linux`")
+	}
+
+	sp1 := VerificationData{
+		ProvingSystemId:    common.SP1,
+		Proof:              readTestFile(t, "sp1/sp1_fibonacci.proof"),
+		VmProgramCode:      readTestFile(t, "sp1/sp1_fibonacci.elf"),
+		ProofGeneratorAddr: "0x66f9664f97F2b50F62D13eA064982f936dE76657",
+	}
+	risc0 := VerificationData{
+		ProvingSystemId:    common.Risc0,
+		Proof:              readTestFile(t, "risc_zero/fibonacci_proof_generator/risc_zero_fibonacci.proof"),
+		PubInput:           readTestFile(t, "risc_zero/fibonacci_proof_generator/risc_zero_fibonacci.pub"),
+		VmProgramCode:      readTestFile(t, "risc_zero/fibonacci_proof_generator/fibonacci_id.bin"),
+		ProofGeneratorAddr: "0x66f9664f97F2b50F62D13eA064982f936dE76657",
+	}
+	risc0NoPubInput := VerificationData{
+		ProvingSystemId:    common.Risc0,
+		Proof:              readTestFile(t, "risc_zero/no_public_inputs/risc_zero_no_pub_input.proof"),
+		VmProgramCode:      readTestFile(t, "risc_zero/no_public_inputs/no_pub_input_id.bin"),
+		ProofGeneratorAddr: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+	}
+	plonk := VerificationData{
+		ProvingSystemId:    common.GnarkPlonkBn254,
+		Proof:              readTestFile(t, "gnark_plonk_bn254_script/plonk.proof"),
+		PubInput:           readTestFile(t, "gnark_plonk_bn254_script/plonk_pub_input.pub"),
+		VerificationKey:    readTestFile(t, "gnark_plonk_bn254_script/plonk.vk"),
+		ProofGeneratorAddr: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+	}
+
+	batches := map[string][]VerificationData{
+		"sp1":                 {sp1},
+		"risc0":               {risc0, risc0},
+		"risc0 no pub input":  {risc0NoPubInput},
+		"mixed":               {sp1, risc0, risc0NoPubInput, plonk},
+		"odd count of 3":      {plonk, risc0NoPubInput, sp1},
+		"odd count of 5":      {plonk, risc0, plonk, risc0NoPubInput, plonk},
+		"single gnark entry":  {plonk},
+		"two identical gnark": {plonk, plonk},
+	}
+	for name, entries := range batches {
+		batch := encodeSdkBatch(t, entries)
+		decoded, merkleRoot, err := decodeTestBatch(bytes.NewReader(batch))
+		if err != nil {
+			t.Fatalf("%s: could not decode batch: %s", name, err)
+		}
+		if len(decoded) != len(entries) {
+			t.Fatalf("%s: expected %d entries, got %d", name, len(entries), len(decoded))
+		}
+
+		verified, err := merkle_tree.VerifyMerkleTreeBatch(batch, merkleRoot)
+		if err != nil || !verified {
+			t.Errorf("%s: merkle root %x doesn't match the one of the sdk: %v", name, merkleRoot, err)
+		}
+		merkleRoot[0] ^= 1
+		if verified, _ := merkle_tree.VerifyMerkleTreeBatch(batch, merkleRoot); verified {
+			t.Errorf("%s: expected a different merkle root not to match", name)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// BatchMirrorStrategy is how the sources of a batch are tried
+type BatchMirrorStrategy string
+
+const (
+	// BatchMirrorsFailover tries the batch data pointer first and then each mirror in order
+	BatchMirrorsFailover BatchMirrorStrategy = "failover"
+	// BatchMirrorsRace downloads from the batch data pointer and every mirror at the same time
+	BatchMirrorsRace BatchMirrorStrategy = "race"
+)
+
+// BatchMirrorPrimary is the source name of the batch data pointer itself
+const BatchMirrorPrimary = "primary"
+
+// Results of a download passed to the OnDownload callback
+const (
+	BatchDownloadSuccess        = "success"
+	BatchDownloadError          = "error"
+	BatchDownloadMerkleMismatch = "merkle_mismatch"
+)
+
+// ErrBatchMerkleRootMismatch is returned when a downloaded batch doesn't match the merkle root of its event
+var ErrBatchMerkleRootMismatch = errors.New("batch merkle root mismatch")
+
+type batchSource struct {
+	name    string
+	pointer string
+}
+
+type openedBatch struct {
+	index     int
+	batch     io.ReadCloser
+	startTime time.Time
+	err       error
+}
+
+// BatchMirrors downloads batches from their batch data pointer or from mirrors of it.
+// A mirror is a base URL replacing the scheme and host of the pointer, keeping its path:
+// with the mirror `https://mirror.example.com/aligned`, `https://storage.alignedlayer.com/batch.json`
+// is also downloaded from `https://mirror.example.com/aligned/batch.json`, and `ipfs://<cid>/batch.json`
+// from `https://mirror.example.com/aligned/ipfs/<cid>/batch.json`.
+// The first download matching the expected merkle root wins, so mirrors don't need to be trusted.
+type BatchMirrors struct {
+	fetchers   *BatchFetchers
+	mirrors    []*url.URL
+	strategy   BatchMirrorStrategy
+	onDownload func(source string, result string, latency time.Duration)
+	logger     logging.Logger
+}
+
+func NewBatchMirrors(fetchers *BatchFetchers, mirrorURLs []string, strategy BatchMirrorStrategy, logger logging.Logger) (*BatchMirrors, error) {
+	if strategy == "" {
+		strategy = BatchMirrorsFailover
+	}
+	if strategy != BatchMirrorsFailover && strategy != BatchMirrorsRace {
+		return nil, fmt.Errorf("invalid batch mirrors strategy: %s", strategy)
+	}
+
+	mirrors := make([]*url.URL, len(mirrorURLs))
+	for i, mirrorURL := range mirrorURLs {
+		mirror, err := url.Parse(strings.TrimSuffix(mirrorURL, "/"))
+		if err != nil || mirror.Scheme == "" || mirror.Host == "" {
+			return nil, fmt.Errorf("invalid batch mirror URL: %s", mirrorURL)
+		}
+		mirrors[i] = mirror
+	}
+
+	return &BatchMirrors{
+		fetchers: fetchers,
+		mirrors:  mirrors,
+		strategy: strategy,
+		logger:   logger,
+	}, nil
+}
+
+// OnDownload sets a callback invoked after every download of a source, with the host of the mirror
+// or BatchMirrorPrimary as source. The latency covers opening and consuming the batch.
+// Downloads cancelled because another source won are not reported.
+func (m *BatchMirrors) OnDownload(callback func(source string, result string, latency time.Duration)) {
+	m.onDownload = callback
+}
+
+// Stream opens the batch at batchDataPointer or one of its mirrors, and passes it to consume as it's downloaded.
+// consume must fail with ErrBatchMerkleRootMismatch if the batch doesn't match the merkle root of its event.
+// If opening or consuming a source fails, the next one is tried, until one of them is consumed or ctx is done.
+// In race mode, every source is opened at the same time and they are consumed in the order they open.
+// The sources that are not being consumed keep their download open until one of the sources is consumed,
+// so a fast source serving a wrong or stale batch doesn't make the others start over.
+func (m *BatchMirrors) Stream(ctx context.Context, batchDataPointer string, maxSize int64, consume func(batch io.Reader) error) error {
+	sources := m.sources(batchDataPointer)
+	if m.strategy == BatchMirrorsRace && len(sources) > 1 {
+		return m.race(ctx, sources, maxSize, consume)
+	}
+
+	var errs []error
+	for index, source := range sources {
+		opened := m.open(ctx, index, source, maxSize)
+		err := opened.err
+		if err == nil {
+			err = m.consume(ctx, source, opened, consume)
+		}
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// race opens every source at the same time and consumes them in the order they open,
+// until one of them matches. The rest are cancelled once one is consumed.
+func (m *BatchMirrors) race(ctx context.Context, sources []batchSource, maxSize int64, consume func(batch io.Reader) error) error {
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	openedBatches := make(chan openedBatch, len(sources))
+	for index, source := range sources {
+		go func() {
+			openedBatches <- m.open(raceCtx, index, source, maxSize)
+		}()
+	}
+
+	var errs []error
+	for remaining := len(sources); remaining > 0; remaining-- {
+		opened := <-openedBatches
+		if opened.err != nil {
+			errs = append(errs, opened.err)
+			continue
+		}
+		if ctx.Err() != nil {
+			opened.batch.Close()
+			continue
+		}
+
+		m.logger.Infof("Downloading batch from %s", sources[opened.index].name)
+		err := m.consume(raceCtx, sources[opened.index], opened, consume)
+		if err == nil {
+			// The sources still downloading are cancelled, and closed once they open
+			go func() {
+				for remaining--; remaining > 0; remaining-- {
+					if opened := <-openedBatches; opened.err == nil {
+						opened.batch.Close()
+					}
+				}
+			}()
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// open opens a single source, reporting it if it fails
+func (m *BatchMirrors) open(ctx context.Context, index int, source batchSource, maxSize int64) openedBatch {
+	opened := openedBatch{index: index, startTime: time.Now()}
+	opened.batch, opened.err = m.fetchers.Open(ctx, source.pointer, maxSize)
+	if opened.err != nil {
+		opened.err = fmt.Errorf("%s: %w", source.name, opened.err)
+		m.logger.Warnf("Could not download batch from %s: %v", source.pointer, opened.err)
+		m.report(ctx, source, opened.startTime, BatchDownloadError)
+	}
+	return opened
+}
+
+// consume passes an opened source to consume, reporting the result
+func (m *BatchMirrors) consume(ctx context.Context, source batchSource, opened openedBatch, consume func(batch io.Reader) error) error {
+	defer opened.batch.Close()
+
+	err := consume(opened.batch)
+	switch {
+	case err == nil:
+		m.report(ctx, source, opened.startTime, BatchDownloadSuccess)
+		return nil
+	case errors.Is(err, ErrBatchMerkleRootMismatch):
+		m.report(ctx, source, opened.startTime, BatchDownloadMerkleMismatch)
+	default:
+		m.report(ctx, source, opened.startTime, BatchDownloadError)
+	}
+
+	err = fmt.Errorf("%s: %w", source.name, err)
+	m.logger.Warnf("Could not download batch from %s: %v", source.pointer, err)
+	return err
+}
+
+func (m *BatchMirrors) report(ctx context.Context, source batchSource, startTime time.Time, result string) {
+	if m.onDownload != nil && ctx.Err() == nil {
+		m.onDownload(source.name, result, time.Since(startTime))
+	}
+}
+
+// sources returns the batch data pointer followed by its mirrors.
+// The host of http(s) pointers is replaced by the mirror, while the host of other pointers
+// identifies the content, like the CID of `ipfs://<cid>/<path>`, so it's kept as `/<scheme>/<host>/<path>`.
+func (m *BatchMirrors) sources(batchDataPointer string) []batchSource {
+	sources := []batchSource{{name: BatchMirrorPrimary, pointer: batchDataPointer}}
+
+	pointer, err := url.Parse(batchDataPointer)
+	if err != nil {
+		return sources
+	}
+	path := pointer.Path
+	if pointer.Scheme != "http" && pointer.Scheme != "https" && pointer.Host != "" {
+		path = "/" + pointer.Scheme + "/" + pointer.Host + path
+	}
+	for _, mirror := range m.mirrors {
+		mirrored := *mirror
+		mirrored.Path = mirror.Path + path
+		mirrored.RawPath = ""
+		mirrored.RawQuery = pointer.RawQuery
+		sources = append(sources, batchSource{name: mirror.Host, pointer: mirrored.String()})
+	}
+	return sources
+}
+
+// newBatchMirrorsFromConfig builds the batch fetchers and mirrors from the
+// `batch_fetchers` and `batch_mirrors` operator config fields
+func newBatchMirrorsFromConfig(configuration config.OperatorConfig, logger logging.Logger) (*BatchMirrors, error) {
+	fetchers, err := newBatchFetchersFromConfig(configuration, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	mirrorsConfig := configuration.Operator.BatchMirrors
+	mirrors, err := NewBatchMirrors(fetchers, mirrorsConfig.Urls, BatchMirrorStrategy(mirrorsConfig.Strategy), logger)
+	if err != nil {
+		return nil, fmt.Errorf("invalid `batch_mirrors` config: %w", err)
+	}
+	return mirrors, nil
+}
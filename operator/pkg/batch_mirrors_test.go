@@ -0,0 +1,223 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mirrorDownload struct {
+	mirror string
+	result string
+}
+
+func newTestBatchMirrors(t *testing.T, mirrorURLs []string, strategy BatchMirrorStrategy) (*BatchMirrors, func() []mirrorDownload) {
+	fetchers := newTestBatchFetchers(t, "http", NewHttpBatchFetcher(&http.Client{}, nil), testBatchFetcherPolicy)
+	mirrors, err := NewBatchMirrors(fetchers, mirrorURLs, strategy, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	var downloads []mirrorDownload
+	mirrors.OnDownload(func(mirror string, result string, latency time.Duration) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		downloads = append(downloads, mirrorDownload{mirror, result})
+	})
+	return mirrors, func() []mirrorDownload {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]mirrorDownload(nil), downloads...)
+	}
+}
+
+// checkMerkleRoot consumes a batch, failing with ErrBatchMerkleRootMismatch if it's not the expected one
+func checkMerkleRoot(expectedMerkleRoot [32]byte) func(batch io.Reader) error {
+	return func(batch io.Reader) error {
+		_, merkleRoot, err := decodeTestBatch(batch)
+		if err != nil {
+			return err
+		}
+		if merkleRoot != expectedMerkleRoot {
+			return ErrBatchMerkleRootMismatch
+		}
+		return nil
+	}
+}
+
+// serveBatch serves batchBytes after delay, or fails with status if it's not 200
+func serveBatch(batchBytes []byte, status int, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write(batchBytes)
+	}))
+}
+
+// tamperedTestBatch returns a valid batch whose merkle root is not the one of the test batch
+func tamperedTestBatch(t *testing.T, batchBytes []byte) []byte {
+	entries, _, err := decodeTestBatch(bytes.NewReader(batchBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered, err := json.Marshal(entries[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tampered
+}
+
+func hostOf(t *testing.T, serverURL string) string {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Host
+}
+
+func expectDownloads(t *testing.T, got []mirrorDownload, expected ...mirrorDownload) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("expected downloads %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected downloads %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestBatchMirrorsRewritePointers(t *testing.T) {
+	mirrors, _ := newTestBatchMirrors(t, []string{"https://mirror.example.com/aligned/", "http://10.0.0.1:8080"}, "")
+	sources := mirrors.sources("https://storage.alignedlayer.com/batches/batch.json?v=1")
+
+	expected := []batchSource{
+		{name: BatchMirrorPrimary, pointer: "https://storage.alignedlayer.com/batches/batch.json?v=1"},
+		{name: "mirror.example.com", pointer: "https://mirror.example.com/aligned/batches/batch.json?v=1"},
+		{name: "10.0.0.1:8080", pointer: "http://10.0.0.1:8080/batches/batch.json?v=1"},
+	}
+	if len(sources) != len(expected) {
+		t.Fatalf("expected %d sources, got %v", len(expected), sources)
+	}
+	for i := range expected {
+		if sources[i] != expected[i] {
+			t.Errorf("expected source %v, got %v", expected[i], sources[i])
+		}
+	}
+}
+
+func TestBatchMirrorsRewriteContentAddressedPointers(t *testing.T) {
+	mirrors, _ := newTestBatchMirrors(t, []string{"https://mirror.example.com/aligned"}, "")
+	sources := mirrors.sources("ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/batch.json")
+
+	expected := "https://mirror.example.com/aligned/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/batch.json"
+	if len(sources) != 2 || sources[1].pointer != expected {
+		t.Errorf("expected the mirror to keep the CID at %s, got %v", expected, sources)
+	}
+}
+
+func TestBatchMirrorsFailover(t *testing.T) {
+	batchBytes, merkleRoot := readTestBatch(t)
+	primary := serveBatch(nil, http.StatusServiceUnavailable, 0)
+	defer primary.Close()
+	tampering := serveBatch(tamperedTestBatch(t, batchBytes), http.StatusOK, 0)
+	defer tampering.Close()
+	mirror := serveBatch(batchBytes, http.StatusOK, 0)
+	defer mirror.Close()
+
+	mirrors, downloads := newTestBatchMirrors(t, []string{tampering.URL, mirror.URL + "/prefix"}, BatchMirrorsFailover)
+	if err := mirrors.Stream(context.Background(), primary.URL+"/batch.cbor", 1<<20, checkMerkleRoot(merkleRoot)); err != nil {
+		t.Fatalf("expected the batch from the last mirror, got %v", err)
+	}
+
+	expectDownloads(t, downloads(),
+		mirrorDownload{BatchMirrorPrimary, BatchDownloadError},
+		mirrorDownload{hostOf(t, tampering.URL), BatchDownloadMerkleMismatch},
+		mirrorDownload{hostOf(t, mirror.URL), BatchDownloadSuccess},
+	)
+}
+
+func TestBatchMirrorsRace(t *testing.T) {
+	batchBytes, merkleRoot := readTestBatch(t)
+	slow := serveBatch(batchBytes, http.StatusOK, time.Minute)
+	defer slow.Close()
+	fast := serveBatch(batchBytes, http.StatusOK, 0)
+	defer fast.Close()
+
+	mirrors, downloads := newTestBatchMirrors(t, []string{fast.URL}, BatchMirrorsRace)
+	if err := mirrors.Stream(context.Background(), slow.URL+"/batch.cbor", 1<<20, checkMerkleRoot(merkleRoot)); err != nil {
+		t.Fatalf("expected the batch from the fastest mirror, got %v", err)
+	}
+
+	// The slow primary is cancelled once the mirror won, so it's not reported
+	expectDownloads(t, downloads(), mirrorDownload{hostOf(t, fast.URL), BatchDownloadSuccess})
+}
+
+func TestBatchMirrorsRaceFallsBackOnMismatch(t *testing.T) {
+	batchBytes, merkleRoot := readTestBatch(t)
+	var primaryRequests atomic.Int32
+	primaryBatch := serveBatch(batchBytes, http.StatusOK, 100*time.Millisecond)
+	defer primaryBatch.Close()
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryRequests.Add(1)
+		primaryBatch.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer primary.Close()
+	tampering := serveBatch(tamperedTestBatch(t, batchBytes), http.StatusOK, 0)
+	defer tampering.Close()
+
+	mirrors, downloads := newTestBatchMirrors(t, []string{tampering.URL}, BatchMirrorsRace)
+	if err := mirrors.Stream(context.Background(), primary.URL+"/batch.cbor", 1<<20, checkMerkleRoot(merkleRoot)); err != nil {
+		t.Fatalf("expected the batch from the primary once the mirror mismatched, got %v", err)
+	}
+
+	expectDownloads(t, downloads(),
+		mirrorDownload{hostOf(t, tampering.URL), BatchDownloadMerkleMismatch},
+		mirrorDownload{BatchMirrorPrimary, BatchDownloadSuccess},
+	)
+	// The primary kept downloading while the mirror was checked, instead of being downloaded again
+	if requests := primaryRequests.Load(); requests != 1 {
+		t.Errorf("expected the primary to be requested once, got %d requests", requests)
+	}
+}
+
+func TestBatchMirrorsFailWhenEverySourceFails(t *testing.T) {
+	server := serveBatch(nil, http.StatusNotFound, 0)
+	defer server.Close()
+
+	for _, strategy := range []BatchMirrorStrategy{BatchMirrorsFailover, BatchMirrorsRace} {
+		mirrors, downloads := newTestBatchMirrors(t, []string{server.URL + "/a", server.URL + "/b"}, strategy)
+		if err := mirrors.Stream(context.Background(), server.URL+"/batch.cbor", 1<<20, checkMerkleRoot([32]byte{})); err == nil {
+			t.Errorf("%s: expected an error when every source fails", strategy)
+		}
+		if got := downloads(); len(got) != 3 {
+			t.Errorf("%s: expected every source to be reported, got %v", strategy, got)
+		}
+	}
+}
+
+func TestNewBatchMirrorsValidatesConfig(t *testing.T) {
+	fetchers := NewBatchFetchers(newTestLogger(t))
+	if _, err := NewBatchMirrors(fetchers, nil, "fastest", newTestLogger(t)); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+	if _, err := NewBatchMirrors(fetchers, []string{"mirror.example.com"}, "", newTestLogger(t)); err == nil {
+		t.Error("expected an error for a mirror URL without scheme")
+	}
+}
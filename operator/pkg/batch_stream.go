@@ -0,0 +1,257 @@
+package operator
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// ErrEmptyBatch is returned when a serialized batch has no entries
+var ErrEmptyBatch = errors.New("empty batch")
+
+// batchEntryDecoder decodes the entries of a serialized batch one at a time, returning io.EOF after the last one
+type batchEntryDecoder interface {
+	next() (VerificationData, error)
+}
+
+// decodeBatchStream decodes a batch serialized by the batcher, as CBOR or otherwise as JSON, without buffering it:
+// onEntry gets each entry as soon as it's decoded, and only the 32 bytes merkle leaf of each entry is kept.
+// Returns the merkle root of the batch, to be checked by the caller before trusting any of the entries.
+// If onEntry returns an error, decoding stops and the error is returned.
+func decodeBatchStream(r io.Reader, onEntry func(index int, verificationData VerificationData) error) ([32]byte, error) {
+	merkleRoot, _, err := decodeBatchStreamTimed(r, onEntry)
+	return merkleRoot, err
+}
+
+// batchDecodeTimings splits the time spent in decodeBatchStreamTimed, apart from reading the batch and onEntry
+type batchDecodeTimings struct {
+	// Decode is the time spent decoding the entries
+	Decode time.Duration
+	// MerkleCheck is the time spent hashing the entries and computing the merkle root
+	MerkleCheck time.Duration
+}
+
+// decodeBatchStreamTimed is decodeBatchStream, also returning how long decoding and computing the merkle root took
+func decodeBatchStreamTimed(r io.Reader, onEntry func(index int, verificationData VerificationData) error) ([32]byte, batchDecodeTimings, error) {
+	var timings batchDecodeTimings
+	start := time.Now()
+	source := &meteredReader{reader: r}
+	var onEntryElapsed time.Duration
+	defer func() {
+		timings.Decode = time.Since(start) - source.elapsed - onEntryElapsed - timings.MerkleCheck
+	}()
+
+	decoder, err := newBatchEntryDecoder(source)
+	if err != nil {
+		return [32]byte{}, timings, err
+	}
+
+	var leaves [][32]byte
+	for {
+		verificationData, err := decoder.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return [32]byte{}, timings, fmt.Errorf("error decoding batch entry %d: %w", len(leaves), err)
+		}
+
+		hashStart := time.Now()
+		leaves = append(leaves, verificationDataLeaf(verificationData))
+		timings.MerkleCheck += time.Since(hashStart)
+
+		onEntryStart := time.Now()
+		err = onEntry(len(leaves)-1, verificationData)
+		onEntryElapsed += time.Since(onEntryStart)
+		if err != nil {
+			return [32]byte{}, timings, err
+		}
+	}
+
+	if len(leaves) == 0 {
+		return [32]byte{}, timings, ErrEmptyBatch
+	}
+	rootStart := time.Now()
+	merkleRoot := batchMerkleRoot(leaves)
+	timings.MerkleCheck += time.Since(rootStart)
+	return merkleRoot, timings, nil
+}
+
+// meteredReader counts the bytes read from reader and the time spent waiting for them
+type meteredReader struct {
+	reader  io.Reader
+	bytes   int64
+	elapsed time.Duration
+}
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := r.reader.Read(p)
+	r.elapsed += time.Since(start)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// newBatchEntryDecoder picks the decoder from the first byte of the batch: a JSON batch starts with `[`,
+// which is never the start of a CBOR array
+func newBatchEntryDecoder(r io.Reader) (batchEntryDecoder, error) {
+	reader := bufio.NewReader(r)
+	for {
+		first, err := reader.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return nil, ErrEmptyBatch
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch first[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+		case '[':
+			return newJsonBatchEntryDecoder(reader)
+		default:
+			return newCborBatchEntryDecoder(reader)
+		}
+	}
+}
+
+type cborBatchEntryDecoder struct {
+	decoder   *cbor.Decoder
+	remaining uint64
+}
+
+func newCborBatchEntryDecoder(reader *bufio.Reader) (*cborBatchEntryDecoder, error) {
+	length, err := readCborArrayHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	decMode, err := createDecoderMode()
+	if err != nil {
+		return nil, fmt.Errorf("error creating CBOR decoder: %s", err)
+	}
+	return &cborBatchEntryDecoder{decoder: decMode.NewDecoder(reader), remaining: length}, nil
+}
+
+func (d *cborBatchEntryDecoder) next() (VerificationData, error) {
+	var verificationData VerificationData
+	if d.remaining == 0 {
+		return verificationData, io.EOF
+	}
+	if err := d.decoder.Decode(&verificationData); err != nil {
+		return verificationData, noUnexpectedEOF(err)
+	}
+	d.remaining--
+	return verificationData, nil
+}
+
+// readCborArrayHeader reads the header of the definite length array the batcher serializes batches as
+func readCborArrayHeader(reader *bufio.Reader) (uint64, error) {
+	initialByte, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if initialByte>>5 != 4 {
+		return 0, fmt.Errorf("batch is not a CBOR array")
+	}
+
+	additionalInfo := initialByte & 0x1f
+	switch {
+	case additionalInfo < 24:
+		return uint64(additionalInfo), nil
+	case additionalInfo <= 27:
+		var length uint64
+		for i := 0; i < 1<<(additionalInfo-24); i++ {
+			b, err := reader.ReadByte()
+			if err != nil {
+				return 0, noUnexpectedEOF(err)
+			}
+			length = length<<8 | uint64(b)
+		}
+		return length, nil
+	default:
+		return 0, fmt.Errorf("unsupported CBOR array length encoding: %d", additionalInfo)
+	}
+}
+
+type jsonBatchEntryDecoder struct {
+	decoder *json.Decoder
+}
+
+func newJsonBatchEntryDecoder(reader *bufio.Reader) (*jsonBatchEntryDecoder, error) {
+	decoder := json.NewDecoder(reader)
+	// Consumes the opening bracket of the array
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return &jsonBatchEntryDecoder{decoder: decoder}, nil
+}
+
+func (d *jsonBatchEntryDecoder) next() (VerificationData, error) {
+	var verificationData VerificationData
+	if !d.decoder.More() {
+		if _, err := d.decoder.Token(); err != nil {
+			return verificationData, noUnexpectedEOF(err)
+		}
+		return verificationData, io.EOF
+	}
+	err := d.decoder.Decode(&verificationData)
+	return verificationData, noUnexpectedEOF(err)
+}
+
+// noUnexpectedEOF turns the EOF of a truncated batch into io.ErrUnexpectedEOF, so it's not taken as the end of the batch
+func noUnexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// verificationDataLeaf is the merkle leaf of a batch entry, as computed by the batcher
+// from the commitments to the proof, public input, verification key or program and proof generator
+func verificationDataLeaf(verificationData VerificationData) [32]byte {
+	proofCommitment := crypto.Keccak256(verificationData.Proof)
+
+	pubInputCommitment := make([]byte, 32)
+	if verificationData.PubInput != nil {
+		pubInputCommitment = crypto.Keccak256(verificationData.PubInput)
+	}
+
+	// The proving system is committed along with the program of SP1 and Risc0, or with the verification key of the rest
+	auxDataCommitment := make([]byte, 32)
+	provingSystemByte := []byte{byte(verificationData.ProvingSystemId)}
+	if verificationData.VmProgramCode != nil {
+		auxDataCommitment = crypto.Keccak256(verificationData.VmProgramCode, provingSystemByte)
+	} else if verificationData.VerificationKey != nil {
+		auxDataCommitment = crypto.Keccak256(verificationData.VerificationKey, provingSystemByte)
+	}
+
+	proofGeneratorAddr := ethcommon.HexToAddress(verificationData.ProofGeneratorAddr)
+
+	return crypto.Keccak256Hash(proofCommitment, pubInputCommitment, auxDataCommitment, proofGeneratorAddr[:])
+}
+
+// batchMerkleRoot computes the root of the merkle tree of the batcher: the leaves are completed
+// to a power of two by repeating the last one, and each parent is the keccak of its two children
+func batchMerkleRoot(leaves [][32]byte) [32]byte {
+	level := leaves
+	for len(level)&(len(level)-1) != 0 {
+		level = append(level, level[len(level)-1])
+	}
+
+	for len(level) > 1 {
+		parents := make([][32]byte, len(level)/2)
+		for i := range parents {
+			parents[i] = crypto.Keccak256Hash(level[2*i][:], level[2*i+1][:])
+		}
+		level = parents
+	}
+	return level[0]
+}
@@ -0,0 +1,339 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+// readTestBatch returns a batch serialized by the batcher and its merkle root, from the merkle tree library test files
+func readTestBatch(t *testing.T) ([]byte, [32]byte) {
+	batchBytes, err := os.ReadFile("../merkle_tree/lib/test_files/merkle_tree_batch.bin")
+	if err != nil {
+		t.Fatalf("could not read test batch: %s", err)
+	}
+	rootHex, err := os.ReadFile("../merkle_tree/lib/test_files/merkle_root.bin")
+	if err != nil {
+		t.Fatalf("could not read test batch merkle root: %s", err)
+	}
+
+	var merkleRoot [32]byte
+	if _, err := hex.Decode(merkleRoot[:], bytes.TrimSpace(rootHex)); err != nil {
+		t.Fatalf("could not decode test batch merkle root: %s", err)
+	}
+	return batchBytes, merkleRoot
+}
+
+// decodeTestBatch decodes a whole batch, returning its entries and merkle root
+func decodeTestBatch(batch io.Reader) ([]VerificationData, [32]byte, error) {
+	var entries []VerificationData
+	merkleRoot, err := decodeBatchStream(batch, func(index int, verificationData VerificationData) error {
+		entries = append(entries, verificationData)
+		return nil
+	})
+	return entries, merkleRoot, err
+}
+
+func TestDecodeBatchStream(t *testing.T) {
+	batchBytes, expectedMerkleRoot := readTestBatch(t)
+
+	entries, merkleRoot, err := decodeTestBatch(bytes.NewReader(batchBytes))
+	if err != nil {
+		t.Fatalf("could not decode CBOR batch: %s", err)
+	}
+	if merkleRoot != expectedMerkleRoot {
+		t.Errorf("expected merkle root %x, got %x", expectedMerkleRoot, merkleRoot)
+	}
+	if len(entries) != 35 || entries[0].ProvingSystemId != common.Groth16Bn254 {
+		t.Fatalf("expected 35 Groth16Bn254 entries, got %d", len(entries))
+	}
+
+	// The same batch serialized as JSON has the same merkle root
+	jsonBatch, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, merkleRoot, err = decodeTestBatch(bytes.NewReader(append([]byte("\n "), jsonBatch...)))
+	if err != nil || merkleRoot != expectedMerkleRoot {
+		t.Errorf("expected the JSON batch to have merkle root %x, got %x, %v", expectedMerkleRoot, merkleRoot, err)
+	}
+
+	// A different batch has a different merkle root
+	jsonBatch, _ = json.Marshal(entries[1:])
+	if _, merkleRoot, _ = decodeTestBatch(bytes.NewReader(jsonBatch)); merkleRoot == expectedMerkleRoot {
+		t.Error("expected a different merkle root without the first entry")
+	}
+}
+
+func TestDecodeBatchStreamRejectsMalformedBatches(t *testing.T) {
+	batchBytes, _ := readTestBatch(t)
+
+	if _, _, err := decodeTestBatch(bytes.NewReader(batchBytes[:len(batchBytes)/2])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF for a truncated batch, got %v", err)
+	}
+	for _, empty := range []string{"", "[]", "\x80"} {
+		if _, _, err := decodeTestBatch(bytes.NewReader([]byte(empty))); !errors.Is(err, ErrEmptyBatch) {
+			t.Errorf("expected ErrEmptyBatch for %q, got %v", empty, err)
+		}
+	}
+	if _, _, err := decodeTestBatch(bytes.NewReader([]byte("\xa1"))); err == nil {
+		t.Error("expected an error for a batch that is not an array")
+	}
+
+	stop := errors.New("stop")
+	decoded := 0
+	_, err := decodeBatchStream(bytes.NewReader(batchBytes), func(index int, verificationData VerificationData) error {
+		decoded++
+		return stop
+	})
+	if !errors.Is(err, stop) || decoded != 1 {
+		t.Errorf("expected decoding to stop after the first entry, got %d entries, %v", decoded, err)
+	}
+}
+
+func TestProofVerificationsBoundPendingProofs(t *testing.T) {
+	verifier := &blockingVerifier{release: make(chan struct{})}
+	verifiers := NewVerifierRegistry()
+	verifiers.Replace(common.Groth16Bn254, verifier)
+	scheduler, err := NewVerificationScheduler(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationTimeouts, err := NewVerificationTimeouts(0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationCache, err := NewVerificationCache(0, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := &Operator{
+		Logger:               newTestLogger(t),
+		verifiers:            verifiers,
+		scheduler:            scheduler,
+		verificationCache:    verificationCache,
+		verificationTimeouts: verificationTimeouts,
+		metrics:              newTestMetrics(t),
+	}
+
+	verifications := operator.startProofVerifications(context.Background(), big.NewInt(0), false, nil)
+	proofs := 2 * MaxPendingProofsPerBatch
+	var submitted atomic.Int32
+	go func() {
+		for i := 0; i < proofs; i++ {
+			verifications.Submit(VerificationData{ProvingSystemId: common.Groth16Bn254, Proof: []byte{byte(i)}})
+			submitted.Add(1)
+		}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if submitted.Load() != MaxPendingProofsPerBatch {
+		t.Errorf("expected submitting to block after %d pending proofs, got %d", MaxPendingProofsPerBatch, submitted.Load())
+	}
+
+	close(verifier.release)
+	for submitted.Load() != int32(proofs) {
+		time.Sleep(time.Millisecond)
+	}
+	verdicts := verifications.Wait()
+	if len(verdicts) != proofs {
+		t.Fatalf("expected %d verdicts, got %d", proofs, len(verdicts))
+	}
+	for index, verdict := range verdicts {
+		if verdict.Index != index || !verdict.IsValid() {
+			t.Errorf("expected proof %d to verify, got %s", index, verdict)
+		}
+	}
+}
+
+func TestProofVerificationsHoldSlotOfTimedOutVerifier(t *testing.T) {
+	verifier := &blockingVerifier{release: make(chan struct{})}
+	verifiers := NewVerifierRegistry()
+	verifiers.Replace(common.Groth16Bn254, verifier)
+	scheduler, err := NewVerificationScheduler(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationTimeouts, err := NewVerificationTimeouts(10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationCache, err := NewVerificationCache(0, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := &Operator{
+		Logger:               newTestLogger(t),
+		verifiers:            verifiers,
+		scheduler:            scheduler,
+		verificationCache:    verificationCache,
+		verificationTimeouts: verificationTimeouts,
+		metrics:              newTestMetrics(t),
+	}
+
+	verifications := operator.startProofVerifications(context.Background(), big.NewInt(0), false, nil)
+	verifications.Submit(VerificationData{ProvingSystemId: common.Groth16Bn254})
+	verdicts := verifications.Wait()
+	if verdicts[0].Outcome != OutcomeTimeout {
+		t.Fatalf("expected the verification to time out, got %s", verdicts[0])
+	}
+
+	// The only slot is held by the verifier still running in the background
+	ran := make(chan struct{})
+	scheduler.Submit(common.Groth16Bn254, func() { close(ran) })
+	select {
+	case <-ran:
+		t.Error("expected the slot to be held until the timed out verifier returns")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(verifier.release)
+	<-ran
+}
+
+// gatheredHistogram returns the histogram of the metric name with the given labels, or nil if not observed
+func gatheredHistogram(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) *dto.Histogram {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			return metric.GetHistogram()
+		}
+	}
+	return nil
+}
+
+func TestOperatorObservesBatchMetrics(t *testing.T) {
+	batchBytes, merkleRoot := readTestBatch(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(batchBytes)
+	}))
+	defer server.Close()
+
+	var configuration config.OperatorConfig
+	configuration.Operator.MaxBatchSize = 1 << 20
+	batchVerifier, err := NewBatchVerifier(configuration, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := batchVerifier.operator
+	reg := prometheus.NewRegistry()
+	operator.metrics = metrics.NewMetrics("", reg, newTestLogger(t))
+
+	verdicts, err := operator.getBatchVerdictsFromDataService(context.Background(), server.URL+"/batch.cbor", merkleRoot, big.NewInt(0), false, nil)
+	if err != nil || len(verdicts) != 35 {
+		t.Fatalf("expected 35 verdicts, got %d, %v", len(verdicts), err)
+	}
+
+	download := gatheredHistogram(t, reg, "aligned_operator_batch_download_size_bytes", nil)
+	if download == nil || download.GetSampleCount() != 1 || download.GetSampleSum() != float64(len(batchBytes)) {
+		t.Errorf("expected one download of %d bytes, got %v", len(batchBytes), download)
+	}
+	for _, name := range []string{"aligned_operator_batch_download_latency_seconds", "aligned_operator_batch_decode_latency_seconds", "aligned_operator_batch_merkle_check_latency_seconds"} {
+		if histogram := gatheredHistogram(t, reg, name, nil); histogram == nil || histogram.GetSampleCount() != 1 {
+			t.Errorf("expected one observation of %s, got %v", name, histogram)
+		}
+	}
+	verification := gatheredHistogram(t, reg, "aligned_operator_verification_latency_seconds", map[string]string{"proving_system": "Groth16Bn254"})
+	if verification == nil || verification.GetSampleCount() == 0 {
+		t.Errorf("expected the latency of the Groth16Bn254 verifications to be observed, got %v", verification)
+	}
+}
+
+// slowVerifier accepts every proof after delay, verifying a single proof at a time
+type slowVerifier struct {
+	delay time.Duration
+	mutex sync.Mutex
+}
+
+func (v *slowVerifier) Name() string                     { return "slow" }
+func (v *slowVerifier) Init(logger logging.Logger) error { return nil }
+func (v *slowVerifier) SelfTest() error                  { return nil }
+func (v *slowVerifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	time.Sleep(v.delay)
+	return VerificationResult{Verified: true}
+}
+
+func TestSlowVerificationDoesNotTimeOutTheDownload(t *testing.T) {
+	batchBytes, _ := readTestBatch(t)
+	entries, _, err := decodeTestBatch(bytes.NewReader(batchBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Enough distinct proofs for the decoder to wait for the verifier
+	var proofs []VerificationData
+	for len(proofs) < 3*MaxPendingProofsPerBatch {
+		for _, entry := range entries {
+			entry.Proof = append(append([]byte(nil), entry.Proof...), byte(len(proofs)))
+			proofs = append(proofs, entry)
+		}
+	}
+	largeBatch, err := json.Marshal(proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, merkleRoot, err := decodeTestBatch(bytes.NewReader(largeBatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(largeBatch)
+	}))
+	defer server.Close()
+
+	var configuration config.OperatorConfig
+	configuration.Operator.MaxBatchSize = 1 << 24
+	batchVerifier, err := NewBatchVerifier(configuration, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := batchVerifier.operator
+	operator.verifiers.Replace(common.Groth16Bn254, &slowVerifier{delay: 5 * time.Millisecond})
+	// A single attempt, much shorter than the verification of the whole batch
+	policy := BatchFetcherPolicy{Timeout: 200 * time.Millisecond, MaxRetries: 1}
+	operator.batchMirrors, err = NewBatchMirrors(newTestBatchFetchers(t, "http", NewHttpBatchFetcher(&http.Client{}, nil), policy), nil, "", newTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startTime := time.Now()
+	verdicts, err := operator.getBatchVerdictsFromDataService(context.Background(), server.URL+"/batch.json", merkleRoot, big.NewInt(0), false, nil)
+	if err != nil || len(verdicts) != len(proofs) {
+		t.Fatalf("expected %d verdicts, got %d, %v", len(proofs), len(verdicts), err)
+	}
+	if elapsed := time.Since(startTime); elapsed < 2*policy.Timeout {
+		t.Fatalf("expected the verification to take longer than the download timeout, took %s", elapsed)
+	}
+}
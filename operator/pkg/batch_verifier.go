@@ -0,0 +1,199 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
+	"github.com/yetanotherco/aligned_layer/core/chainio"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/metrics"
+	"github.com/yetanotherco/aligned_layer/operator/merkle_tree"
+)
+
+// BatchReport holds the per-proof verdicts of a batch re-verified outside of the operator loop
+type BatchReport struct {
+	BatchMerkleRoot     ethcommon.Hash    `json:"batch_merkle_root"`
+	SenderAddress       ethcommon.Address `json:"sender_address"`
+	BatchIdentifierHash ethcommon.Hash    `json:"batch_identifier_hash"`
+	BlockNumber         uint64            `json:"block_number"`
+	BatchDataPointer    string            `json:"batch_data_pointer"`
+	Responded           bool              `json:"responded"`
+	Verdicts            []ProofVerdict    `json:"verdicts"`
+}
+
+// Valid returns true if every proof in the batch verified
+func (r *BatchReport) Valid() bool {
+	for _, verdict := range r.Verdicts {
+		if !verdict.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+// BatchVerifier verifies batches with the same verifiers, scheduler and timeouts as the operator,
+// but it never signs them nor contacts the aggregator. It's meant to debug the verdicts of the operator.
+// Unlike the operator, it verifies every proof of a batch even after one of them failed,
+// and it doesn't use the verification cache.
+type BatchVerifier struct {
+	operator *Operator
+}
+
+// NewBatchVerifier builds a verifier from the `operator` section of the configuration.
+// avsReader is only needed to verify batches from the chain, and can be nil otherwise.
+func NewBatchVerifier(configuration config.OperatorConfig, avsReader *chainio.AvsReader, logger logging.Logger) (*BatchVerifier, error) {
+	verifiers := DefaultVerifierRegistry()
+	if err := verifiers.InitAll(logger); err != nil {
+		return nil, err
+	}
+
+	scheduler, err := newVerificationSchedulerFromConfig(configuration)
+	if err != nil {
+		return nil, err
+	}
+	verificationTimeouts, err := newVerificationTimeoutsFromConfig(configuration)
+	if err != nil {
+		return nil, err
+	}
+	batchMirrors, err := newBatchMirrorsFromConfig(configuration, logger)
+	if err != nil {
+		return nil, err
+	}
+	// Only proofs repeated within the batch hit this cache, the results of the operator are never reused
+	verificationCache, err := NewVerificationCache(0, "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	operator := &Operator{
+		Config:               configuration,
+		Logger:               logger,
+		verifiers:            verifiers,
+		scheduler:            scheduler,
+		verificationCache:    verificationCache,
+		verificationTimeouts: verificationTimeouts,
+		batchMirrors:         batchMirrors,
+		// The metrics of the batch verifier are not served
+		metrics: metrics.NewMetrics("", prometheus.NewRegistry(), logger),
+	}
+	if avsReader != nil {
+		operator.avsReader = *avsReader
+	}
+
+	return &BatchVerifier{operator: operator}, nil
+}
+
+// FindBatch returns the NewBatchV3 event of the batch with the given merkle root, created from fromBlock on.
+// If senderAddress is nil, the merkle root must match a single batch.
+func (v *BatchVerifier) FindBatch(batchMerkleRoot [32]byte, senderAddress *ethcommon.Address, fromBlock uint64) (*servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, error) {
+	logs, err := v.operator.avsReader.GetNewBatchV3Logs(batchMerkleRoot, fromBlock)
+	if err != nil {
+		return nil, fmt.Errorf("could not get batch events: %w", err)
+	}
+
+	var found []servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
+	for _, log := range logs {
+		if senderAddress == nil || log.SenderAddress == *senderAddress {
+			found = append(found, log)
+		}
+	}
+
+	switch {
+	case len(found) == 0:
+		return nil, fmt.Errorf("no batch found with merkle root 0x%x", batchMerkleRoot)
+	case senderAddress == nil && len(found) > 1:
+		senders := make([]string, len(found))
+		for i, log := range found {
+			senders[i] = log.SenderAddress.Hex()
+		}
+		return nil, fmt.Errorf("%d batches found with merkle root 0x%x, pick a sender among %v", len(found), batchMerkleRoot, senders)
+	}
+	// The same batch could be submitted again by the same sender after it expired, the latest one is the relevant one
+	return &found[len(found)-1], nil
+}
+
+// VerifyOnChainBatch downloads the batch of a NewBatchV3 event, checks its merkle root
+// and verifies every proof in it, honoring the verifiers disabled on chain
+func (v *BatchVerifier) VerifyOnChainBatch(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) (BatchReport, error) {
+	batchIdentifierHash := computeBatchIdentifierHash(newBatchLog.BatchMerkleRoot, newBatchLog.SenderAddress)
+	report := BatchReport{
+		BatchMerkleRoot:     newBatchLog.BatchMerkleRoot,
+		SenderAddress:       newBatchLog.SenderAddress,
+		BatchIdentifierHash: batchIdentifierHash,
+		BlockNumber:         newBatchLog.Raw.BlockNumber,
+		BatchDataPointer:    newBatchLog.BatchDataPointer,
+	}
+
+	responded, err := v.operator.avsReader.IsBatchResponded(batchIdentifierHash)
+	if err != nil {
+		return report, fmt.Errorf("could not check batch state: %w", err)
+	}
+	report.Responded = responded
+
+	disabledVerifiersBitmap, err := v.operator.avsReader.DisabledVerifiers()
+	if err != nil {
+		return report, fmt.Errorf("could not check verifiers status: %w", err)
+	}
+
+	verdicts, err := v.operator.getBatchVerdictsFromDataService(ctx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, disabledVerifiersBitmap, false, nil)
+	if err != nil {
+		return report, fmt.Errorf("could not get batch: %w", err)
+	}
+
+	report.Verdicts = verdicts
+	return report, nil
+}
+
+// VerifyBatchFile verifies every proof of a batch file, serialized as CBOR or JSON like the batches in the data service,
+// and reports the merkle root of the file. If expectedMerkleRoot is not nil, the merkle root must match it,
+// both as computed by the merkle tree library of the batcher before verifying the proofs and as computed while
+// streaming them. No chain access is needed.
+func (v *BatchVerifier) VerifyBatchFile(ctx context.Context, path string, expectedMerkleRoot *[32]byte) (BatchReport, error) {
+	report := BatchReport{BatchDataPointer: path}
+
+	if expectedMerkleRoot != nil {
+		batchBytes, err := os.ReadFile(path)
+		if err != nil {
+			return report, fmt.Errorf("could not read batch file: %w", err)
+		}
+		verified, err := merkle_tree.VerifyMerkleTreeBatch(batchBytes, *expectedMerkleRoot)
+		if err != nil {
+			return report, fmt.Errorf("could not verify the merkle root with the merkle tree library: %w", err)
+		}
+		if !verified {
+			return report, fmt.Errorf("%w: the merkle tree library doesn't match 0x%x", ErrBatchMerkleRootMismatch, *expectedMerkleRoot)
+		}
+	}
+
+	batchFile, err := os.Open(path)
+	if err != nil {
+		return report, fmt.Errorf("could not read batch file: %w", err)
+	}
+	defer batchFile.Close()
+
+	merkleRoot, verdicts, err := v.operator.verifyBatchStream(ctx, batchFile, big.NewInt(0), false, nil)
+	if err != nil {
+		return report, fmt.Errorf("could not decode batch file: %w", err)
+	}
+	report.BatchMerkleRoot = merkleRoot
+	if expectedMerkleRoot != nil && merkleRoot != *expectedMerkleRoot {
+		return report, fmt.Errorf("%w: expected 0x%x, got 0x%x", ErrBatchMerkleRootMismatch, *expectedMerkleRoot, merkleRoot)
+	}
+
+	report.Verdicts = verdicts
+	return report, nil
+}
+
+// Verify verifies every proof of a batch. A nil disabledVerifiersBitmap enables every verifier.
+func (v *BatchVerifier) Verify(ctx context.Context, verificationDataBatch []VerificationData, disabledVerifiersBitmap *big.Int) []ProofVerdict {
+	if disabledVerifiersBitmap == nil {
+		disabledVerifiersBitmap = big.NewInt(0)
+	}
+	return v.operator.verifyProofs(ctx, verificationDataBatch, disabledVerifiersBitmap, nil)
+}
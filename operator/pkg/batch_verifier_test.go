@@ -0,0 +1,89 @@
+package operator
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ugorji/go/codec"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+func TestBatchVerifierReportsEveryProof(t *testing.T) {
+	batchVerifier, err := NewBatchVerifier(config.OperatorConfig{}, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("could not create batch verifier: %s", err)
+	}
+
+	validData := readPlonkBn254VerificationData(t)
+	malformedData := validData
+	malformedData.Proof = []byte{1, 2, 3}
+	batch := []VerificationData{malformedData, validData, validData}
+
+	verdicts := batchVerifier.Verify(context.Background(), batch, nil)
+	if len(verdicts) != len(batch) {
+		t.Fatalf("expected %d verdicts, got %d", len(batch), len(verdicts))
+	}
+	if verdicts[0].Outcome != OutcomeDeserializationError {
+		t.Errorf("expected a deserialization error for proof 0, got %s", verdicts[0].Outcome)
+	}
+	// The operator would cancel the rest of the batch after the first failure
+	for _, verdict := range verdicts[1:] {
+		if !verdict.IsValid() {
+			t.Errorf("expected proof %d to verify, got %s", verdict.Index, verdict)
+		}
+	}
+
+	disabled := new(big.Int).Lsh(big.NewInt(1), uint(common.GnarkPlonkBn254))
+	verdicts = batchVerifier.Verify(context.Background(), batch[1:], disabled)
+	if verdicts[0].Outcome != OutcomeVerifierDisabled {
+		t.Errorf("expected a disabled verifier, got %s", verdicts[0].Outcome)
+	}
+}
+
+func TestBatchVerifierVerifiesBatchFiles(t *testing.T) {
+	batchVerifier, err := NewBatchVerifier(config.OperatorConfig{}, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("could not create batch verifier: %s", err)
+	}
+	// Serialized like the batcher does, with the proving system as a string
+	type batchEntry struct {
+		ProvingSystem   string `json:"proving_system"`
+		Proof           []byte `json:"proof"`
+		PubInput        []byte `json:"pub_input"`
+		VerificationKey []byte `json:"verification_key"`
+	}
+	verificationData := readPlonkBn254VerificationData(t)
+	entry := batchEntry{"GnarkPlonkBn254", verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey}
+	batch := []batchEntry{entry, entry}
+
+	cborBatch, err := cbor.Marshal(batch)
+	if err != nil {
+		t.Fatalf("could not encode batch as CBOR: %s", err)
+	}
+	var jsonBatch []byte
+	if err := codec.NewEncoderBytes(&jsonBatch, new(codec.JsonHandle)).Encode(batch); err != nil {
+		t.Fatalf("could not encode batch as JSON: %s", err)
+	}
+
+	for name, batchBytes := range map[string][]byte{"cbor": cborBatch, "json": jsonBatch} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "batch")
+			if err := os.WriteFile(path, batchBytes, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := batchVerifier.VerifyBatchFile(context.Background(), path, nil)
+			if err != nil {
+				t.Fatalf("could not verify batch file: %s", err)
+			}
+			if len(report.Verdicts) != len(batch) || !report.Valid() {
+				t.Errorf("expected %d valid proofs, got %+v", len(batch), report.Verdicts)
+			}
+		})
+	}
+}
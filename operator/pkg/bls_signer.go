@@ -0,0 +1,244 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/yetanotherco/aligned_layer/core/config"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+// DefaultRemoteBlsSignerTimeout bounds each request to the remote BLS signer
+const DefaultRemoteBlsSignerTimeout = 10 * time.Second
+
+// The endpoints of the remote BLS signer, see BlsSignerServer
+const (
+	blsSignerPubkeysPath = "/v1/pubkeys"
+	blsSignerSignPath    = "/v1/sign"
+)
+
+var ErrInvalidBlsSignature = errors.New("invalid BLS signature")
+
+// BlsSigner signs messages with the BLS key of the operator
+type BlsSigner interface {
+	SignMessage(ctx context.Context, message [32]byte) (*bls.Signature, error)
+	PubKeyG1() *bls.G1Point
+	PubKeyG2() *bls.G2Point
+}
+
+// NewBlsSignerFromConfig returns the remote signer of blsConfig if configured, or else signs with its keystore
+func NewBlsSignerFromConfig(ctx context.Context, blsConfig *config.BlsConfig) (BlsSigner, error) {
+	if blsConfig.RemoteSigner.Url != "" {
+		return NewRemoteBlsSigner(ctx, blsConfig.RemoteSigner)
+	}
+	if blsConfig.KeyPair == nil {
+		return nil, fmt.Errorf("neither a BLS keystore nor a remote signer is configured")
+	}
+	return NewLocalBlsSigner(blsConfig.KeyPair), nil
+}
+
+// LocalBlsSigner signs with a BLS key held in memory, read from a keystore
+type LocalBlsSigner struct {
+	keyPair *bls.KeyPair
+}
+
+func NewLocalBlsSigner(keyPair *bls.KeyPair) *LocalBlsSigner {
+	return &LocalBlsSigner{keyPair: keyPair}
+}
+
+func (s *LocalBlsSigner) SignMessage(ctx context.Context, message [32]byte) (*bls.Signature, error) {
+	return s.keyPair.SignMessage(message), nil
+}
+
+func (s *LocalBlsSigner) PubKeyG1() *bls.G1Point {
+	return s.keyPair.GetPubKeyG1()
+}
+
+func (s *LocalBlsSigner) PubKeyG2() *bls.G2Point {
+	return s.keyPair.GetPubKeyG2()
+}
+
+// RemoteBlsSigner signs with a BLS key held by a signing service, so it never sits in the operator memory.
+// The public keys are fetched once, and every signature is verified against them before being used.
+type RemoteBlsSigner struct {
+	url       string
+	authToken string
+	client    *http.Client
+	pubKeyG1  *bls.G1Point
+	pubKeyG2  *bls.G2Point
+}
+
+type blsSignerPubkeysResponse struct {
+	PubKeyG1 string `json:"pubkey_g1"`
+	PubKeyG2 string `json:"pubkey_g2"`
+}
+
+type blsSignerSignRequest struct {
+	Message string `json:"message"`
+}
+
+type blsSignerSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+type blsSignerErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewRemoteBlsSigner connects to the signer of signerConfig and fetches its public keys.
+// The signer must be served over https, unless it's on a loopback address.
+func NewRemoteBlsSigner(ctx context.Context, signerConfig config.RemoteBlsSignerConfig) (*RemoteBlsSigner, error) {
+	signerUrl, err := url.Parse(signerConfig.Url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote BLS signer url: %w", err)
+	}
+	if signerUrl.Scheme != "https" && !(signerUrl.Scheme == "http" && utils.IsLoopbackHost(signerUrl.Hostname())) {
+		return nil, fmt.Errorf("remote BLS signer url must be https, got %s", signerConfig.Url)
+	}
+
+	tlsConfig, err := utils.NewTLSClientConfig(signerConfig.CaCertPath, signerConfig.ClientCertPath, signerConfig.ClientKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS config of the remote BLS signer: %w", err)
+	}
+	timeout := signerConfig.Timeout
+	if timeout == 0 {
+		timeout = DefaultRemoteBlsSignerTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	signer := &RemoteBlsSigner{
+		url:       strings.TrimSuffix(signerConfig.Url, "/"),
+		authToken: signerConfig.AuthToken,
+		client:    &http.Client{Transport: transport, Timeout: timeout},
+	}
+
+	var pubkeys blsSignerPubkeysResponse
+	if err := signer.do(ctx, http.MethodGet, blsSignerPubkeysPath, nil, &pubkeys); err != nil {
+		return nil, fmt.Errorf("could not get the public keys of the remote BLS signer: %w", err)
+	}
+	if signer.pubKeyG1, err = decodeG1Point(pubkeys.PubKeyG1); err != nil {
+		return nil, fmt.Errorf("invalid G1 public key of the remote BLS signer: %w", err)
+	}
+	if signer.pubKeyG2, err = decodeG2Point(pubkeys.PubKeyG2); err != nil {
+		return nil, fmt.Errorf("invalid G2 public key of the remote BLS signer: %w", err)
+	}
+	if equivalent, err := signer.pubKeyG1.VerifyEquivalence(signer.pubKeyG2); err != nil || !equivalent {
+		return nil, fmt.Errorf("the G1 and G2 public keys of the remote BLS signer don't belong to the same key")
+	}
+	return signer, nil
+}
+
+func (s *RemoteBlsSigner) SignMessage(ctx context.Context, message [32]byte) (*bls.Signature, error) {
+	var response blsSignerSignResponse
+	request := blsSignerSignRequest{Message: "0x" + hex.EncodeToString(message[:])}
+	if err := s.do(ctx, http.MethodPost, blsSignerSignPath, request, &response); err != nil {
+		return nil, err
+	}
+
+	point, err := decodeG1Point(response.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBlsSignature, err)
+	}
+	signature := &bls.Signature{G1Point: point}
+	if ok, err := signature.Verify(s.pubKeyG2, message); err != nil || !ok {
+		return nil, fmt.Errorf("%w: it doesn't verify with the public key of the signer", ErrInvalidBlsSignature)
+	}
+	return signature, nil
+}
+
+func (s *RemoteBlsSigner) PubKeyG1() *bls.G1Point {
+	return s.pubKeyG1
+}
+
+func (s *RemoteBlsSigner) PubKeyG2() *bls.G2Point {
+	return s.pubKeyG2
+}
+
+// do sends a request to the signer, encoding body and decoding the response into reply as JSON
+func (s *RemoteBlsSigner) do(ctx context.Context, method string, path string, body any, reply any) error {
+	var requestBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewReader(encoded)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, s.url+path, requestBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if s.authToken != "" {
+		request.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(response.Body, 1<<20))
+	if response.StatusCode != http.StatusOK {
+		var errorResponse blsSignerErrorResponse
+		if decoder.Decode(&errorResponse) == nil && errorResponse.Error != "" {
+			return fmt.Errorf("remote BLS signer responded %s: %s", response.Status, errorResponse.Error)
+		}
+		return fmt.Errorf("remote BLS signer responded %s", response.Status)
+	}
+	return decoder.Decode(reply)
+}
+
+// decodeG1Point decodes a G1 point serialized as its 32 bytes X and Y coordinates, in hex
+func decodeG1Point(encoded string) (*bls.G1Point, error) {
+	serialized, err := hex.DecodeString(strings.TrimPrefix(encoded, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(serialized) != 64 {
+		return nil, fmt.Errorf("expected 64 bytes, got %d", len(serialized))
+	}
+	point := new(bls.G1Point).Deserialize(serialized)
+	if !point.IsOnCurve() || !point.IsInSubGroup() {
+		return nil, fmt.Errorf("not a point of the BN254 G1 group")
+	}
+	return point, nil
+}
+
+// decodeG2Point decodes a G2 point serialized as its 32 bytes X.A0, X.A1, Y.A0 and Y.A1 coordinates, in hex
+func decodeG2Point(encoded string) (*bls.G2Point, error) {
+	serialized, err := hex.DecodeString(strings.TrimPrefix(encoded, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(serialized) != 128 {
+		return nil, fmt.Errorf("expected 128 bytes, got %d", len(serialized))
+	}
+	point := new(bls.G2Point).Deserialize(serialized)
+	if !point.IsOnCurve() || !point.IsInSubGroup() {
+		return nil, fmt.Errorf("not a point of the BN254 G2 group")
+	}
+	return point, nil
+}
+
+func encodeG1Point(point *bls.G1Point) string {
+	return "0x" + hex.EncodeToString(point.Serialize())
+}
+
+func encodeG2Point(point *bls.G2Point) string {
+	return "0x" + hex.EncodeToString(point.Serialize())
+}
@@ -0,0 +1,85 @@
+package operator
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+)
+
+// BlsSignerServer serves the API of a remote BLS signer, signing with a local key pair:
+//
+//	GET  /v1/pubkeys  -> {"pubkey_g1": "0x<64 bytes>", "pubkey_g2": "0x<128 bytes>"}
+//	POST /v1/sign     {"message": "0x<32 bytes>"} -> {"signature": "0x<64 bytes>"}
+//
+// Requests must carry "Authorization: Bearer <authToken>" if authToken is set.
+// It's a stand-in for a hardened signing service, for tests and devnets: it signs any message.
+type BlsSignerServer struct {
+	keyPair   *bls.KeyPair
+	authToken string
+	mux       *http.ServeMux
+}
+
+func NewBlsSignerServer(keyPair *bls.KeyPair, authToken string) *BlsSignerServer {
+	server := &BlsSignerServer{keyPair: keyPair, authToken: authToken, mux: http.NewServeMux()}
+	server.mux.HandleFunc(blsSignerPubkeysPath, server.handlePubkeys)
+	server.mux.HandleFunc(blsSignerSignPath, server.handleSign)
+	return server
+}
+
+func (s *BlsSignerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.authToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) != 1 {
+		writeBlsSignerError(w, http.StatusUnauthorized, "invalid auth token")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *BlsSignerServer) handlePubkeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeBlsSignerError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeBlsSignerResponse(w, blsSignerPubkeysResponse{
+		PubKeyG1: encodeG1Point(s.keyPair.GetPubKeyG1()),
+		PubKeyG2: encodeG2Point(s.keyPair.GetPubKeyG2()),
+	})
+}
+
+func (s *BlsSignerServer) handleSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeBlsSignerError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var request blsSignerSignRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<10)).Decode(&request); err != nil {
+		writeBlsSignerError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	decoded, err := hex.DecodeString(strings.TrimPrefix(request.Message, "0x"))
+	if err != nil || len(decoded) != 32 {
+		writeBlsSignerError(w, http.StatusBadRequest, "message must be 32 bytes in hex")
+		return
+	}
+
+	var message [32]byte
+	copy(message[:], decoded)
+	signature := s.keyPair.SignMessage(message)
+	writeBlsSignerResponse(w, blsSignerSignResponse{Signature: encodeG1Point(signature.G1Point)})
+}
+
+func writeBlsSignerResponse(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeBlsSignerError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(blsSignerErrorResponse{Error: message})
+}
@@ -0,0 +1,105 @@
+package operator
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// newTestBlsSignerServer serves handler over TLS and returns the config of a remote signer trusting it
+func newTestBlsSignerServer(t *testing.T, handler http.Handler, authToken string) config.RemoteBlsSignerConfig {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	caCertPath := filepath.Join(t.TempDir(), "ca.pem")
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caCertPath, caCert, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return config.RemoteBlsSignerConfig{Url: server.URL, AuthToken: authToken, CaCertPath: caCertPath}
+}
+
+func TestRemoteBlsSignerSignsLikeTheLocalKey(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerConfig := newTestBlsSignerServer(t, NewBlsSignerServer(keyPair, "token"), "token")
+
+	signer, err := NewBlsSignerFromConfig(context.Background(), &config.BlsConfig{RemoteSigner: signerConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signer.PubKeyG1().Equal(keyPair.GetPubKeyG1().G1Affine) || !signer.PubKeyG2().Equal(keyPair.GetPubKeyG2().G2Affine) {
+		t.Error("expected the public keys of the remote signer to be the ones of its key")
+	}
+
+	message := [32]byte{1, 2, 3}
+	signature, err := signer.SignMessage(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localSignature, err := NewLocalBlsSigner(keyPair).SignMessage(context.Background(), message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signature.Equal(localSignature.G1Affine) {
+		t.Error("expected the remote signature to be the one of the local key")
+	}
+}
+
+func TestRemoteBlsSignerAuthentication(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerConfig := newTestBlsSignerServer(t, NewBlsSignerServer(keyPair, "token"), "wrong")
+	if _, err := NewRemoteBlsSigner(context.Background(), signerConfig); err == nil {
+		t.Error("expected the signer to reject a wrong auth token")
+	}
+
+	// The self-signed certificate of the signer is only trusted with its CA
+	signerConfig.AuthToken = "token"
+	signerConfig.CaCertPath = ""
+	if _, err := NewRemoteBlsSigner(context.Background(), signerConfig); err == nil {
+		t.Error("expected the certificate of the signer not to be trusted")
+	}
+
+	plainConfig := config.RemoteBlsSignerConfig{Url: "http://signer.example.com:9000"}
+	if _, err := NewRemoteBlsSigner(context.Background(), plainConfig); err == nil {
+		t.Error("expected a remote signer over plain http to be rejected")
+	}
+}
+
+func TestRemoteBlsSignerRejectsSignaturesOfOtherKeys(t *testing.T) {
+	keyPair, err := bls.NewKeyPairFromString("12345")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKeyPair, err := bls.NewKeyPairFromString("54321")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Advertises the public keys of keyPair, but signs with otherKeyPair
+	mux := http.NewServeMux()
+	mux.Handle(blsSignerPubkeysPath, NewBlsSignerServer(keyPair, ""))
+	mux.Handle(blsSignerSignPath, NewBlsSignerServer(otherKeyPair, ""))
+	signerConfig := newTestBlsSignerServer(t, mux, "")
+
+	signer, err := NewRemoteBlsSigner(context.Background(), signerConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.SignMessage(context.Background(), [32]byte{1}); !errors.Is(err, ErrInvalidBlsSignature) {
+		t.Errorf("expected the signature to be rejected, got %v", err)
+	}
+}
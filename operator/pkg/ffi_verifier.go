@@ -0,0 +1,114 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/operator/risc_zero"
+	"github.com/yetanotherco/aligned_layer/operator/risc_zero_old"
+	"github.com/yetanotherco/aligned_layer/operator/sp1"
+	"github.com/yetanotherco/aligned_layer/operator/sp1_old"
+)
+
+// The versions of the verifier libraries linked through the FFIs, see the Cargo.toml of each FFI.
+// They must be updated along with them, so the verdicts cached by the previous versions are discarded.
+const (
+	sp1VerifierVersion      = "sp1-sdk v3.0.0, fallback sp1-sdk v1.0.1"
+	riscZeroVerifierVersion = "risc0-zkvm v1.1.2, fallback risc0-zkvm v1.0.1"
+)
+
+// Sp1Verifier verifies SP1 proofs through the SP1 FFI,
+// falling back to the previous SP1 version when the current one rejects the proof
+type Sp1Verifier struct {
+	logger logging.Logger
+}
+
+func NewSp1Verifier() *Sp1Verifier {
+	return &Sp1Verifier{}
+}
+
+func (v *Sp1Verifier) Name() string {
+	return "SP1"
+}
+
+func (v *Sp1Verifier) Version() string {
+	return sp1VerifierVersion
+}
+
+func (v *Sp1Verifier) Init(logger logging.Logger) error {
+	v.logger = logger
+	return nil
+}
+
+func (v *Sp1Verifier) SelfTest() error {
+	return selfTestRejectsMalformed(v)
+}
+
+func (v *Sp1Verifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	verificationResult, err := sp1.VerifySp1Proof(verificationData.Proof, verificationData.VmProgramCode)
+	if !verificationResult {
+		// Don't start the fallback if the verification was cancelled in the meantime
+		if err := verificationCancelled(ctx); err != nil {
+			return VerificationResult{Verified: false, Err: err}
+		}
+		v.logger.Infof("SP1 proof verification failed. Trying old SP1 version...")
+		verificationResult, err = sp1_old.VerifySp1ProofOld(verificationData.Proof, verificationData.VmProgramCode)
+		if !verificationResult {
+			v.logger.Errorf("Old SP1 proof verification failed")
+		}
+	}
+	v.logger.Infof("SP1 proof verification result: %t", verificationResult)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrVerifierPanic, err)
+	}
+	return VerificationResult{Verified: verificationResult, Err: err}
+}
+
+// RiscZeroVerifier verifies Risc0 receipts through the Risc0 FFI,
+// falling back to the previous Risc0 version when the current one rejects the receipt
+type RiscZeroVerifier struct {
+	logger logging.Logger
+}
+
+func NewRiscZeroVerifier() *RiscZeroVerifier {
+	return &RiscZeroVerifier{}
+}
+
+func (v *RiscZeroVerifier) Name() string {
+	return "Risc0"
+}
+
+func (v *RiscZeroVerifier) Version() string {
+	return riscZeroVerifierVersion
+}
+
+func (v *RiscZeroVerifier) Init(logger logging.Logger) error {
+	v.logger = logger
+	return nil
+}
+
+func (v *RiscZeroVerifier) SelfTest() error {
+	return selfTestRejectsMalformed(v)
+}
+
+func (v *RiscZeroVerifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	verificationResult, err := risc_zero.VerifyRiscZeroReceipt(verificationData.Proof,
+		verificationData.VmProgramCode, verificationData.PubInput)
+	if !verificationResult {
+		// Don't start the fallback if the verification was cancelled in the meantime
+		if err := verificationCancelled(ctx); err != nil {
+			return VerificationResult{Verified: false, Err: err}
+		}
+		v.logger.Infof("Risc0 proof verification failed. Trying old Risc0 version...")
+		verificationResult, err = risc_zero_old.VerifyRiscZeroReceiptOld(verificationData.Proof, verificationData.VmProgramCode, verificationData.PubInput)
+		if !verificationResult {
+			v.logger.Errorf("Old Risc0 proof verification failed")
+		}
+	}
+	v.logger.Infof("Risc0 proof verification result: %t", verificationResult)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrVerifierPanic, err)
+	}
+	return VerificationResult{Verified: verificationResult, Err: err}
+}
@@ -0,0 +1,167 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/yetanotherco/aligned_layer/core/vkcache"
+)
+
+// gnarkVersion is the version of the gnark modules built into the binary
+var gnarkVersion = sync.OnceValue(func() string {
+	version := "gnark"
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return version + " unknown"
+	}
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == "github.com/consensys/gnark" || dep.Path == "github.com/consensys/gnark-crypto" {
+			version += " " + dep.Path + "@" + dep.Version
+		}
+	}
+	return version
+})
+
+// GnarkPlonkVerifier verifies gnark PLONK proofs over a given curve
+type GnarkPlonkVerifier struct {
+	curve             ecc.ID
+	verifyingKeyCache *vkcache.Cache
+	logger            logging.Logger
+}
+
+func NewGnarkPlonkVerifier(curve ecc.ID) *GnarkPlonkVerifier {
+	return &GnarkPlonkVerifier{curve: curve, verifyingKeyCache: DefaultVerifyingKeyCache()}
+}
+
+func (v *GnarkPlonkVerifier) Name() string {
+	return "PLONK " + v.curve.String()
+}
+
+func (v *GnarkPlonkVerifier) Version() string {
+	return gnarkVersion()
+}
+
+func (v *GnarkPlonkVerifier) Init(logger logging.Logger) error {
+	v.logger = logger
+	return nil
+}
+
+func (v *GnarkPlonkVerifier) SelfTest() error {
+	return selfTestRejectsMalformed(v)
+}
+
+func (v *GnarkPlonkVerifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	verified, err := verifyPlonkProof(ctx, verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey, v.curve, v.verifyingKeyCache)
+	if err == nil {
+		v.logger.Infof("%s proof verification result: %t", v.Name(), verified)
+	}
+	return VerificationResult{Verified: verified, Err: err}
+}
+
+// GnarkGroth16Verifier verifies gnark Groth16 proofs over a given curve
+type GnarkGroth16Verifier struct {
+	curve             ecc.ID
+	verifyingKeyCache *vkcache.Cache
+	logger            logging.Logger
+}
+
+func NewGnarkGroth16Verifier(curve ecc.ID) *GnarkGroth16Verifier {
+	return &GnarkGroth16Verifier{curve: curve, verifyingKeyCache: DefaultVerifyingKeyCache()}
+}
+
+func (v *GnarkGroth16Verifier) Name() string {
+	return "GROTH16 " + v.curve.String()
+}
+
+func (v *GnarkGroth16Verifier) Version() string {
+	return gnarkVersion()
+}
+
+func (v *GnarkGroth16Verifier) Init(logger logging.Logger) error {
+	v.logger = logger
+	return nil
+}
+
+func (v *GnarkGroth16Verifier) SelfTest() error {
+	return selfTestRejectsMalformed(v)
+}
+
+func (v *GnarkGroth16Verifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	verified, err := verifyGroth16Proof(ctx, verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey, v.curve, v.verifyingKeyCache)
+	if err == nil {
+		v.logger.Infof("%s proof verification result: %t", v.Name(), verified)
+	}
+	return VerificationResult{Verified: verified, Err: err}
+}
+
+// verifyPlonkProof contains the common proof verification logic.
+// The verifying key is taken from verifyingKeyCache, which parses it on the first use.
+// An error is returned only when the inputs could not be deserialized or ctx is done before verifying.
+func verifyPlonkProof(ctx context.Context, proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte, curve ecc.ID, verifyingKeyCache *vkcache.Cache) (bool, error) {
+	proofReader := bytes.NewReader(proofBytes)
+	proof := plonk.NewProof(curve)
+	if _, err := proof.ReadFrom(proofReader); err != nil {
+		return false, fmt.Errorf("%w: could not deserialize proof: %v", ErrDeserialization, err)
+	}
+
+	pubInputReader := bytes.NewReader(pubInputBytes)
+	pubInput, err := witness.New(curve.ScalarField())
+	if err != nil {
+		return false, fmt.Errorf("%w: error instantiating witness: %v", ErrDeserialization, err)
+	}
+	if _, err = pubInput.ReadFrom(pubInputReader); err != nil {
+		return false, fmt.Errorf("%w: could not read PLONK public input: %v", ErrDeserialization, err)
+	}
+
+	verificationKey, err := verifyingKeyCache.PlonkVerifyingKey(verificationKeyBytes, curve)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDeserialization, err)
+	}
+
+	if err = verificationCancelled(ctx); err != nil {
+		return false, err
+	}
+
+	err = plonk.Verify(proof, verificationKey, pubInput)
+	return err == nil, nil
+}
+
+// verifyGroth16Proof contains the common proof verification logic.
+// The verifying key is taken from verifyingKeyCache, which parses it on the first use.
+// An error is returned only when the inputs could not be deserialized or ctx is done before verifying.
+func verifyGroth16Proof(ctx context.Context, proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte, curve ecc.ID, verifyingKeyCache *vkcache.Cache) (bool, error) {
+	proofReader := bytes.NewReader(proofBytes)
+	proof := groth16.NewProof(curve)
+	if _, err := proof.ReadFrom(proofReader); err != nil {
+		return false, fmt.Errorf("%w: could not deserialize proof: %v", ErrDeserialization, err)
+	}
+
+	pubInputReader := bytes.NewReader(pubInputBytes)
+	pubInput, err := witness.New(curve.ScalarField())
+	if err != nil {
+		return false, fmt.Errorf("%w: error instantiating witness: %v", ErrDeserialization, err)
+	}
+	if _, err = pubInput.ReadFrom(pubInputReader); err != nil {
+		return false, fmt.Errorf("%w: could not read Groth16 public input: %v", ErrDeserialization, err)
+	}
+
+	verificationKey, err := verifyingKeyCache.Groth16VerifyingKey(verificationKeyBytes, curve)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDeserialization, err)
+	}
+
+	if err = verificationCancelled(ctx); err != nil {
+		return false, err
+	}
+
+	err = groth16.Verify(proof, verificationKey, pubInput)
+	return err == nil, nil
+}
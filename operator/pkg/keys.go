@@ -0,0 +1,133 @@
+package operator
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type KeyType string
+
+const (
+	KeyTypeBls   KeyType = "bls"
+	KeyTypeEcdsa KeyType = "ecdsa"
+)
+
+var ErrKeystoreExists = errors.New("keystore already exists")
+
+func ParseKeyType(keyType string) (KeyType, error) {
+	switch KeyType(strings.ToLower(keyType)) {
+	case KeyTypeBls:
+		return KeyTypeBls, nil
+	case KeyTypeEcdsa:
+		return KeyTypeEcdsa, nil
+	default:
+		return "", fmt.Errorf("unknown key type %q, expected %q or %q", keyType, KeyTypeBls, KeyTypeEcdsa)
+	}
+}
+
+// GenerateBlsKeystore generates a random BLS key and stores it encrypted with password at path.
+// It never overwrites an existing keystore.
+func GenerateBlsKeystore(path string, password string) (*bls.KeyPair, error) {
+	keyPair, err := bls.GenRandomBlsKeys()
+	if err != nil {
+		return nil, err
+	}
+	return keyPair, writeNewKeystore(path, func(path string) error { return keyPair.SaveToFile(path, password) })
+}
+
+// ImportBlsKeystore stores the BLS key privateKeyHex encrypted with password at path.
+// It never overwrites an existing keystore.
+func ImportBlsKeystore(path string, privateKeyHex string, password string) (*bls.KeyPair, error) {
+	keyPair, err := blsKeyPairFromHex(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	return keyPair, writeNewKeystore(path, func(path string) error { return keyPair.SaveToFile(path, password) })
+}
+
+// ChangeBlsKeystorePassword encrypts the BLS keystore at path with newPassword.
+// The keystore is replaced atomically, so it's never left half written.
+func ChangeBlsKeystorePassword(path string, password string, newPassword string) (*bls.KeyPair, error) {
+	keyPair, err := bls.ReadPrivateKeyFromFile(path, password)
+	if err != nil {
+		return nil, err
+	}
+	return keyPair, replaceKeystore(path, func(path string) error { return keyPair.SaveToFile(path, newPassword) })
+}
+
+// GenerateEcdsaKeystore generates a random ECDSA key and stores it encrypted with password at path.
+// It never overwrites an existing keystore.
+func GenerateEcdsaKeystore(path string, password string) (*ecdsa.PrivateKey, error) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, writeNewKeystore(path, func(path string) error { return sdkecdsa.WriteKey(path, privateKey, password) })
+}
+
+// ImportEcdsaKeystore stores the ECDSA key privateKeyHex encrypted with password at path.
+// It never overwrites an existing keystore.
+func ImportEcdsaKeystore(path string, privateKeyHex string, password string) (*ecdsa.PrivateKey, error) {
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, writeNewKeystore(path, func(path string) error { return sdkecdsa.WriteKey(path, privateKey, password) })
+}
+
+// ChangeEcdsaKeystorePassword encrypts the ECDSA keystore at path with newPassword.
+// The keystore is replaced atomically, so it's never left half written.
+func ChangeEcdsaKeystorePassword(path string, password string, newPassword string) (*ecdsa.PrivateKey, error) {
+	privateKey, err := sdkecdsa.ReadKey(path, password)
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, replaceKeystore(path, func(path string) error { return sdkecdsa.WriteKey(path, privateKey, newPassword) })
+}
+
+// blsKeyPairFromHex parses a BLS private key in hex, with or without the 0x prefix.
+// The key must be a scalar of the BN254 curve.
+func blsKeyPairFromHex(privateKeyHex string) (*bls.KeyPair, error) {
+	privateKey, ok := new(big.Int).SetString(strings.TrimPrefix(privateKeyHex, "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid BLS private key: not a hex number")
+	}
+	if privateKey.Sign() == 0 || privateKey.Cmp(fr.Modulus()) >= 0 {
+		return nil, fmt.Errorf("invalid BLS private key: out of the BN254 scalar field")
+	}
+	return bls.NewKeyPair(new(fr.Element).SetBigInt(privateKey)), nil
+}
+
+// writeNewKeystore writes a keystore to path with write, failing if there's already a file at path
+func writeNewKeystore(path string, write func(path string) error) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%w: %s", ErrKeystoreExists, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return replaceKeystore(path, write)
+}
+
+// replaceKeystore writes a keystore to a temporary file with write, and then renames it to path
+func replaceKeystore(path string, write func(path string) error) error {
+	tmpPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := write(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
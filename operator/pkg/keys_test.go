@@ -0,0 +1,112 @@
+package operator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	sdkecdsa "github.com/Layr-Labs/eigensdk-go/crypto/ecdsa"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestImportBlsKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "bls.json")
+	keyPair, err := ImportBlsKeystore(path, "0x0a", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := bls.NewKeyPairFromString("10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keyPair.PubKey.Equal(expected.PubKey.G1Affine) {
+		t.Errorf("expected the imported key to be 10")
+	}
+
+	stored, err := bls.ReadPrivateKeyFromFile(path, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.PrivKey.Equal(expected.PrivKey) {
+		t.Errorf("expected the keystore to store the imported key")
+	}
+
+	if _, err := ImportBlsKeystore(path, "0b", "password"); !errors.Is(err, ErrKeystoreExists) {
+		t.Errorf("expected the existing keystore not to be overwritten, got %v", err)
+	}
+	for _, invalid := range []string{"0x", "zz", "0", "30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001"} {
+		if _, err := ImportBlsKeystore(filepath.Join(t.TempDir(), "bls.json"), invalid, ""); err == nil {
+			t.Errorf("expected %q not to be imported", invalid)
+		}
+	}
+}
+
+func TestChangeBlsKeystorePassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bls.json")
+	keyPair, err := GenerateBlsKeystore(path, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ChangeBlsKeystorePassword(path, "wrong", "new"); err == nil {
+		t.Fatal("expected the password not to change with a wrong password")
+	}
+	if _, err := ChangeBlsKeystorePassword(path, "old", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bls.ReadPrivateKeyFromFile(path, "old"); err == nil {
+		t.Error("expected the old password not to decrypt the keystore")
+	}
+	stored, err := bls.ReadPrivateKeyFromFile(path, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.PrivKey.Equal(keyPair.PrivKey) {
+		t.Error("expected the keystore to keep its key")
+	}
+	assertNoTemporaryKeystore(t, path)
+}
+
+func TestEcdsaKeystore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ecdsa.json")
+	privateKeyHex := "0x2a871d0798f97d79848a013d4936a73bf4cc922c825d33c1cf7073dff6d409c6"
+	privateKey, err := ImportEcdsaKeystore(path, privateKeyHex, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := sdkecdsa.GetAddressFromKeyStoreFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if address != crypto.PubkeyToAddress(privateKey.PublicKey) {
+		t.Errorf("expected the keystore address to be %s, got %s", crypto.PubkeyToAddress(privateKey.PublicKey), address)
+	}
+	if _, err := GenerateEcdsaKeystore(path, "old"); !errors.Is(err, ErrKeystoreExists) {
+		t.Errorf("expected the existing keystore not to be overwritten, got %v", err)
+	}
+
+	if _, err := ChangeEcdsaKeystorePassword(path, "old", "new"); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := sdkecdsa.ReadKey(path, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Equal(privateKey) {
+		t.Error("expected the keystore to keep its key")
+	}
+	assertNoTemporaryKeystore(t, path)
+}
+
+func assertNoTemporaryKeystore(t *testing.T, path string) {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the keystore in its directory, got %d files", len(entries))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
@@ -16,28 +17,21 @@ import (
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/urfave/cli/v2"
-	"github.com/yetanotherco/aligned_layer/operator/risc_zero"
-	"github.com/yetanotherco/aligned_layer/operator/risc_zero_old"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/yetanotherco/aligned_layer/metrics"
 
-	"github.com/yetanotherco/aligned_layer/operator/sp1"
-	"github.com/yetanotherco/aligned_layer/operator/sp1_old"
-
 	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
 	"github.com/Layr-Labs/eigensdk-go/logging"
 	eigentypes "github.com/Layr-Labs/eigensdk-go/types"
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/backend/plonk"
-	"github.com/consensys/gnark/backend/witness"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/yetanotherco/aligned_layer/common"
 	servicemanager "github.com/yetanotherco/aligned_layer/contracts/bindings/AlignedLayerServiceManager"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/core/utils"
 
 	"github.com/yetanotherco/aligned_layer/core/config"
 )
@@ -49,25 +43,56 @@ type Operator struct {
 	Timeout                   time.Duration
 	KeyPair                   *bls.KeyPair
 	OperatorId                eigentypes.OperatorId
+	blsSigner                 BlsSigner
 	avsSubscriber             chainio.AvsSubscriber
 	avsReader                 chainio.AvsReader
 	NewTaskCreatedChanV2      chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2
 	NewTaskCreatedChanV3      chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3
 	Logger                    logging.Logger
-	aggRpcClient              AggregatorRpcClient
+	aggregators               *AggregatorFanOut
 	metricsReg                *prometheus.Registry
 	metrics                   *metrics.Metrics
-	lastProcessedBatch        OperatorLastProcessedBatch
 	lastProcessedBatchLogFile string
+	taskJournal               *TaskJournal
+	tasksInFlight             map[[32]byte]struct{}
+	tasksInFlightMutex        sync.Mutex
+	verifiers                 *VerifierRegistry
+	verdicts                  *VerdictStore
+	scheduler                 *VerificationScheduler
+	verificationCache         *VerificationCache
+	verificationTimeouts      *VerificationTimeouts
+	verifierWorkers           *VerifierWorkerPool
+	batchMirrors              *BatchMirrors
+	// batchCache is nil if `batch_cache.dir` is not set
+	batchCache *BatchCache
+	health     *operatorHealth
+	// shuttingDown stops startTask from claiming new batches, guarded by tasksInFlightMutex
+	shuttingDown bool
+	// tasks tracks the goroutines handling batches, to wait for them on shutdown
+	tasks sync.WaitGroup
 	//Socket  string
 	//Timeout time.Duration
 }
 
+// Default policy of the batch fetchers, see `batch_fetchers` in the operator config
 const (
 	BatchDownloadTimeout    = 1 * time.Minute
 	BatchDownloadMaxRetries = 3
 	BatchDownloadRetryDelay = 5 * time.Second
-	UnverifiedBatchOffset   = 100
+)
+
+const (
+	// UnverifiedBatchOffset is only used to migrate from the last processed batch file to the task journal
+	UnverifiedBatchOffset = 100
+	// BatchVerificationTimeout bounds the verification of a whole batch, on top of the per-proof deadlines
+	BatchVerificationTimeout = 10 * time.Minute
+	// BatchRespondedPollInterval is how often a batch under verification is checked for being already responded
+	BatchRespondedPollInterval = 12 * time.Second
+	// ShutdownTimeout bounds how long the operator keeps handling the batches in flight once it's asked to stop.
+	// The batches still in flight are then cancelled and resumed from the task journal on the next start.
+	ShutdownTimeout = 1 * time.Minute
+	// ShutdownCancelTimeout is how long the operator waits for the batches cancelled after ShutdownTimeout to return
+	ShutdownCancelTimeout = 10 * time.Second
 )
 
 func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, error) {
@@ -93,23 +118,137 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 	newTaskCreatedChanV2 := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2)
 	newTaskCreatedChanV3 := make(chan *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3)
 
-	rpcClient, err := NewAggregatorRpcClient(configuration.Operator.AggregatorServerIpPortAddress, logger)
+	aggregators, err := newAggregatorFanOutFromConfig(configuration, logger)
 	if err != nil {
 		return nil, fmt.Errorf("could not create RPC client: %s. Is aggregator running?", err)
 	}
 
-	operatorId := eigentypes.OperatorIdFromKeyPair(configuration.BlsConfig.KeyPair)
+	blsSigner, err := NewBlsSignerFromConfig(context.Background(), configuration.BlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not create BLS signer: %w", err)
+	}
+	operatorId := eigentypes.OperatorIdFromG1Pubkey(blsSigner.PubKeyG1())
 	address := configuration.Operator.Address
 	lastProcessedBatchLogFile := configuration.Operator.LastProcessedBatchFilePath
+	taskJournalFile := configuration.Operator.TaskJournalFilePath
 
-	if lastProcessedBatchLogFile == "" {
-		logger.Fatalf("Config file field: `last_processed_batch_filepath` not provided.")
+	if taskJournalFile == "" {
+		if lastProcessedBatchLogFile == "" {
+			logger.Fatalf("Config file field: `task_journal_filepath` not provided.")
+		}
+		taskJournalFile = filepath.Join(filepath.Dir(lastProcessedBatchLogFile), DefaultTaskJournalFileName)
+		logger.Warnf("Config file field: `task_journal_filepath` not provided, using %s", taskJournalFile)
+	}
+
+	taskJournal, err := OpenTaskJournal(taskJournalFile, configuration.Operator.UnfinishedTaskRetention)
+	if err != nil {
+		logger.Fatalf("Error while loading task journal: %v. This is probably related to the `task_journal_filepath` field passed in the config file", err)
 	}
 
 	// Metrics
 	reg := prometheus.NewRegistry()
 	operatorMetrics := metrics.NewMetrics(configuration.Operator.MetricsIpPortAddress, reg, logger)
 
+	if configuration.Operator.VerifyingKeyCacheMaxBytes > 0 {
+		DefaultVerifyingKeyCache().SetMaxBytes(configuration.Operator.VerifyingKeyCacheMaxBytes)
+	}
+
+	verifiers := DefaultVerifierRegistry()
+	var verifierWorkers *VerifierWorkerPool
+	if configuration.Operator.VerificationSandbox {
+		verifierWorkers, err = sandboxVerifiers(verifiers, configuration, logger)
+		if err != nil {
+			return nil, err
+		}
+		verifierWorkers.OnRestart(operatorMetrics.IncOperatorVerifierWorkerRestarts)
+	}
+	err = verifiers.InitAll(logger)
+	if err != nil {
+		return nil, err
+	}
+	selfTestFailures := verifiers.SelfTestAll()
+	for provingSystem, err := range selfTestFailures {
+		logger.Warnf("Verifier self test failed for proving system %d: %v", provingSystem, err)
+	}
+	health := newOperatorHealth()
+	health.setSelfTest(verifiers.ProvingSystems(), selfTestFailures)
+	health.recordProcessedBlock(taskJournal.LatestFinishedBlock())
+
+	scheduler, err := newVerificationSchedulerFromConfig(configuration)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.OnQueueDepthChange(func(provingSystem common.ProvingSystemId, depth int) {
+		provingSystemName, _ := common.ProvingSystemIdToString(provingSystem)
+		operatorMetrics.SetOperatorVerificationQueueDepth(provingSystemName, depth)
+	})
+	scheduler.OnAbandonedChange(operatorMetrics.SetOperatorAbandonedVerifiers)
+	scheduler.OnAbandon(func(provingSystem common.ProvingSystemId, holdsSlot bool) {
+		if holdsSlot {
+			logger.Warnf("%s verifier still running after its verification timed out, holding its slot until it returns", provingSystem)
+			return
+		}
+		logger.Errorf("%s verifier still running after its verification timed out, and too many abandoned verifiers hold a slot. "+
+			"Releasing its slot; enable the verification sandbox to kill stuck verifiers", provingSystem)
+	})
+
+	verificationTimeouts, err := newVerificationTimeoutsFromConfig(configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	batchMirrors, err := newBatchMirrorsFromConfig(configuration, logger)
+	if err != nil {
+		return nil, err
+	}
+	batchMirrors.OnDownload(func(mirror string, result string, latency time.Duration) {
+		operatorMetrics.IncOperatorBatchMirrorDownload(mirror, result)
+		operatorMetrics.ObserveOperatorBatchMirrorDownloadLatency(mirror, latency)
+	})
+	aggregators.OnDelivery(func(aggregator string, result string, latency time.Duration) {
+		operatorMetrics.IncOperatorAggregatorDelivery(aggregator, result)
+		operatorMetrics.ObserveOperatorAggregatorDeliveryLatency(aggregator, latency)
+	})
+	aggregators.OnReconnect(func(aggregator string, err error) {
+		result := "ok"
+		if err != nil {
+			result = "error"
+		}
+		operatorMetrics.IncOperatorAggregatorReconnection(aggregator, result)
+	})
+
+	var batchCache *BatchCache
+	if batchCacheConfig := configuration.Operator.BatchCache; batchCacheConfig.Dir != "" {
+		batchCache, err = NewBatchCache(batchCacheConfig.Dir, batchCacheConfig.MaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		batchCache.OnLookup(func(hit bool) {
+			if hit {
+				operatorMetrics.IncOperatorBatchCacheHits()
+			} else {
+				operatorMetrics.IncOperatorBatchCacheMisses()
+			}
+		})
+		batchCache.OnStore(func(evicted int, size int64) {
+			operatorMetrics.AddOperatorBatchCacheEvictions(evicted)
+			operatorMetrics.SetOperatorBatchCacheSize(size)
+		})
+		operatorMetrics.SetOperatorBatchCacheSize(batchCache.Size())
+	}
+
+	verificationCache, err := NewVerificationCache(configuration.Operator.VerificationCacheSize, configuration.Operator.VerificationCacheDir, configuration.Operator.VerificationCacheDiskEntries)
+	if err != nil {
+		return nil, err
+	}
+	verificationCache.OnLookup(func(hit bool, tier string) {
+		if hit {
+			operatorMetrics.IncOperatorVerificationCacheHits(tier)
+		} else {
+			operatorMetrics.IncOperatorVerificationCacheMisses()
+		}
+	})
+
 	operator := &Operator{
 		Config:                    configuration,
 		Logger:                    logger,
@@ -118,102 +257,157 @@ func NewOperatorFromConfig(configuration config.OperatorConfig) (*Operator, erro
 		Address:                   address,
 		NewTaskCreatedChanV2:      newTaskCreatedChanV2,
 		NewTaskCreatedChanV3:      newTaskCreatedChanV3,
-		aggRpcClient:              *rpcClient,
+		aggregators:               aggregators,
 		OperatorId:                operatorId,
+		blsSigner:                 blsSigner,
 		metricsReg:                reg,
 		metrics:                   operatorMetrics,
 		lastProcessedBatchLogFile: lastProcessedBatchLogFile,
-		lastProcessedBatch: OperatorLastProcessedBatch{
-			BlockNumber:        0,
-			batchProcessedChan: make(chan uint32),
-		},
+		taskJournal:               taskJournal,
+		tasksInFlight:             make(map[[32]byte]struct{}),
+		verifiers:                 verifiers,
+		verdicts:                  NewVerdictStore(MaxStoredBatchVerdicts),
+		scheduler:                 scheduler,
+		verificationCache:         verificationCache,
+		verificationTimeouts:      verificationTimeouts,
+		verifierWorkers:           verifierWorkers,
+		batchMirrors:              batchMirrors,
+		batchCache:                batchCache,
+		health:                    health,
 
 		// Timeout
 		// Socket
 	}
 
-	err = operator.LoadLastProcessedBatch()
-	if err != nil {
-		logger.Fatalf("Error while loading last process batch: %v. This is probably related to the `last_processed_batch_filepath` field passed in the config file", err)
-	}
-
 	return operator, nil
 }
 
-func (o *Operator) SubscribeToNewTasksV2() (chan error, error) {
-	return o.avsSubscriber.SubscribeToNewTasksV2(o.NewTaskCreatedChanV2)
-}
-
-func (o *Operator) SubscribeToNewTasksV3() (chan error, error) {
-	return o.avsSubscriber.SubscribeToNewTasksV3(o.NewTaskCreatedChanV3)
+func (o *Operator) SubscribeToNewTasksV2(ctx context.Context) (chan error, error) {
+	return o.avsSubscriber.SubscribeToNewTasksV2(ctx, o.NewTaskCreatedChanV2)
 }
 
-type OperatorLastProcessedBatch struct {
-	BlockNumber        uint32      `json:"block_number"`
-	batchProcessedChan chan uint32 `json:"-"`
+func (o *Operator) SubscribeToNewTasksV3(ctx context.Context) (chan error, error) {
+	return o.avsSubscriber.SubscribeToNewTasksV3(ctx, o.NewTaskCreatedChanV3)
 }
 
-func (o *Operator) LoadLastProcessedBatch() error {
-	// check if the directory exist
-	folderPath := filepath.Dir(o.lastProcessedBatchLogFile)
-	_, err := os.Stat(folderPath)
-
-	if os.IsNotExist(err) {
-		return err
+// startTask claims a batch for handling, recording it in the task journal if it's new.
+// Returns false if the batch is already being handled or was finished before,
+// which happens when an event is received twice or is also resumed from the journal,
+// or if the operator is shutting down.
+func (o *Operator) startTask(record TaskRecord) (TaskRecord, bool) {
+	o.tasksInFlightMutex.Lock()
+	defer o.tasksInFlightMutex.Unlock()
+
+	if o.shuttingDown {
+		o.Logger.Infof("Not handling batch 0x%s, the operator is shutting down", hex.EncodeToString(record.BatchMerkleRoot[:]))
+		return record, false
 	}
 
-	file, err := os.ReadFile(o.lastProcessedBatchLogFile)
-
-	// if the file does not exist, we don't return an err, as it will get created later
-	// that is why we check of the directory exist in the first place
-	if err != nil {
-		return nil
+	if _, ok := o.tasksInFlight[record.BatchIdentifierHash]; ok {
+		return record, false
 	}
-
-	err = json.Unmarshal(file, &o.lastProcessedBatch)
-
-	if err != nil {
-		return err
+	if journaled, ok := o.taskJournal.Get(record.BatchIdentifierHash); ok {
+		if journaled.Stage.IsFinal() {
+			o.Logger.Infof("Batch 0x%s was already handled, stage: %s", hex.EncodeToString(record.BatchMerkleRoot[:]), journaled.Stage)
+			return journaled, false
+		}
+		record = journaled
+	} else {
+		record.Stage = TaskStageReceived
+		if err := o.taskJournal.Put(record); err != nil {
+			o.Logger.Errorf("Could not record task in journal: %v", err)
+		}
 	}
 
-	return nil
+	o.tasksInFlight[record.BatchIdentifierHash] = struct{}{}
+	return record, true
 }
 
-func (o *Operator) UpdateLastProcessBatch(blockNumber uint32) error {
-	// we want to store the latest block number
-	if blockNumber < o.lastProcessedBatch.BlockNumber {
-		return nil
-	}
-
-	o.lastProcessedBatch.BlockNumber = blockNumber
+func (o *Operator) finishTask(batchIdentifierHash [32]byte) {
+	o.tasksInFlightMutex.Lock()
+	defer o.tasksInFlightMutex.Unlock()
 
-	// write to a file so it can be recovered in case of operator outage
-	json, err := json.Marshal(o.lastProcessedBatch)
+	delete(o.tasksInFlight, batchIdentifierHash)
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to marshal batch: %v", err)
+// advanceTask moves the journal record of a batch to stage.
+// If taskErr is not nil, the error is recorded and the stage is left as is.
+func (o *Operator) advanceTask(batchIdentifierHash [32]byte, stage TaskStage, taskErr error) {
+	record, ok := o.taskJournal.Get(batchIdentifierHash)
+	if !ok {
+		return
 	}
+	if taskErr != nil {
+		record.Error = taskErr.Error()
+	} else {
+		record.Stage = stage
+		record.Error = ""
+	}
+	if err := o.taskJournal.Put(record); err != nil {
+		o.Logger.Errorf("Could not update task journal: %v", err)
+	}
+	if taskErr == nil && stage.IsFinal() && o.health != nil {
+		o.health.recordProcessedBlock(record.BlockNumber)
+	}
+}
 
-	err = os.WriteFile(o.lastProcessedBatchLogFile, json, os.ModePerm)
+// finishBatchVerification journals and records the result of verifyBatch, which ran with verifyCtx.
+// A batch responded on chain in the meantime is journaled as responded: its cancelled proofs didn't fail,
+// so no rejection is recorded and ErrBatchAlreadyResponded is returned.
+func (o *Operator) finishBatchVerification(ctx context.Context, verifyCtx context.Context, batchIdentifierHash [32]byte, batchMerkleRoot [32]byte, senderAddress [20]byte, verdicts []ProofVerdict, err error) error {
+	if errors.Is(context.Cause(verifyCtx), ErrBatchAlreadyResponded) {
+		o.advanceTask(batchIdentifierHash, TaskStageResponded, nil)
+		return ErrBatchAlreadyResponded
+	}
+	if err == nil && ctx.Err() != nil {
+		// The proofs cancelled by the shutdown didn't fail, the batch is verified again once resumed
+		err = context.Cause(ctx)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to write to file: %v", err)
+		o.Logger.Errorf("Could not get proofs from data service: %v", err)
+		o.advanceTask(batchIdentifierHash, TaskStageDownloaded, err)
+		return err
 	}
+	o.advanceTask(batchIdentifierHash, TaskStageDownloaded, nil)
 
-	o.Logger.Infof("Updated latest block json file, new block: %v", blockNumber)
+	err = o.recordBatchVerdicts(batchMerkleRoot, senderAddress, verdicts)
+	var invalidBatchErr *InvalidBatchError
+	if errors.As(err, &invalidBatchErr) {
+		o.metrics.IncOperatorBatchesRejected()
+		o.advanceTask(batchIdentifierHash, TaskStageRejected, nil)
+		return err
+	}
+	o.advanceTask(batchIdentifierHash, TaskStageVerified, err)
+	return err
+}
 
-	return nil
+// goTask runs f in a goroutine tracked for the shutdown of the operator
+func (o *Operator) goTask(f func()) {
+	o.tasks.Add(1)
+	go func() {
+		defer o.tasks.Done()
+		f()
+	}()
 }
 
+// Start handles new batches until ctx is done. Then it stops taking new batches
+// and waits for the ones in flight, see shutdown.
 func (o *Operator) Start(ctx context.Context) error {
-	subV2, err := o.SubscribeToNewTasksV2()
+	// The batches in flight outlive ctx, so they can finish while shutting down
+	tasksCtx, cancelTasks := context.WithCancelCause(context.WithoutCancel(ctx))
+	defer cancelTasks(nil)
+
+	subV2, err := o.SubscribeToNewTasksV2(ctx)
 	if err != nil {
 		log.Fatal("Could not subscribe to new tasks")
 	}
+	o.health.setSubscription(SubscriptionNewBatchV2, nil)
 
-	subV3, err := o.SubscribeToNewTasksV3()
+	subV3, err := o.SubscribeToNewTasksV3(ctx)
 	if err != nil {
 		log.Fatal("Could not subscribe to new tasks")
 	}
+	o.health.setSubscription(SubscriptionNewBatchV3, nil)
 
 	var metricsErrChan <-chan error
 	if o.Config.Operator.EnableMetrics {
@@ -222,77 +416,189 @@ func (o *Operator) Start(ctx context.Context) error {
 		metricsErrChan = make(chan error, 1)
 	}
 
-	go o.ProcessMissedBatchesWhileOffline()
+	var statusErrChan <-chan error
+	if o.Config.Operator.StatusIpPortAddress != "" {
+		statusErrChan = o.StartStatusServer(ctx, o.Config.Operator.StatusIpPortAddress)
+	} else {
+		statusErrChan = make(chan error, 1)
+	}
+	heartbeat := time.NewTicker(StatusHeartbeatInterval)
+	defer heartbeat.Stop()
+	o.health.recordHeartbeat()
+
+	o.goTask(func() { o.ProcessMissedBatchesWhileOffline(tasksCtx) })
 
 	for {
 		select {
-		case <-context.Background().Done():
-			o.Logger.Info("Operator shutting down...")
+		case <-ctx.Done():
+			o.shutdown(cancelTasks)
 			return nil
 		case err := <-metricsErrChan:
 			o.Logger.Errorf("Metrics server failed", "err", err)
+		case err := <-statusErrChan:
+			o.Logger.Errorf("Status server failed", "err", err)
+		case <-heartbeat.C:
+			o.health.recordHeartbeat()
 		case err := <-subV2:
 			o.Logger.Infof("Error in websocket subscription", "err", err)
-			subV2, err = o.SubscribeToNewTasksV2()
+			o.health.setSubscription(SubscriptionNewBatchV2, err)
+			subV2, err = o.SubscribeToNewTasksV2(ctx)
 			if err != nil {
 				o.Logger.Fatal("Could not subscribe to new tasks V2")
 			}
+			o.health.setSubscription(SubscriptionNewBatchV2, nil)
 		case err := <-subV3:
 			o.Logger.Infof("Error in websocket subscription", "err", err)
-			subV3, err = o.SubscribeToNewTasksV3()
+			o.health.setSubscription(SubscriptionNewBatchV3, err)
+			subV3, err = o.SubscribeToNewTasksV3(ctx)
 			if err != nil {
 				o.Logger.Fatal("Could not subscribe to new tasks V3")
 			}
+			o.health.setSubscription(SubscriptionNewBatchV3, nil)
 		case newBatchLogV2 := <-o.NewTaskCreatedChanV2:
-			go o.handleNewBatchLogV2(newBatchLogV2)
+			o.health.recordBlock(newBatchLogV2.Raw.BlockNumber)
+			o.goTask(func() { o.handleNewBatchLogV2(tasksCtx, newBatchLogV2) })
 		case newBatchLogV3 := <-o.NewTaskCreatedChanV3:
-			go o.handleNewBatchLogV3(newBatchLogV3)
-		case blockNumber := <-o.lastProcessedBatch.batchProcessedChan:
-			err = o.UpdateLastProcessBatch(blockNumber)
-			if err != nil {
-				o.Logger.Errorf("Error while updating last process batch", "err", err)
-			}
+			o.health.recordBlock(newBatchLogV3.Raw.BlockNumber)
+			o.goTask(func() { o.handleNewBatchLogV3(tasksCtx, newBatchLogV3) })
 		}
 	}
 }
 
-// Here we query all the batches that have not yet been verified starting from
-// the latest verified batch by the operator. We also read from the previous
-// `UnverifiedBatchOffset` blocks, because as batches are processed in parallel, there could be
-// unverified batches slightly before the latest verified batch
-func (o *Operator) ProcessMissedBatchesWhileOffline() {
-	// this is the default value
-	// and it means there was no file so no batches have been verified
-	if o.lastProcessedBatch.BlockNumber == 0 {
-		o.Logger.Info("Not continuing with missed batch processing, as operator hasn't verified anything yet...")
+// shutdown stops claiming new batches and waits up to ShutdownTimeout for the ones in flight.
+// The batches still in flight are then cancelled with ErrOperatorShutdown. Their progress is kept
+// in the task journal, so they are resumed on the next start.
+// Once the batches are done, the verifier workers, the task journal and the aggregator connections are closed.
+func (o *Operator) shutdown(cancelTasks context.CancelCauseFunc) {
+	defer o.close()
+
+	o.tasksInFlightMutex.Lock()
+	o.shuttingDown = true
+	batchesInFlight := len(o.tasksInFlight)
+	o.tasksInFlightMutex.Unlock()
+
+	o.Logger.Infof("Operator shutting down, waiting for %d batches in flight", batchesInFlight)
+	if utils.WaitTimeout(&o.tasks, ShutdownTimeout) {
+		o.Logger.Info("Operator shut down")
 		return
 	}
 
-	o.Logger.Info("Getting missed tasks")
+	o.tasksInFlightMutex.Lock()
+	batchesInFlight = len(o.tasksInFlight)
+	o.tasksInFlightMutex.Unlock()
+	o.Logger.Warnf("%d batches still in flight after %v, cancelling them to resume them on the next start", batchesInFlight, ShutdownTimeout)
+	cancelTasks(ErrOperatorShutdown)
+	if !utils.WaitTimeout(&o.tasks, ShutdownCancelTimeout) {
+		o.Logger.Error("Batches in flight did not stop after being cancelled, exiting anyway")
+	}
+}
 
-	// this check is necessary for overflows as go does not do saturating arithmetic
-	var fromBlock uint64
-	if o.lastProcessedBatch.BlockNumber < UnverifiedBatchOffset {
-		fromBlock = 0
-	} else {
-		fromBlock = uint64(o.lastProcessedBatch.BlockNumber - UnverifiedBatchOffset)
+// close releases the resources held by the operator
+func (o *Operator) close() {
+	if o.verifierWorkers != nil {
+		o.verifierWorkers.Close()
+	}
+	if err := o.taskJournal.Close(); err != nil {
+		o.Logger.Error("Could not close the task journal", "err", err)
+	}
+	if err := o.aggregators.Close(); err != nil {
+		o.Logger.Warn("Could not close the aggregator connections", "err", err)
 	}
+}
 
-	logs, err := o.avsReader.GetNotRespondedTasksFrom(fromBlock)
-	if err != nil {
+// ProcessMissedBatchesWhileOffline resumes the batches left unfinished in the task journal
+// and then handles the batches created since the latest journaled one that are not responded yet.
+// Batches already responded on chain are marked as such instead of being resumed.
+// The batches are handled with ctx.
+func (o *Operator) ProcessMissedBatchesWhileOffline(ctx context.Context) {
+	unfinished := o.taskJournal.Unfinished()
+	o.Logger.Infof("Resuming %d unfinished batches from the task journal", len(unfinished))
+	for _, record := range unfinished {
+		if ctx.Err() != nil {
+			return
+		}
+		responded, err := o.avsReader.IsBatchResponded(record.BatchIdentifierHash)
+		if err != nil {
+			o.Logger.Errorf("Could not check if batch 0x%s was responded: %v", hex.EncodeToString(record.BatchMerkleRoot[:]), err)
+		} else if responded {
+			o.advanceTask(record.BatchIdentifierHash, TaskStageResponded, nil)
+			continue
+		}
+		o.goTask(func() { o.resumeTask(ctx, record) })
+	}
+
+	fromBlock, ok := o.missedBatchesFromBlock()
+	if !ok {
+		o.Logger.Info("Not continuing with missed batch processing, as operator hasn't verified anything yet...")
 		return
 	}
-	o.Logger.Infof(fmt.Sprintf("Missed tasks retrieved, total tasks to process: %v", len(logs)))
 
-	if len(logs) == 0 {
+	o.Logger.Info("Getting missed tasks")
+	logs, err := o.avsReader.GetNotRespondedTasksFrom(fromBlock)
+	if err != nil {
+		o.Logger.Errorf("Could not get missed tasks: %v", err)
 		return
 	}
+	o.Logger.Infof("Missed tasks retrieved, total tasks to process: %v", len(logs))
 
-	o.Logger.Infof("Starting to verify missed batches while offline")
+	// Batches already in the journal are skipped by startTask
 	for _, logEntry := range logs {
-		go o.handleNewBatchLogV3(&logEntry)
+		o.goTask(func() { o.handleNewBatchLogV3(ctx, &logEntry) })
 	}
-	o.Logger.Info("Finished verifying all batches missed while offline")
+}
+
+// missedBatchesFromBlock returns the block to look for missed batches from: the latest block in the task journal,
+// or, the first time the operator runs with a journal, the block of the legacy last processed batch file
+// minus `UnverifiedBatchOffset`, as it only kept the latest batch processed.
+// Returns false if neither exists, i.e. the operator never processed a batch.
+func (o *Operator) missedBatchesFromBlock() (uint64, bool) {
+	if latestBlock := o.taskJournal.LatestBlock(); latestBlock != 0 {
+		return latestBlock, true
+	}
+	if o.lastProcessedBatchLogFile == "" {
+		return 0, false
+	}
+
+	file, err := os.ReadFile(o.lastProcessedBatchLogFile)
+	if err != nil {
+		return 0, false
+	}
+	var lastProcessedBatch struct {
+		BlockNumber uint64 `json:"block_number"`
+	}
+	if err := json.Unmarshal(file, &lastProcessedBatch); err != nil || lastProcessedBatch.BlockNumber == 0 {
+		return 0, false
+	}
+
+	o.Logger.Infof("Task journal is empty, looking for missed batches from the last processed batch file")
+	// this check is necessary for overflows as go does not do saturating arithmetic
+	if lastProcessedBatch.BlockNumber < UnverifiedBatchOffset {
+		return 0, true
+	}
+	return lastProcessedBatch.BlockNumber - UnverifiedBatchOffset, true
+}
+
+// resumeTask handles again the event a journal record was created from
+func (o *Operator) resumeTask(ctx context.Context, record TaskRecord) {
+	raw := ethtypes.Log{BlockNumber: record.BlockNumber}
+	if record.EventVersion == 2 {
+		o.handleNewBatchLogV2(ctx, &servicemanager.ContractAlignedLayerServiceManagerNewBatchV2{
+			BatchMerkleRoot:  record.BatchMerkleRoot,
+			SenderAddress:    record.SenderAddress,
+			TaskCreatedBlock: record.TaskCreatedBlock,
+			BatchDataPointer: record.BatchDataPointer,
+			Raw:              raw,
+		})
+		return
+	}
+	o.handleNewBatchLogV3(ctx, &servicemanager.ContractAlignedLayerServiceManagerNewBatchV3{
+		BatchMerkleRoot:       record.BatchMerkleRoot,
+		SenderAddress:         record.SenderAddress,
+		TaskCreatedBlock:      record.TaskCreatedBlock,
+		BatchDataPointer:      record.BatchDataPointer,
+		RespondToTaskFeeLimit: record.RespondToTaskFeeLimit,
+		Raw:                   raw,
+	})
 }
 
 // Currently, Operator can handle NewBatchV2 and NewBatchV3 events.
@@ -305,324 +611,378 @@ func (o *Operator) ProcessMissedBatchesWhileOffline() {
 // different events enables the smooth operator upgradeability
 
 // Process of handling batches from V2 events:
-func (o *Operator) handleNewBatchLogV2(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) {
-	var err error
-	defer func() { o.afterHandlingBatchV2(newBatchLog, err == nil) }()
-
+func (o *Operator) handleNewBatchLogV2(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) {
 	o.Logger.Info("Received new batch log V2")
-	err = o.ProcessNewBatchLogV2(newBatchLog)
-	if err != nil {
-		o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
+	record, ok := o.startTask(TaskRecord{
+		BatchIdentifierHash: computeBatchIdentifierHash(newBatchLog.BatchMerkleRoot, newBatchLog.SenderAddress),
+		BatchMerkleRoot:     newBatchLog.BatchMerkleRoot,
+		SenderAddress:       newBatchLog.SenderAddress,
+		BatchDataPointer:    newBatchLog.BatchDataPointer,
+		TaskCreatedBlock:    newBatchLog.TaskCreatedBlock,
+		BlockNumber:         newBatchLog.Raw.BlockNumber,
+		EventVersion:        2,
+	})
+	if !ok {
 		return
 	}
+	defer o.finishTask(record.BatchIdentifierHash)
 
-	batchIdentifier := append(newBatchLog.BatchMerkleRoot[:], newBatchLog.SenderAddress[:]...)
-	var batchIdentifierHash = *(*[32]byte)(crypto.Keccak256(batchIdentifier))
-	responseSignature := o.SignTaskResponse(batchIdentifierHash)
-	o.Logger.Debugf("responseSignature about to send: %x", responseSignature)
-
-	signedTaskResponse := types.SignedTaskResponse{
-		BatchIdentifierHash: batchIdentifierHash,
-		BatchMerkleRoot:     newBatchLog.BatchMerkleRoot,
-		SenderAddress:       newBatchLog.SenderAddress,
-		BlsSignature:        *responseSignature,
-		OperatorId:          o.OperatorId,
+	if record.Stage == TaskStageReceived || record.Stage == TaskStageDownloaded {
+		err := o.ProcessNewBatchLogV2(ctx, newBatchLog)
+		if errors.Is(err, ErrBatchAlreadyResponded) {
+			o.Logger.Infof("batch %x was responded before it was verified", newBatchLog.BatchMerkleRoot)
+			return
+		}
+		if err != nil {
+			o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
+			return
+		}
 	}
-	o.Logger.Infof("Signed Task Response to send: BatchIdentifierHash=%s, BatchMerkleRoot=%s, SenderAddress=%s",
-		hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]),
-		hex.EncodeToString(signedTaskResponse.BatchMerkleRoot[:]),
-		hex.EncodeToString(signedTaskResponse.SenderAddress[:]),
-	)
 
-	o.aggRpcClient.SendSignedTaskResponseToAggregator(&signedTaskResponse)
+	o.signAndSendTaskResponse(ctx, record)
 }
-func (o *Operator) ProcessNewBatchLogV2(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) error {
+func (o *Operator) ProcessNewBatchLogV2(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2) error {
 
 	o.Logger.Info("Received new batch with proofs to verify",
 		"batch merkle root", "0x"+hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
 		"sender address", "0x"+hex.EncodeToString(newBatchLog.SenderAddress[:]),
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), BatchDownloadTimeout)
-	defer cancel()
-
-	verificationDataBatch, err := o.getBatchFromDataService(ctx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, BatchDownloadMaxRetries, BatchDownloadRetryDelay)
-	if err != nil {
-		o.Logger.Errorf("Could not get proofs from S3 bucket: %v", err)
-		return err
-	}
-
-	verificationDataBatchLen := len(verificationDataBatch)
-	results := make(chan bool, verificationDataBatchLen)
-	var wg sync.WaitGroup
-	wg.Add(verificationDataBatchLen)
+	batchIdentifierHash := computeBatchIdentifierHash(newBatchLog.BatchMerkleRoot, newBatchLog.SenderAddress)
 
 	disabledVerifiersBitmap, err := o.avsReader.DisabledVerifiers()
 	if err != nil {
 		o.Logger.Errorf("Could not check verifiers status: %s", err)
-		results <- false
+		o.advanceTask(batchIdentifierHash, TaskStageDownloaded, err)
 		return err
 	}
 
-	for _, verificationData := range verificationDataBatch {
-		go func(data VerificationData) {
-			defer wg.Done()
-			o.verify(data, disabledVerifiersBitmap, results)
-			o.metrics.IncOperatorTaskResponses()
-		}(verificationData)
-	}
+	verifyCtx, cancelVerify := o.batchVerificationContext(ctx, batchIdentifierHash)
+	defer cancelVerify()
 
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	for result := range results {
-		if !result {
-			return fmt.Errorf("invalid proof")
-		}
-	}
-
-	return nil
+	verdicts, err := o.verifyBatch(verifyCtx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, disabledVerifiersBitmap)
+	return o.finishBatchVerification(ctx, verifyCtx, batchIdentifierHash, newBatchLog.BatchMerkleRoot, newBatchLog.SenderAddress, verdicts, err)
 }
 
 // Process of handling batches from V3 events:
-func (o *Operator) handleNewBatchLogV3(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
-	var err error
-	defer func() { o.afterHandlingBatchV3(newBatchLog, err == nil) }()
+func (o *Operator) handleNewBatchLogV3(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) {
 	o.Logger.Infof("Received new batch log V3")
-	err = o.ProcessNewBatchLogV3(newBatchLog)
-	if err != nil {
-		o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
+	record, ok := o.startTask(TaskRecord{
+		BatchIdentifierHash:   computeBatchIdentifierHash(newBatchLog.BatchMerkleRoot, newBatchLog.SenderAddress),
+		BatchMerkleRoot:       newBatchLog.BatchMerkleRoot,
+		SenderAddress:         newBatchLog.SenderAddress,
+		BatchDataPointer:      newBatchLog.BatchDataPointer,
+		TaskCreatedBlock:      newBatchLog.TaskCreatedBlock,
+		RespondToTaskFeeLimit: newBatchLog.RespondToTaskFeeLimit,
+		BlockNumber:           newBatchLog.Raw.BlockNumber,
+		EventVersion:          3,
+	})
+	if !ok {
 		return
 	}
+	defer o.finishTask(record.BatchIdentifierHash)
 
-	batchIdentifier := append(newBatchLog.BatchMerkleRoot[:], newBatchLog.SenderAddress[:]...)
-	var batchIdentifierHash = *(*[32]byte)(crypto.Keccak256(batchIdentifier))
-	responseSignature := o.SignTaskResponse(batchIdentifierHash)
-	o.Logger.Debugf("responseSignature about to send: %x", responseSignature)
-
-	signedTaskResponse := types.SignedTaskResponse{
-		BatchIdentifierHash: batchIdentifierHash,
-		BatchMerkleRoot:     newBatchLog.BatchMerkleRoot,
-		SenderAddress:       newBatchLog.SenderAddress,
-		BlsSignature:        *responseSignature,
-		OperatorId:          o.OperatorId,
+	if record.Stage == TaskStageReceived || record.Stage == TaskStageDownloaded {
+		err := o.ProcessNewBatchLogV3(ctx, newBatchLog)
+		if errors.Is(err, ErrBatchAlreadyResponded) {
+			o.Logger.Infof("batch %x was responded before it was verified", newBatchLog.BatchMerkleRoot)
+			return
+		}
+		if err != nil {
+			o.Logger.Infof("batch %x did not verify. Err: %v", newBatchLog.BatchMerkleRoot, err)
+			return
+		}
 	}
-	o.Logger.Infof("Signed Task Response to send: BatchIdentifierHash=%s, BatchMerkleRoot=%s, SenderAddress=%s",
-		hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]),
-		hex.EncodeToString(signedTaskResponse.BatchMerkleRoot[:]),
-		hex.EncodeToString(signedTaskResponse.SenderAddress[:]),
-	)
 
-	o.aggRpcClient.SendSignedTaskResponseToAggregator(&signedTaskResponse)
+	o.signAndSendTaskResponse(ctx, record)
 }
-func (o *Operator) ProcessNewBatchLogV3(newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) error {
+func (o *Operator) ProcessNewBatchLogV3(ctx context.Context, newBatchLog *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3) error {
 
 	o.Logger.Info("Received new batch with proofs to verify",
 		"batch merkle root", "0x"+hex.EncodeToString(newBatchLog.BatchMerkleRoot[:]),
 		"sender address", "0x"+hex.EncodeToString(newBatchLog.SenderAddress[:]),
 	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), BatchDownloadTimeout)
-	defer cancel()
+	batchIdentifierHash := computeBatchIdentifierHash(newBatchLog.BatchMerkleRoot, newBatchLog.SenderAddress)
 
-	verificationDataBatch, err := o.getBatchFromDataService(ctx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, BatchDownloadMaxRetries, BatchDownloadRetryDelay)
-	if err != nil {
-		o.Logger.Errorf("Could not get proofs from S3 bucket: %v", err)
-		return err
-	}
-
-	verificationDataBatchLen := len(verificationDataBatch)
-	results := make(chan bool, verificationDataBatchLen)
-	var wg sync.WaitGroup
-	wg.Add(verificationDataBatchLen)
 	disabledVerifiersBitmap, err := o.avsReader.DisabledVerifiers()
 	if err != nil {
 		o.Logger.Errorf("Could not check verifiers status: %s", err)
-		results <- false
+		o.advanceTask(batchIdentifierHash, TaskStageDownloaded, err)
 		return err
 	}
-	for _, verificationData := range verificationDataBatch {
-		go func(data VerificationData) {
-			defer wg.Done()
-			o.verify(data, disabledVerifiersBitmap, results)
-			o.metrics.IncOperatorTaskResponses()
-		}(verificationData)
+
+	verifyCtx, cancelVerify := o.batchVerificationContext(ctx, batchIdentifierHash)
+	defer cancelVerify()
+
+	verdicts, err := o.verifyBatch(verifyCtx, newBatchLog.BatchDataPointer, newBatchLog.BatchMerkleRoot, disabledVerifiersBitmap)
+	return o.finishBatchVerification(ctx, verifyCtx, batchIdentifierHash, newBatchLog.BatchMerkleRoot, newBatchLog.SenderAddress, verdicts, err)
+}
+
+// signAndSendTaskResponse signs a verified batch and sends the signature to the aggregator,
+// journaling both steps
+func (o *Operator) signAndSendTaskResponse(ctx context.Context, record TaskRecord) {
+	responseSignature, err := o.SignTaskResponse(ctx, record.BatchIdentifierHash)
+	if err != nil {
+		o.Logger.Errorf("Could not sign task response: %v", err)
+		o.advanceTask(record.BatchIdentifierHash, TaskStageSigned, err)
+		return
 	}
+	o.Logger.Debugf("responseSignature about to send: %x", responseSignature)
+	o.metrics.IncOperatorBatchesSigned()
+	o.advanceTask(record.BatchIdentifierHash, TaskStageSigned, nil)
 
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+	signedTaskResponse := types.SignedTaskResponse{
+		BatchIdentifierHash: record.BatchIdentifierHash,
+		BatchMerkleRoot:     record.BatchMerkleRoot,
+		SenderAddress:       record.SenderAddress,
+		BlsSignature:        *responseSignature,
+		OperatorId:          o.OperatorId,
+	}
+	o.Logger.Infof("Signed Task Response to send: BatchIdentifierHash=%s, BatchMerkleRoot=%s, SenderAddress=%s",
+		hex.EncodeToString(signedTaskResponse.BatchIdentifierHash[:]),
+		hex.EncodeToString(signedTaskResponse.BatchMerkleRoot[:]),
+		hex.EncodeToString(signedTaskResponse.SenderAddress[:]),
+	)
 
-	for result := range results {
-		if !result {
-			return fmt.Errorf("invalid proof")
-		}
+	err = o.aggregators.SendSignedTaskResponseToAggregator(ctx, &signedTaskResponse)
+	if err != nil {
+		o.Logger.Errorf("Could not send signed task response: %v", err)
+		o.metrics.IncOperatorResponseSendFailures()
+		o.advanceTask(record.BatchIdentifierHash, TaskStageSent, err)
+		return
 	}
+	o.advanceTask(record.BatchIdentifierHash, TaskStageSent, nil)
+}
 
-	return nil
+func computeBatchIdentifierHash(batchMerkleRoot [32]byte, senderAddress [20]byte) [32]byte {
+	batchIdentifier := append(batchMerkleRoot[:], senderAddress[:]...)
+	return *(*[32]byte)(crypto.Keccak256(batchIdentifier))
 }
 
-func (o *Operator) afterHandlingBatchV2(log *servicemanager.ContractAlignedLayerServiceManagerNewBatchV2, succeeded bool) {
-	if succeeded {
-		o.lastProcessedBatch.batchProcessedChan <- uint32(log.Raw.BlockNumber)
+// batchVerificationContext returns the context to verify a batch with. It is cancelled with
+// ErrBatchVerificationTimeout after BatchVerificationTimeout, or with ErrBatchAlreadyResponded
+// once the batch is responded on chain.
+func (o *Operator) batchVerificationContext(ctx context.Context, batchIdentifierHash [32]byte) (context.Context, context.CancelFunc) {
+	timeoutCtx, cancelTimeout := context.WithTimeoutCause(ctx, BatchVerificationTimeout, ErrBatchVerificationTimeout)
+	verifyCtx, cancel := context.WithCancelCause(timeoutCtx)
+	go o.cancelWhenBatchResponded(verifyCtx, cancel, batchIdentifierHash)
+	return verifyCtx, func() {
+		cancel(nil)
+		cancelTimeout()
 	}
 }
 
-func (o *Operator) afterHandlingBatchV3(log *servicemanager.ContractAlignedLayerServiceManagerNewBatchV3, succeeded bool) {
-	if succeeded {
-		o.lastProcessedBatch.batchProcessedChan <- uint32(log.Raw.BlockNumber)
-	}
+// verifyBatch streams the batch from the data service, verifying its proofs as they are decoded,
+// and returns their verdicts in batch order once all of them finished and the merkle root of the batch matched.
+// Once a proof fails, the remaining verifications are cancelled and reported as timeouts.
+// If ctx is done, the download is cancelled too.
+func (o *Operator) verifyBatch(ctx context.Context, batchDataPointer string, expectedMerkleRoot [32]byte, disabledVerifiersBitmap *big.Int) ([]ProofVerdict, error) {
+	return o.getBatchVerdictsFromDataService(ctx, batchDataPointer, expectedMerkleRoot, disabledVerifiersBitmap, true, func(verdict ProofVerdict) {
+		o.metrics.IncOperatorTaskResponses()
+	})
 }
 
-func (o *Operator) verify(verificationData VerificationData, disabledVerifiersBitmap *big.Int, results chan bool) {
-	IsVerifierDisabled := IsVerifierDisabled(disabledVerifiersBitmap, verificationData.ProvingSystemId)
-	if IsVerifierDisabled {
-		o.Logger.Infof("Verifier %s is disabled. Returning false", verificationData.ProvingSystemId.String())
-		results <- false
-		return
+// verifyProofs runs every proof through the verification scheduler and returns their verdicts in batch order.
+// onVerdict, if not nil, is called as soon as each verdict is ready.
+func (o *Operator) verifyProofs(ctx context.Context, verificationDataBatch []VerificationData, disabledVerifiersBitmap *big.Int, onVerdict func(ProofVerdict)) []ProofVerdict {
+	verifications := o.startProofVerifications(ctx, disabledVerifiersBitmap, false, onVerdict)
+	for _, verificationData := range verificationDataBatch {
+		verifications.Submit(verificationData)
 	}
-	switch verificationData.ProvingSystemId {
-	case common.GnarkPlonkBls12_381:
-		verificationResult := o.verifyPlonkProofBLS12_381(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
-		o.Logger.Infof("PLONK BLS12-381 proof verification result: %t", verificationResult)
+	return verifications.Wait()
+}
 
-		results <- verificationResult
+// MaxPendingProofsPerBatch is the number of proofs of a batch that can wait for their verification.
+// Submitting more blocks the caller, so a batch being decoded never holds more proofs than this.
+const MaxPendingProofsPerBatch = 64
+
+// proofVerifications runs the proofs of a batch through the verification scheduler as they are submitted
+type proofVerifications struct {
+	operator                *Operator
+	ctx                     context.Context
+	cancel                  context.CancelCauseFunc
+	disabledVerifiersBitmap *big.Int
+	stopOnFailure           bool
+	onVerdict               func(ProofVerdict)
+	pending                 chan struct{}
+	wg                      sync.WaitGroup
+	verdicts                []ProofVerdict
+	mutex                   sync.Mutex
+}
 
-	case common.GnarkPlonkBn254:
-		verificationResult := o.verifyPlonkProofBN254(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
-		o.Logger.Infof("PLONK BN254 proof verification result: %t", verificationResult)
+// startProofVerifications starts verifying a batch. If stopOnFailure is set,
+// the verifications still pending once a proof fails are cancelled with ErrBatchFailed.
+func (o *Operator) startProofVerifications(ctx context.Context, disabledVerifiersBitmap *big.Int, stopOnFailure bool, onVerdict func(ProofVerdict)) *proofVerifications {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &proofVerifications{
+		operator:                o,
+		ctx:                     ctx,
+		cancel:                  cancel,
+		disabledVerifiersBitmap: disabledVerifiersBitmap,
+		stopOnFailure:           stopOnFailure,
+		onVerdict:               onVerdict,
+		pending:                 make(chan struct{}, MaxPendingProofsPerBatch),
+	}
+}
 
-		results <- verificationResult
+// Submit queues the verification of the next proof of the batch,
+// blocking while MaxPendingProofsPerBatch proofs are pending
+func (v *proofVerifications) Submit(verificationData VerificationData) {
+	v.pending <- struct{}{}
+	v.wg.Add(1)
+
+	v.mutex.Lock()
+	index := len(v.verdicts)
+	v.verdicts = append(v.verdicts, ProofVerdict{})
+	v.mutex.Unlock()
+
+	// A timed out verifier keeps running in the background, so the scheduler holds a slot until it returns,
+	// or stuck verifiers would pile up past the worker limits
+	v.operator.scheduler.SubmitVerification(verificationData.ProvingSystemId, func() <-chan struct{} {
+		defer v.wg.Done()
+		defer func() { <-v.pending }()
+
+		verdict, verifierDone := v.operator.verify(v.ctx, index, verificationData, v.disabledVerifiersBitmap)
+		v.mutex.Lock()
+		v.verdicts[index] = verdict
+		v.mutex.Unlock()
+
+		if v.stopOnFailure && !verdict.IsValid() {
+			v.cancel(ErrBatchFailed)
+		}
+		if v.onVerdict != nil {
+			v.onVerdict(verdict)
+		}
+		return verifierDone
+	})
+}
 
-	case common.Groth16Bn254:
-		verificationResult := o.verifyGroth16ProofBN254(verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey)
-		o.Logger.Infof("GROTH16 BN254 proof verification result: %t", verificationResult)
+// Wait waits for every submitted verification and returns their verdicts in batch order
+func (v *proofVerifications) Wait() []ProofVerdict {
+	v.wg.Wait()
+	v.cancel(nil)
+	return v.verdicts
+}
 
-		results <- verificationResult
+// cancelWhenBatchResponded polls the batch state until ctx is done,
+// cancelling the verification if the batch gets responded in the meantime
+func (o *Operator) cancelWhenBatchResponded(ctx context.Context, cancel context.CancelCauseFunc, batchIdentifierHash [32]byte) {
+	ticker := time.NewTicker(BatchRespondedPollInterval)
+	defer ticker.Stop()
 
-	case common.SP1:
-		verificationResult, err := sp1.VerifySp1Proof(verificationData.Proof, verificationData.VmProgramCode)
-		if !verificationResult {
-			o.Logger.Infof("SP1 proof verification failed. Trying old SP1 version...")
-			verificationResult, err = sp1_old.VerifySp1ProofOld(verificationData.Proof, verificationData.VmProgramCode)
-			if !verificationResult {
-				o.Logger.Errorf("Old SP1 proof verification failed")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			responded, err := o.avsReader.IsBatchResponded(batchIdentifierHash)
+			if err != nil {
+				o.Logger.Warnf("Could not check if batch 0x%s was responded: %v", hex.EncodeToString(batchIdentifierHash[:]), err)
+				continue
 			}
-		}
-		o.Logger.Infof("SP1 proof verification result: %t", verificationResult)
-		o.handleVerificationResult(results, verificationResult, err, "SP1 proof verification")
-
-	case common.Risc0:
-		verificationResult, err := risc_zero.VerifyRiscZeroReceipt(verificationData.Proof,
-			verificationData.VmProgramCode, verificationData.PubInput)
-		if !verificationResult {
-			o.Logger.Infof("Risc0 proof verification failed. Trying old Risc0 version...")
-			verificationResult, err = risc_zero_old.VerifyRiscZeroReceiptOld(verificationData.Proof, verificationData.VmProgramCode, verificationData.PubInput)
-			if !verificationResult {
-				o.Logger.Errorf("Old Risc0 proof verification failed")
+			if responded {
+				o.Logger.Infof("Batch 0x%s was already responded, cancelling its verification", hex.EncodeToString(batchIdentifierHash[:]))
+				cancel(ErrBatchAlreadyResponded)
+				return
 			}
 		}
-		o.Logger.Infof("Risc0 proof verification result: %t", verificationResult)
-		o.handleVerificationResult(results, verificationResult, err, "Risc0 proof verification")
-	default:
-		o.Logger.Error("Unrecognized proving system ID")
-		results <- false
 	}
 }
 
-func (o *Operator) handleVerificationResult(results chan bool, isVerified bool, err error, name string) {
-	if err != nil {
-		o.Logger.Errorf("%v failed %v", name, err)
-		results <- false
-	} else {
-		o.Logger.Infof("%v result: %t", name, isVerified)
-		results <- isVerified
+// recordBatchVerdicts logs, exports and stores the verdicts of a batch.
+// Returns an InvalidBatchError if any of the proofs did not verify.
+func (o *Operator) recordBatchVerdicts(batchMerkleRoot [32]byte, senderAddress [20]byte, verdicts []ProofVerdict) error {
+	batchVerdicts := BatchVerdicts{
+		BatchMerkleRoot: batchMerkleRoot,
+		SenderAddress:   senderAddress,
+		VerifiedAt:      time.Now(),
+		Verdicts:        verdicts,
+	}
+	o.verdicts.Add(batchVerdicts)
+
+	for _, verdict := range verdicts {
+		provingSystem, _ := common.ProvingSystemIdToString(verdict.ProvingSystemId)
+		o.metrics.IncOperatorProofVerdict(provingSystem, string(verdict.Outcome))
+		if verdict.IsValid() {
+			o.Logger.Debugf("Batch 0x%s %s", hex.EncodeToString(batchMerkleRoot[:]), verdict)
+		} else {
+			o.Logger.Warnf("Batch 0x%s %s", hex.EncodeToString(batchMerkleRoot[:]), verdict)
+		}
 	}
-}
 
-// VerifyPlonkProofBLS12_381 verifies a PLONK proof using BLS12-381 curve.
-func (o *Operator) verifyPlonkProofBLS12_381(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte) bool {
-	return o.verifyPlonkProof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BLS12_381)
-}
+	failed := batchVerdicts.Failed()
+	o.Logger.Info("Batch verification finished",
+		"batch merkle root", "0x"+hex.EncodeToString(batchMerkleRoot[:]),
+		"proofs", len(verdicts),
+		"failed", len(failed),
+	)
+	if len(failed) > 0 {
+		return &InvalidBatchError{BatchMerkleRoot: batchMerkleRoot, Failed: failed}
+	}
 
-// VerifyPlonkProofBN254 verifies a PLONK proof using BN254 curve.
-func (o *Operator) verifyPlonkProofBN254(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte) bool {
-	return o.verifyPlonkProof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BN254)
+	return nil
 }
 
-// VerifyGroth16ProofBN254 verifies a GROTH16 proof using BN254 curve.
-func (o *Operator) verifyGroth16ProofBN254(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte) bool {
-	return o.verifyGroth16Proof(proofBytes, pubInputBytes, verificationKeyBytes, ecc.BN254)
+// BatchVerdicts returns the per-proof verdicts of a recently verified batch
+func (o *Operator) BatchVerdicts(batchMerkleRoot [32]byte) (BatchVerdicts, bool) {
+	return o.verdicts.Get(batchMerkleRoot)
 }
 
-// verifyPlonkProof contains the common proof verification logic.
-func (o *Operator) verifyPlonkProof(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte, curve ecc.ID) bool {
-	proofReader := bytes.NewReader(proofBytes)
-	proof := plonk.NewProof(curve)
-	if _, err := proof.ReadFrom(proofReader); err != nil {
-		o.Logger.Infof("Could not deserialize proof: %v", err)
-		return false
-	}
+// verify returns the verdict of the proof, and a channel closed once its verifier returned,
+// which may be after the verdict if the verification timed out
+func (o *Operator) verify(ctx context.Context, index int, verificationData VerificationData, disabledVerifiersBitmap *big.Int) (verdict ProofVerdict, verifierDone <-chan struct{}) {
+	startTime := time.Now()
+	verdict = ProofVerdict{Index: index, ProvingSystemId: verificationData.ProvingSystemId}
+	defer func() { verdict.Duration = time.Since(startTime) }()
 
-	pubInputReader := bytes.NewReader(pubInputBytes)
-	pubInput, err := witness.New(curve.ScalarField())
-	if err != nil {
-		o.Logger.Infof("Error instantiating witness: %v", err)
-		return false
-	}
-	if _, err = pubInput.ReadFrom(pubInputReader); err != nil {
-		o.Logger.Infof("Could not read PLONK public input: %v", err)
-		return false
+	IsVerifierDisabled := IsVerifierDisabled(disabledVerifiersBitmap, verificationData.ProvingSystemId)
+	if IsVerifierDisabled {
+		o.Logger.Infof("Verifier %s is disabled. Returning false", verificationData.ProvingSystemId.String())
+		verdict.Outcome = OutcomeVerifierDisabled
+		return verdict, verifierReturned
 	}
 
-	verificationKeyReader := bytes.NewReader(verificationKeyBytes)
-	verificationKey := plonk.NewVerifyingKey(curve)
-	if _, err = verificationKey.ReadFrom(verificationKeyReader); err != nil {
-		o.Logger.Infof("Could not read PLONK verifying key from bytes: %v", err)
-		return false
+	verifier, ok := o.verifiers.Get(verificationData.ProvingSystemId)
+	if !ok {
+		o.Logger.Error("Unrecognized proving system ID")
+		verdict.Outcome = OutcomeUnsupportedProvingSystem
+		return verdict, verifierReturned
 	}
 
-	err = plonk.Verify(proof, verificationKey, pubInput)
-	return err == nil
-}
-
-// verifyGroth16Proof contains the common proof verification logic.
-func (o *Operator) verifyGroth16Proof(proofBytes []byte, pubInputBytes []byte, verificationKeyBytes []byte, curve ecc.ID) bool {
-	proofReader := bytes.NewReader(proofBytes)
-	proof := groth16.NewProof(curve)
-	if _, err := proof.ReadFrom(proofReader); err != nil {
-		o.Logger.Infof("Could not deserialize proof: %v", err)
-		return false
+	cacheKey := VerificationCacheKey(verificationData, VerifierVersion(verifier))
+	if outcome, ok := o.verificationCache.Get(cacheKey); ok {
+		o.Logger.Debugf("%v proof verification result found in cache: %s", verifier.Name(), outcome)
+		verdict.Outcome = outcome
+		verdict.Cached = true
+		return verdict, verifierReturned
 	}
 
-	pubInputReader := bytes.NewReader(pubInputBytes)
-	pubInput, err := witness.New(curve.ScalarField())
-	if err != nil {
-		o.Logger.Infof("Error instantiating witness: %v", err)
-		return false
-	}
-	if _, err = pubInput.ReadFrom(pubInputReader); err != nil {
-		o.Logger.Infof("Could not read Groth16 public input: %v", err)
-		return false
-	}
+	ctx, cancel := context.WithTimeout(ctx, o.verificationTimeouts.For(verificationData.ProvingSystemId))
+	defer cancel()
 
-	verificationKeyReader := bytes.NewReader(verificationKeyBytes)
-	verificationKey := groth16.NewVerifyingKey(curve)
-	if _, err = verificationKey.ReadFrom(verificationKeyReader); err != nil {
-		o.Logger.Infof("Could not read Groth16 verifying key from bytes: %v", err)
-		return false
+	verificationStart := time.Now()
+	result, verifierDone := runVerifier(ctx, verifier, verificationData)
+	provingSystem, _ := common.ProvingSystemIdToString(verificationData.ProvingSystemId)
+	o.metrics.ObserveOperatorVerificationLatency(provingSystem, time.Since(verificationStart))
+	if errors.Is(result.Err, ErrVerificationTimeout) {
+		o.Logger.Warnf("%v proof verification cancelled: %v", verifier.Name(), result.Err)
+		verdict.Error = result.Err.Error()
+	} else if result.Err != nil {
+		o.Logger.Errorf("%v proof verification failed %v", verifier.Name(), result.Err)
+		verdict.Error = result.Err.Error()
 	}
+	verdict.Outcome = outcomeFromResult(result)
 
-	err = groth16.Verify(proof, verificationKey, pubInput)
-	return err == nil
+	if err := o.verificationCache.Put(cacheKey, verdict.Outcome); err != nil {
+		o.Logger.Warnf("Could not store verification result in cache: %v", err)
+	}
+	return verdict, verifierDone
 }
 
-func (o *Operator) SignTaskResponse(batchIdentifierHash [32]byte) *bls.Signature {
-	responseSignature := *o.Config.BlsConfig.KeyPair.SignMessage(batchIdentifierHash)
-	return &responseSignature
+// SignTaskResponse signs the batch identifier hash with the BLS signer of the operator
+func (o *Operator) SignTaskResponse(ctx context.Context, batchIdentifierHash [32]byte) (*bls.Signature, error) {
+	return o.blsSigner.SignMessage(ctx, batchIdentifierHash)
 }
 
 func (o *Operator) SendTelemetryData(ctx *cli.Context) error {
@@ -635,8 +995,11 @@ func (o *Operator) SendTelemetryData(ctx *cli.Context) error {
 	copy(version[:], hash.Sum(nil))
 
 	// sign version
-	signature := o.Config.BlsConfig.KeyPair.SignMessage(version)
-	public_key_g2 := o.Config.BlsConfig.KeyPair.GetPubKeyG2()
+	signature, err := o.blsSigner.SignMessage(ctx.Context, version)
+	if err != nil {
+		return err
+	}
+	public_key_g2 := o.blsSigner.PubKeyG2()
 	ethRpcUrl, err := BaseUrlOnly(o.Config.BaseConfig.EthRpcUrl)
 	if err != nil {
 		return err
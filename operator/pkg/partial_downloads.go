@@ -0,0 +1,209 @@
+package operator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PartialDownloadRetention is how long the data of an interrupted download is kept to resume it
+const PartialDownloadRetention = 24 * time.Hour
+
+// PartialDownloads stores the data of HTTP batch downloads while they are in progress, so an interrupted download
+// resumes where it stopped instead of starting over, across attempts and operator restarts.
+// Each download is stored as `<sha256 of the URL>.partial`, with its ETag and length in `<sha256 of the URL>.json`.
+type PartialDownloads struct {
+	dir   string
+	inUse map[string]struct{}
+	mutex sync.Mutex
+}
+
+// ErrUnsafePartialDownloadsDir is returned when other users could read or tamper with the partial downloads
+var ErrUnsafePartialDownloadsDir = errors.New("unsafe partial downloads directory")
+
+// NewPartialDownloads stores partial downloads in dir, creating it if needed
+// and removing the downloads older than PartialDownloadRetention.
+// The directory must be owned by the current user with mode 0700, since a resumed download trusts its stored data.
+func NewPartialDownloads(dir string) (*PartialDownloads, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPrivateDir(dir, info); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsafePartialDownloadsDir, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && time.Since(info.ModTime()) > PartialDownloadRetention {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return &PartialDownloads{dir: dir, inUse: make(map[string]struct{})}, nil
+}
+
+// partialDownloadMeta identifies the version of the batch the partial data belongs to
+type partialDownloadMeta struct {
+	URL    string `json:"url"`
+	ETag   string `json:"etag"`
+	Length int64  `json:"length"`
+}
+
+// partialDownload is the stored data of a download, owned by a single request at a time
+type partialDownload struct {
+	downloads *PartialDownloads
+	key       string
+	file      *os.File
+	meta      partialDownloadMeta
+	size      int64
+}
+
+// acquire opens the partial download of batchURL, or returns nil if another request is using it
+func (d *PartialDownloads) acquire(batchURL string) (*partialDownload, error) {
+	hash := sha256.Sum256([]byte(batchURL))
+	key := hex.EncodeToString(hash[:])
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if _, ok := d.inUse[key]; ok {
+		return nil, nil
+	}
+
+	partial := &partialDownload{downloads: d, key: key, meta: partialDownloadMeta{URL: batchURL, Length: -1}}
+	file, err := os.OpenFile(partial.path(".partial"), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	partial.file = file
+
+	// Data without a valid description of its batch can't be resumed
+	metaBytes, err := os.ReadFile(partial.path(".json"))
+	var meta partialDownloadMeta
+	if err == nil && json.Unmarshal(metaBytes, &meta) == nil && meta.URL == batchURL && meta.Length >= 0 {
+		info, err := file.Stat()
+		if err == nil && info.Size() < meta.Length {
+			partial.meta, partial.size = meta, info.Size()
+		}
+	}
+	if partial.size == 0 {
+		if err := file.Truncate(0); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	d.inUse[key] = struct{}{}
+	return partial, nil
+}
+
+func (p *partialDownload) path(extension string) string {
+	return filepath.Join(p.downloads.dir, p.key+extension)
+}
+
+// resumable returns whether the download can continue with a range request
+func (p *partialDownload) resumable() bool {
+	return p.size > 0 && p.meta.Length >= 0
+}
+
+// restart discards the stored data to download a new version of the batch
+func (p *partialDownload) restart(etag string, length int64) error {
+	if err := p.file.Truncate(0); err != nil {
+		return err
+	}
+	p.size = 0
+	p.meta.ETag, p.meta.Length = etag, length
+
+	metaBytes, err := json.Marshal(p.meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.path(".json"), metaBytes, 0o600)
+}
+
+// release closes the partial download, removing its data if it's complete or can't be resumed
+func (p *partialDownload) release(keep bool) {
+	p.file.Close()
+	if !keep || p.meta.Length < 0 || p.size >= p.meta.Length {
+		os.Remove(p.path(".partial"))
+		os.Remove(p.path(".json"))
+	}
+
+	p.downloads.mutex.Lock()
+	delete(p.downloads.inUse, p.key)
+	p.downloads.mutex.Unlock()
+}
+
+// partialReader serves a batch from offset on: first from the stored data, then from the response body,
+// storing what it reads from the body
+type partialReader struct {
+	partial *partialDownload
+	stored  io.Reader
+	body    io.ReadCloser
+	// skip is the number of bytes of body before offset, stored but not returned
+	skip int64
+}
+
+func newPartialReader(partial *partialDownload, body io.ReadCloser, offset int64) *partialReader {
+	reader := &partialReader{partial: partial, body: body}
+	if offset < partial.size {
+		reader.stored = io.NewSectionReader(partial.file, offset, partial.size-offset)
+	} else {
+		reader.skip = offset - partial.size
+	}
+	return reader
+}
+
+func (r *partialReader) Read(p []byte) (int, error) {
+	if r.stored != nil {
+		n, err := r.stored.Read(p)
+		if !errors.Is(err, io.EOF) {
+			return n, err
+		}
+		r.stored = nil
+		if n > 0 {
+			return n, nil
+		}
+	}
+
+	for {
+		n, err := r.body.Read(p)
+		if n > 0 {
+			if _, writeErr := r.partial.file.WriteAt(p[:n], r.partial.size); writeErr != nil {
+				return 0, writeErr
+			}
+			r.partial.size += int64(n)
+		}
+		if errors.Is(err, io.EOF) && r.partial.meta.Length >= 0 && r.partial.size != r.partial.meta.Length {
+			err = io.ErrUnexpectedEOF
+		}
+
+		skipped := min(int64(n), r.skip)
+		r.skip -= skipped
+		if n-int(skipped) > 0 || err != nil {
+			copy(p, p[skipped:n])
+			return n - int(skipped), err
+		}
+	}
+}
+
+// Close keeps the stored data to resume the download, unless it's complete
+func (r *partialReader) Close() error {
+	err := r.body.Close()
+	r.partial.release(true)
+	return err
+}
@@ -0,0 +1,196 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cuttingWriter aborts the response after writing remaining bytes of the body, like a dropped connection
+type cuttingWriter struct {
+	http.ResponseWriter
+	remaining int
+}
+
+func (w *cuttingWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.remaining {
+		w.remaining -= len(p)
+		return w.ResponseWriter.Write(p)
+	}
+	w.ResponseWriter.Write(p[:w.remaining])
+	w.ResponseWriter.(http.Flusher).Flush()
+	panic(http.ErrAbortHandler)
+}
+
+// flakyBatchServer serves a batch, cutting the first response after cutAfter bytes
+type flakyBatchServer struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	batch    []byte
+	etag     string
+	ranges   []string
+	cutAfter int
+	// ignoreRanges serves the whole batch to range requests, like servers without range support
+	ignoreRanges bool
+}
+
+func newFlakyBatchServer(batch []byte, etag string, cutAfter int) *flakyBatchServer {
+	server := &flakyBatchServer{batch: batch, etag: etag, cutAfter: cutAfter}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mutex.Lock()
+		batch, etag := server.batch, server.etag
+		server.ranges = append(server.ranges, r.Header.Get("Range"))
+		if len(server.ranges) == 1 {
+			w = &cuttingWriter{ResponseWriter: w, remaining: server.cutAfter}
+		}
+		ignoreRanges := server.ignoreRanges
+		server.mutex.Unlock()
+
+		w.Header().Set("ETag", etag)
+		if ignoreRanges {
+			r.Header.Del("Range")
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(batch))
+	}))
+	return server
+}
+
+// replace changes the batch served from now on
+func (s *flakyBatchServer) replace(batch []byte, etag string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.batch, s.etag = batch, etag
+}
+
+func (s *flakyBatchServer) requestedRanges() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.ranges...)
+}
+
+func randomTestBatch(t *testing.T, size int) []byte {
+	batch := make([]byte, size)
+	if _, err := rand.Read(batch); err != nil {
+		t.Fatal(err)
+	}
+	return batch
+}
+
+func newTestResumableFetchers(t *testing.T, policy BatchFetcherPolicy) (*BatchFetchers, string) {
+	dir := filepath.Join(t.TempDir(), "partials")
+	partials, err := NewPartialDownloads(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestBatchFetchers(t, "http", NewHttpBatchFetcher(&http.Client{}, partials), policy), dir
+}
+
+func expectNoPartialDownloads(t *testing.T, dir string) {
+	t.Helper()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected the partial download to be removed, got %d files", len(entries))
+	}
+}
+
+func TestHttpBatchFetcherResumesInterruptedDownloads(t *testing.T) {
+	batch := randomTestBatch(t, 64*1024)
+	server := newFlakyBatchServer(batch, `"v1"`, 10000)
+	defer server.Close()
+
+	fetchers, dir := newTestResumableFetchers(t, testBatchFetcherPolicy)
+	downloaded, err := readBatch(fetchers, server.URL+"/batch.cbor", 1<<20)
+	if err != nil || !bytes.Equal(downloaded, batch) {
+		t.Fatalf("expected the whole batch after resuming, got %d bytes, %v", len(downloaded), err)
+	}
+
+	ranges := server.requestedRanges()
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes=10000-" {
+		t.Errorf("expected the second request to resume from byte 10000, got ranges %q", ranges)
+	}
+	expectNoPartialDownloads(t, dir)
+}
+
+func TestHttpBatchFetcherResumesWithoutRangeSupport(t *testing.T) {
+	batch := randomTestBatch(t, 64*1024)
+	server := newFlakyBatchServer(batch, `"v1"`, 10000)
+	server.ignoreRanges = true
+	defer server.Close()
+
+	fetchers, dir := newTestResumableFetchers(t, testBatchFetcherPolicy)
+	downloaded, err := readBatch(fetchers, server.URL+"/batch.cbor", 1<<20)
+	if err != nil || !bytes.Equal(downloaded, batch) {
+		t.Fatalf("expected the whole batch after downloading it again, got %d bytes, %v", len(downloaded), err)
+	}
+	expectNoPartialDownloads(t, dir)
+}
+
+func TestHttpBatchFetcherDetectsChangedBatches(t *testing.T) {
+	batch := randomTestBatch(t, 64*1024)
+	server := newFlakyBatchServer(batch, `"v1"`, 10000)
+	defer server.Close()
+
+	fetchers, dir := newTestResumableFetchers(t, testBatchFetcherPolicy)
+	reader, err := fetchers.Open(context.Background(), server.URL+"/batch.cbor", 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	// The batch changes after the first response was cut, so the rest doesn't belong to the bytes already read
+	server.replace(randomTestBatch(t, 64*1024), `"v2"`)
+	buf := make([]byte, 1024)
+	for err == nil {
+		_, err = reader.Read(buf)
+	}
+	if !errors.Is(err, ErrBatchChanged) {
+		t.Errorf("expected ErrBatchChanged, got %v", err)
+	}
+	expectNoPartialDownloads(t, dir)
+}
+
+func TestHttpBatchFetcherKeepsProgressAcrossDownloads(t *testing.T) {
+	batch := randomTestBatch(t, 64*1024)
+	server := newFlakyBatchServer(batch, `"v1"`, 10000)
+	defer server.Close()
+
+	// A single attempt per download, so the first one fails once the response is cut
+	policy := BatchFetcherPolicy{Timeout: time.Second, MaxRetries: 1, RetryDelay: time.Millisecond}
+	fetchers, dir := newTestResumableFetchers(t, policy)
+	if _, err := readBatch(fetchers, server.URL+"/batch.cbor", 1<<20); err == nil {
+		t.Fatal("expected the first download to fail")
+	}
+
+	downloaded, err := readBatch(fetchers, server.URL+"/batch.cbor", 1<<20)
+	if err != nil || !bytes.Equal(downloaded, batch) {
+		t.Fatalf("expected the whole batch from the stored part, got %d bytes, %v", len(downloaded), err)
+	}
+	if ranges := server.requestedRanges(); len(ranges) != 2 || ranges[1] != "bytes=10000-" {
+		t.Errorf("expected the second download to resume from byte 10000, got ranges %q", ranges)
+	}
+	expectNoPartialDownloads(t, dir)
+}
+
+func TestPartialDownloadsRefuseSharedDirs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPartialDownloads(dir); !errors.Is(err, ErrUnsafePartialDownloadsDir) {
+		t.Errorf("expected a directory readable by other users to be refused, got %v", err)
+	}
+
+	if err := os.Chmod(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPartialDownloads(dir); err != nil {
+		t.Errorf("expected a private directory to be accepted, got %v", err)
+	}
+}
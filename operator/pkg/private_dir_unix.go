@@ -0,0 +1,20 @@
+//go:build unix
+
+package operator
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivateDir returns an error unless dir is owned by the current user and only accessible by them
+func checkPrivateDir(dir string, info os.FileInfo) error {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by uid %d, not by the current user", dir, stat.Uid)
+	}
+	if info.Mode().Perm() != 0o700 {
+		return fmt.Errorf("%s has mode %#o, expected 0700", dir, info.Mode().Perm())
+	}
+	return nil
+}
@@ -2,22 +2,31 @@ package operator
 
 import (
 	"context"
+	"errors"
 
+	chainioutils "github.com/Layr-Labs/eigensdk-go/chainio/utils"
 	"github.com/Layr-Labs/eigensdk-go/types"
 	"github.com/yetanotherco/aligned_layer/core/chainio"
 	"github.com/yetanotherco/aligned_layer/core/config"
 )
 
+// ErrBlsKeystoreRequired is returned by RegisterOperator, which signs the pubkey registration with the BLS private key itself
+var ErrBlsKeystoreRequired = errors.New("the BLS keystore is required to register, a remote signer can't be used")
+
 // RegisterOperator operator registers the operator with the given public key for the given quorum IDs.
 // RegisterOperator registers a new operator with the given public key and socket with the provided quorum ids.
 // If the operator is already registered with a given quorum id, the transaction will fail (noop) and an error
 // will be returned.
+// The AVS registration message is signed with the ECDSA keystore or, without one, as EIP-712 typed data by the external signer.
 func RegisterOperator(
 	ctx context.Context,
 	configuration *config.OperatorConfig,
 	ecdsaConfig *config.EcdsaConfig,
 	operatorToAvsRegistrationSigSalt [32]byte,
 ) error {
+	if configuration.BlsConfig.KeyPair == nil {
+		return ErrBlsKeystoreRequired
+	}
 	writer, err := chainio.NewAvsWriterFromConfig(configuration.BaseConfig, ecdsaConfig, nil)
 	if err != nil {
 		configuration.BaseConfig.Logger.Error("Failed to create AVS writer", "err", err)
@@ -28,9 +37,17 @@ func RegisterOperator(
 
 	quorumNumbers := types.QuorumNums{0}
 
-	_, err = writer.RegisterOperator(ctx, ecdsaConfig.PrivateKey,
-		configuration.BlsConfig.KeyPair,
-		quorumNumbers, socket, true)
+	if ecdsaConfig.PrivateKey != nil {
+		_, err = writer.RegisterOperator(ctx, ecdsaConfig.PrivateKey,
+			configuration.BlsConfig.KeyPair,
+			quorumNumbers, socket, true)
+	} else if typedDataSigner, ok := ecdsaConfig.Signer.(chainio.TypedDataSigner); ok {
+		_, err = writer.RegisterOperatorWithTypedDataSigner(ctx, typedDataSigner,
+			configuration.BlsConfig.KeyPair,
+			quorumNumbers, socket, operatorToAvsRegistrationSigSalt)
+	} else {
+		err = errors.New("the ECDSA signer can't sign the AVS registration")
+	}
 
 	if err != nil {
 		configuration.BaseConfig.Logger.Error("Failed to register operator", "err", err)
@@ -39,3 +56,77 @@ func RegisterOperator(
 
 	return nil
 }
+
+// DeregisterOperator deregisters the operator from the quorums it registered with RegisterOperator.
+// Only the BLS public key is needed, so it works with the BLS keystore or a remote signer.
+func DeregisterOperator(
+	ctx context.Context,
+	configuration *config.OperatorConfig,
+	ecdsaConfig *config.EcdsaConfig,
+) error {
+	blsSigner, err := NewBlsSignerFromConfig(ctx, configuration.BlsConfig)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to create BLS signer", "err", err)
+		return err
+	}
+	writer, err := chainio.NewAvsWriterFromConfig(configuration.BaseConfig, ecdsaConfig, nil)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to create AVS writer", "err", err)
+		return err
+	}
+
+	quorumNumbers := types.QuorumNums{0}
+	pubkey := chainioutils.ConvertToBN254G1Point(blsSigner.PubKeyG1())
+
+	_, err = writer.DeregisterOperator(ctx, quorumNumbers, pubkey, true)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to deregister operator", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateOperatorSocket updates the socket the operator registered with in the registry coordinator.
+func UpdateOperatorSocket(
+	ctx context.Context,
+	configuration *config.OperatorConfig,
+	ecdsaConfig *config.EcdsaConfig,
+	socket string,
+) error {
+	writer, err := chainio.NewAvsWriterFromConfig(configuration.BaseConfig, ecdsaConfig, nil)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to create AVS writer", "err", err)
+		return err
+	}
+
+	_, err = writer.UpdateSocket(ctx, types.Socket(socket), true)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to update operator socket", "err", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateOperatorMetadataURI updates the metadata URI of the operator in the EigenLayer delegation manager.
+func UpdateOperatorMetadataURI(
+	ctx context.Context,
+	configuration *config.OperatorConfig,
+	ecdsaConfig *config.EcdsaConfig,
+	metadataURI string,
+) error {
+	writer, err := chainio.NewAvsWriterFromConfig(configuration.BaseConfig, ecdsaConfig, nil)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to create AVS writer", "err", err)
+		return err
+	}
+
+	_, err = writer.ElChainWriter.UpdateMetadataURI(ctx, metadataURI, true)
+	if err != nil {
+		configuration.BaseConfig.Logger.Error("Failed to update operator metadata URI", "err", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package operator
+
+import "errors"
+
+var errRlimitsNotSupported = errors.New("resource limits are not supported on this platform")
+
+func setMemoryLimit(maxBytes int64) error {
+	return errRlimitsNotSupported
+}
+
+func setCPULimit(seconds int64) error {
+	return errRlimitsNotSupported
+}
+
+func exitOnCPULimit(exitCode int) {}
@@ -0,0 +1,46 @@
+//go:build unix
+
+package operator
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// setMemoryLimit bounds the address space of the current process
+func setMemoryLimit(maxBytes int64) error {
+	limit := syscall.Rlimit{Cur: uint64(maxBytes), Max: uint64(maxBytes)}
+	return syscall.Setrlimit(syscall.RLIMIT_AS, &limit)
+}
+
+// setCPULimit lets the current process use seconds more of CPU time from now on.
+// Only the soft limit is moved, so it can be raised again for the next proof.
+func setCPULimit(seconds int64) error {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return err
+	}
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_CPU, &limit); err != nil {
+		return err
+	}
+
+	used := usage.Utime.Sec + usage.Stime.Sec
+	limit.Cur = uint64(used + seconds)
+	if limit.Cur > limit.Max {
+		limit.Cur = limit.Max
+	}
+	return syscall.Setrlimit(syscall.RLIMIT_CPU, &limit)
+}
+
+// exitOnCPULimit exits with exitCode when the soft CPU limit is exceeded,
+// since the Go runtime ignores the SIGXCPU signal raised by the kernel
+func exitOnCPULimit(exitCode int) {
+	cpuLimitChan := make(chan os.Signal, 1)
+	signal.Notify(cpuLimitChan, syscall.SIGXCPU)
+	go func() {
+		<-cpuLimitChan
+		os.Exit(exitCode)
+	}()
+}
@@ -1,64 +1,302 @@
 package operator
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"net/rpc"
+	"sync"
 	"time"
 
 	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/core/aggregatorapi"
 	"github.com/yetanotherco/aligned_layer/core/types"
 )
 
-// AggregatorRpcClient is the client to communicate with the aggregator via RPC
+// AggregatorRpcClient is the client to communicate with an aggregator, over its JSON API or the legacy gob RPC.
+// It keeps its own retry state: after a failed attempt, every response to the aggregator waits
+// RetryInterval before the next attempt, and the connection is reestablished if the aggregator restarted.
+// Responses the aggregator rejected for good, see aggregatorapi.ErrorCode, are not retried.
 type AggregatorRpcClient struct {
-	rpcClient            *rpc.Client
 	aggregatorIpPortAddr string
+	protocol             AggregatorProtocol
 	logger               logging.Logger
+	onDelivery           func(aggregator string, result string, latency time.Duration)
+	onReconnect          func(aggregator string, err error)
+	// requestTimeout bounds each attempt, AggregatorRequestTimeout unless changed in tests
+	requestTimeout time.Duration
+
+	mutex sync.Mutex
+	// conn is nil while disconnected
+	conn aggregatorConn
+	// retryAt is when the aggregator can be tried again after a failed attempt
+	retryAt time.Time
+}
+
+// AggregatorProtocol is the API used to send the signed task responses to the aggregators
+type AggregatorProtocol string
+
+const (
+	// AggregatorProtocolJson is the versioned HTTP JSON API of aggregatorapi
+	AggregatorProtocolJson AggregatorProtocol = "json"
+	// AggregatorProtocolGob is the legacy gob net/rpc API, for aggregators not serving the JSON API yet
+	AggregatorProtocolGob AggregatorProtocol = "gob"
+)
+
+// aggregatorConn is a connection to the API of an aggregator
+type aggregatorConn interface {
+	sendSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) error
+	close() error
+}
+
+// gobAggregatorConn calls the legacy gob RPC of the aggregator. Its errors are rpc.ErrShutdown once the aggregator closed it.
+type gobAggregatorConn struct {
+	rpcClient *rpc.Client
+}
+
+func (c *gobAggregatorConn) sendSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) error {
+	var reply uint8
+	return call(ctx, c.rpcClient, "Aggregator.ProcessOperatorSignedTaskResponseV2", signedTaskResponse, &reply)
+}
+
+func (c *gobAggregatorConn) close() error {
+	return c.rpcClient.Close()
+}
+
+// jsonAggregatorConn calls the JSON API of the aggregator, the HTTP client reconnects by itself
+type jsonAggregatorConn struct {
+	client     *aggregatorapi.Client
+	httpClient *http.Client
+}
+
+func (c *jsonAggregatorConn) sendSignedTaskResponse(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) error {
+	_, err := c.client.SubmitSignedTaskResponse(ctx, signedTaskResponse)
+	return err
+}
+
+func (c *jsonAggregatorConn) close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
 }
 
 const (
 	MaxRetries    = 10
 	RetryInterval = 10 * time.Second
+	// AggregatorRequestTimeout bounds each attempt to send a response, which the aggregator can hold
+	// while it waits for the task to be created and processes the signature
+	AggregatorRequestTimeout = 30 * time.Second
 )
 
-func NewAggregatorRpcClient(aggregatorIpPortAddr string, logger logging.Logger) (*AggregatorRpcClient, error) {
-	client, err := rpc.DialHTTP("tcp", aggregatorIpPortAddr)
-	if err != nil {
+// Results of a delivery attempt passed to the OnDelivery callback
+const (
+	AggregatorDeliveryAccepted = "accepted"
+	AggregatorDeliveryError    = "error"
+)
+
+func NewAggregatorRpcClient(aggregatorIpPortAddr string, protocol AggregatorProtocol, logger logging.Logger) (*AggregatorRpcClient, error) {
+	c := newAggregatorRpcClient(aggregatorIpPortAddr, protocol, logger)
+	if err := c.connect(); err != nil {
 		return nil, err
 	}
+	return c, nil
+}
 
+func newAggregatorRpcClient(aggregatorIpPortAddr string, protocol AggregatorProtocol, logger logging.Logger) *AggregatorRpcClient {
 	return &AggregatorRpcClient{
-		rpcClient:            client,
 		aggregatorIpPortAddr: aggregatorIpPortAddr,
-		logger:               logger,
-	}, nil
+		protocol:             protocol,
+		logger:               logger.With("aggregator", aggregatorIpPortAddr),
+		requestTimeout:       AggregatorRequestTimeout,
+	}
+}
+
+// connect dials the aggregator, replacing the current connection.
+// With the JSON API, it checks the aggregator serves its version instead.
+func (c *AggregatorRpcClient) connect() error {
+	var conn aggregatorConn
+	switch c.protocol {
+	case AggregatorProtocolGob:
+		rpcClient, err := rpc.DialHTTP("tcp", c.aggregatorIpPortAddr)
+		if err != nil {
+			return err
+		}
+		conn = &gobAggregatorConn{rpcClient: rpcClient}
+	default:
+		httpClient := &http.Client{Timeout: c.requestTimeout}
+		client := aggregatorapi.NewClient(c.aggregatorIpPortAddr, httpClient)
+		ctx, cancel := context.WithTimeout(context.Background(), AggregatorDialTimeout)
+		defer cancel()
+		if err := client.CheckVersion(ctx); err != nil {
+			return fmt.Errorf("%w. Set `aggregators.protocol: gob` for aggregators not serving the JSON API", err)
+		}
+		conn = &jsonAggregatorConn{client: client, httpClient: httpClient}
+	}
+	c.mutex.Lock()
+	previous := c.conn
+	c.conn = conn
+	c.mutex.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+	return nil
+}
+
+// Close closes the connection to the aggregator
+func (c *AggregatorRpcClient) Close() error {
+	c.mutex.Lock()
+	conn := c.conn
+	c.conn = nil
+	c.mutex.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.close()
+}
+
+// reconnect dials the aggregator again after it shut down the connection
+func (c *AggregatorRpcClient) reconnect() error {
+	c.logger.Info("Reconnecting to aggregator...")
+	err := c.connect()
+	if c.onReconnect != nil {
+		c.onReconnect(c.aggregatorIpPortAddr, err)
+	}
+	if err != nil {
+		c.logger.Error("Could not reconnect to aggregator", "err", err)
+		return err
+	}
+	c.logger.Info("Reconnected to aggregator")
+	return nil
 }
 
 // SendSignedTaskResponseToAggregator is the method called by operators via RPC to send
-// their signed task response.
-func (c *AggregatorRpcClient) SendSignedTaskResponseToAggregator(signedTaskResponse *types.SignedTaskResponse) {
-	var reply uint8
+// their signed task response. Returns an error if the aggregator did not accept it after MaxRetries attempts,
+// or the cause of ctx once it's done.
+func (c *AggregatorRpcClient) SendSignedTaskResponseToAggregator(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) error {
 	for retries := 0; retries < MaxRetries; retries++ {
-		err := c.rpcClient.Call("Aggregator.ProcessOperatorSignedTaskResponseV2", signedTaskResponse, &reply)
-		if err != nil {
-			c.logger.Error("Received error from aggregator", "err", err)
-			if errors.Is(err, rpc.ErrShutdown) {
-				c.logger.Error("Aggregator is shutdown. Reconnecting...")
-				client, err := rpc.DialHTTP("tcp", c.aggregatorIpPortAddr)
-				if err != nil {
-					c.logger.Error("Could not reconnect to aggregator", "err", err)
-					time.Sleep(RetryInterval)
-				} else {
-					c.rpcClient = client
-					c.logger.Info("Reconnected to aggregator")
-				}
-			} else {
-				c.logger.Infof("Received error from aggregator: %s. Retrying ProcessOperatorSignedTaskResponseV2 RPC call...", err)
-				time.Sleep(RetryInterval)
+		if err := sleepContext(ctx, time.Until(c.nextAttempt())); err != nil {
+			return err
+		}
+		err := c.attempt(ctx, signedTaskResponse)
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if err == nil {
+			return nil
+		}
+		if !aggregatorapi.IsRetryable(err) {
+			return fmt.Errorf("signed task response rejected by aggregator %s: %w", c.aggregatorIpPortAddr, err)
+		}
+	}
+
+	return fmt.Errorf("signed task response not accepted by aggregator %s after %d retries", c.aggregatorIpPortAddr, MaxRetries)
+}
+
+// attempt sends the signed task response once, waiting up to AggregatorRequestTimeout for the aggregator to reply.
+// If it fails, the aggregator isn't tried again for RetryInterval, unless the connection was shut down
+// and reestablished or the aggregator rejected the response for good.
+func (c *AggregatorRpcClient) attempt(ctx context.Context, signedTaskResponse *types.SignedTaskResponse) error {
+	c.mutex.Lock()
+	conn := c.conn
+	c.mutex.Unlock()
+
+	var err error
+	if conn == nil {
+		if err = c.reconnect(); err == nil {
+			c.mutex.Lock()
+			conn = c.conn
+			c.mutex.Unlock()
+		}
+	}
+
+	if err == nil {
+		startTime := time.Now()
+		attemptCtx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+		err = conn.sendSignedTaskResponse(attemptCtx, signedTaskResponse)
+		cancel()
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if err == nil {
+			c.logger.Info("Signed task response header accepted by aggregator.")
+			c.report(AggregatorDeliveryAccepted, time.Since(startTime))
+			c.mutex.Lock()
+			c.retryAt = time.Time{}
+			c.mutex.Unlock()
+			return nil
+		}
+		c.logger.Error("Received error from aggregator", "err", err)
+		c.report(AggregatorDeliveryError, time.Since(startTime))
+
+		if errors.Is(err, rpc.ErrShutdown) {
+			c.logger.Error("Aggregator is shutdown. Reconnecting...")
+			c.mutex.Lock()
+			if c.conn == conn {
+				c.conn = nil
+			}
+			c.mutex.Unlock()
+			if c.reconnect() == nil {
+				return err
 			}
+		} else if !aggregatorapi.IsRetryable(err) {
+			return err
 		} else {
-			c.logger.Info("Signed task response header accepted by aggregator.", "reply", reply)
-			return
+			c.logger.Infof("Received error from aggregator: %s. Retrying in %v...", err, RetryInterval)
 		}
 	}
+
+	c.mutex.Lock()
+	c.retryAt = time.Now().Add(RetryInterval)
+	c.mutex.Unlock()
+	return err
+}
+
+func (c *AggregatorRpcClient) report(result string, latency time.Duration) {
+	if c.onDelivery != nil {
+		c.onDelivery(c.aggregatorIpPortAddr, result, latency)
+	}
+}
+
+// nextAttempt is when the aggregator can be tried again, in the past if it can be tried now
+func (c *AggregatorRpcClient) nextAttempt() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.retryAt
+}
+
+// call calls an RPC method of the aggregator, returning early if ctx is done.
+// The call itself can't be cancelled, so its reply is discarded in that case.
+func call(ctx context.Context, rpcClient *rpc.Client, serviceMethod string, args any, reply any) error {
+	call := rpcClient.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// sleepContext sleeps for duration, returning the cause of ctx if it's done first
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return context.Cause(ctx)
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Ping checks the aggregator accepts connections
+func (c *AggregatorRpcClient) Ping(timeout time.Duration) error {
+	conn, err := net.DialTimeout("tcp", c.aggregatorIpPortAddr, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
 }
@@ -2,117 +2,102 @@ package operator
 
 import (
 	"context"
-	"fmt"
 	"io"
-	"net/http"
-	"time"
-
-	"github.com/ugorji/go/codec"
-	"github.com/yetanotherco/aligned_layer/operator/merkle_tree"
+	"math/big"
 )
 
-func (o *Operator) getBatchFromDataService(ctx context.Context, batchURL string, expectedMerkleRoot [32]byte, maxRetries int, retryDelay time.Duration) ([]VerificationData, error) {
-	o.Logger.Infof("Getting batch from data service, batchURL: %s", batchURL)
-
-	var resp *http.Response
-	var err error
-	var req *http.Request
+// getBatchVerdictsFromDataService streams the batch at batchDataPointer, or from one of its mirrors,
+// verifying its proofs as soon as they are decoded. The verdicts are only returned once the merkle root
+// of the whole batch is the expected one: the verdicts of a download that doesn't match are discarded,
+// and the batch is downloaded again from the next mirror.
+// If the batch cache is enabled, the batch is read from it when stored, and stored once downloaded.
+func (o *Operator) getBatchVerdictsFromDataService(ctx context.Context, batchDataPointer string, expectedMerkleRoot [32]byte, disabledVerifiersBitmap *big.Int, stopOnFailure bool, onVerdict func(ProofVerdict)) ([]ProofVerdict, error) {
+	if o.batchCache != nil {
+		if verdicts, ok := o.getBatchVerdictsFromCache(ctx, expectedMerkleRoot, disabledVerifiersBitmap, stopOnFailure, onVerdict); ok {
+			return verdicts, nil
+		}
+	}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			o.Logger.Infof("Waiting for %s before retrying data fetch (attempt %d of %d)", retryDelay, attempt+1, maxRetries)
-			select {
-			case <-time.After(retryDelay):
-				// Wait before retrying
-			case <-ctx.Done():
-				return nil, ctx.Err()
+	o.Logger.Infof("Getting batch from data service, batchURL: %s", batchDataPointer)
+
+	var verdicts []ProofVerdict
+	err := o.batchMirrors.Stream(ctx, batchDataPointer, o.Config.Operator.MaxBatchSize, func(download io.Reader) error {
+		metered := &meteredReader{reader: download}
+		var batch io.Reader = metered
+		var pending *pendingBatch
+		if o.batchCache != nil {
+			var err error
+			if pending, err = o.batchCache.Create(); err != nil {
+				o.Logger.Warnf("Could not store batch in the batch cache: %v", err)
+			} else {
+				defer pending.Discard()
+				batch = io.TeeReader(batch, pending)
 			}
-			retryDelay *= 2 // Exponential backoff. Ex: 5s, 10s, 20s
 		}
 
-		req, err = http.NewRequestWithContext(ctx, "GET", batchURL, nil)
+		merkleRoot, batchVerdicts, err := o.verifyBatchStream(ctx, batch, disabledVerifiersBitmap, stopOnFailure, onVerdict)
 		if err != nil {
-			return nil, err
+			return err
 		}
-
-		resp, err = http.DefaultClient.Do(req)
-		if err == nil && resp.StatusCode == http.StatusOK {
-			break // Successful request, exit retry loop
+		if merkleRoot != expectedMerkleRoot {
+			return ErrBatchMerkleRootMismatch
 		}
-
-		if resp != nil {
-			err := resp.Body.Close()
+		o.Logger.Infof("Batch merkle tree verified")
+		verdicts = batchVerdicts
+
+		if pending != nil {
+			// Whatever the decoder didn't read is part of the batch too
+			_, err := io.Copy(io.Discard, batch)
+			if err == nil {
+				err = pending.Commit(merkleRoot)
+			}
 			if err != nil {
-				return nil, err
+				o.Logger.Warnf("Could not store batch in the batch cache: %v", err)
 			}
 		}
+		o.metrics.ObserveOperatorBatchDownload(metered.bytes, metered.elapsed)
+		return nil
+	})
+	return verdicts, err
+}
 
-		o.Logger.Warnf("Error fetching batch from data service - (attempt %d): %v", attempt+1, err)
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	// At this point, the HTTP request was successfull.
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			fmt.Println("error closing body: ", err)
-		}
-	}(resp.Body)
-
-	// Check if the response is OK
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting batch from data service: %s", resp.Status)
-	}
-
-	contentLength := resp.ContentLength
-	if contentLength > o.Config.Operator.MaxBatchSize {
-		return nil, fmt.Errorf("proof size %d exceeds max batch size %d",
-			contentLength, o.Config.Operator.MaxBatchSize)
-	}
-
-	// Use io.LimitReader to limit the size of the response body
-	// This is to prevent the operator from downloading a larger than expected file
-	// + 1 is added to the contentLength to check if the response body is larger than expected
-	reader := io.LimitedReader{R: resp.Body, N: contentLength + 1}
-	batchBytes, err := io.ReadAll(&reader)
-	if err != nil {
-		return nil, err
-	}
-
-	// Check if the response body is larger than expected
-	if reader.N <= 0 {
-		return nil, fmt.Errorf("batch size exceeds max batch size %d", o.Config.Operator.MaxBatchSize)
+// getBatchVerdictsFromCache verifies the batch with expectedMerkleRoot from the batch cache, if stored.
+// A stored batch that doesn't match its merkle root is removed, so it's downloaded again.
+func (o *Operator) getBatchVerdictsFromCache(ctx context.Context, expectedMerkleRoot [32]byte, disabledVerifiersBitmap *big.Int, stopOnFailure bool, onVerdict func(ProofVerdict)) ([]ProofVerdict, bool) {
+	batch, ok := o.batchCache.Open(expectedMerkleRoot)
+	if !ok {
+		return nil, false
 	}
+	defer batch.Close()
 
-	// Checks if downloaded merkle root is the same as the expected one
-	o.Logger.Infof("Verifying batch merkle tree...")
-	merkle_root_check, err := merkle_tree.VerifyMerkleTreeBatch(batchBytes, expectedMerkleRoot)
-	if err != nil || !merkle_root_check {
-		return nil, fmt.Errorf("Error while verifying merkle tree batch")
+	o.Logger.Infof("Getting batch from the batch cache, merkle root: 0x%x", expectedMerkleRoot)
+	merkleRoot, verdicts, err := o.verifyBatchStream(ctx, batch, disabledVerifiersBitmap, stopOnFailure, onVerdict)
+	if err == nil && merkleRoot == expectedMerkleRoot {
+		o.Logger.Infof("Batch merkle tree verified")
+		return verdicts, true
 	}
-	o.Logger.Infof("Batch merkle tree verified")
-
-	var batch []VerificationData
-
-	decoder, err := createDecoderMode()
-	if err != nil {
-		return nil, fmt.Errorf("error creating CBOR decoder: %s", err)
+	if ctx.Err() == nil {
+		o.Logger.Warnf("Removing corrupted batch 0x%x from the batch cache: %v", expectedMerkleRoot, err)
+		o.batchCache.Remove(expectedMerkleRoot)
 	}
-	err = decoder.Unmarshal(batchBytes, &batch)
+	return nil, false
+}
 
-	if err != nil {
-		o.Logger.Infof("Error decoding batch as CBOR: %s. Trying JSON decoding...", err)
-		// try json
-		decoder := codec.NewDecoderBytes(batchBytes, new(codec.JsonHandle))
-		err = decoder.Decode(&batch)
-		if err != nil {
-			return nil, err
-		}
+// verifyBatchStream decodes a serialized batch, submitting each proof for verification as soon as it's decoded,
+// and returns the merkle root of the batch along with the verdicts of its proofs, in batch order.
+// Decoding blocks while MaxPendingProofsPerBatch proofs wait for their verification, which bounds the memory used
+// by the batch. If stopOnFailure is set, the verifications after the first failed proof are cancelled, but the batch
+// is still decoded to compute its merkle root: the failure only stands if the batch is the expected one.
+func (o *Operator) verifyBatchStream(ctx context.Context, batch io.Reader, disabledVerifiersBitmap *big.Int, stopOnFailure bool, onVerdict func(ProofVerdict)) ([32]byte, []ProofVerdict, error) {
+	verifications := o.startProofVerifications(ctx, disabledVerifiersBitmap, stopOnFailure, onVerdict)
+	merkleRoot, timings, err := decodeBatchStreamTimed(batch, func(index int, verificationData VerificationData) error {
+		verifications.Submit(verificationData)
+		return context.Cause(ctx)
+	})
+	verdicts := verifications.Wait()
+	if err == nil {
+		o.metrics.ObserveOperatorBatchDecodeLatency(timings.Decode)
+		o.metrics.ObserveOperatorBatchMerkleCheckLatency(timings.MerkleCheck)
 	}
-
-	return batch, nil
+	return merkleRoot, verdicts, err
 }
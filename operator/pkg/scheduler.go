@@ -0,0 +1,276 @@
+package operator
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// VerificationQueueSize is the number of verifications that can wait in the queue of each proving system.
+// Submitting more work blocks the caller until a slot frees up.
+const VerificationQueueSize = 4096
+
+// VerificationScheduler runs verifications on a bounded set of workers.
+// Each proving system has its own queue served by a fixed number of workers,
+// and a global limit caps how many verifications run at the same time across all proving systems.
+// Verifiers still running after their verification timed out free the worker of their proving system
+// but keep their global slot, up to a limit so stuck verifiers can't take every slot, see SubmitVerification.
+type VerificationScheduler struct {
+	globalSlots        chan struct{}
+	defaultLimit       int
+	limits             map[common.ProvingSystemId]int
+	queues             map[common.ProvingSystemId]*verificationQueue
+	onQueueDepthChange func(provingSystem common.ProvingSystemId, depth int)
+	maxAbandoned       int
+	abandoned          int
+	abandonedHolding   int
+	onAbandonedChange  func(abandoned int)
+	onAbandon          func(provingSystem common.ProvingSystemId, holdsSlot bool)
+	mutex              sync.Mutex
+}
+
+type verificationQueue struct {
+	tasks chan func() <-chan struct{}
+	depth int
+	mutex sync.Mutex
+}
+
+// NewVerificationScheduler creates a scheduler running at most maxWorkers verifications concurrently.
+// limits optionally caps the concurrency of specific proving systems; the rest can use up to maxWorkers.
+// If maxWorkers is 0, the number of CPUs is used.
+func NewVerificationScheduler(maxWorkers int, limits map[common.ProvingSystemId]int) (*VerificationScheduler, error) {
+	if maxWorkers < 0 {
+		return nil, fmt.Errorf("invalid max verification workers: %d", maxWorkers)
+	}
+	if maxWorkers == 0 {
+		maxWorkers = runtime.NumCPU()
+	}
+	for provingSystem, limit := range limits {
+		if limit <= 0 {
+			return nil, fmt.Errorf("invalid verification workers limit %d for proving system %d", limit, provingSystem)
+		}
+	}
+
+	return &VerificationScheduler{
+		globalSlots:  make(chan struct{}, maxWorkers),
+		defaultLimit: maxWorkers,
+		limits:       limits,
+		queues:       make(map[common.ProvingSystemId]*verificationQueue),
+		maxAbandoned: defaultMaxAbandonedVerifiers(maxWorkers),
+	}, nil
+}
+
+// defaultMaxAbandonedVerifiers is the number of abandoned verifiers that can hold a global slot
+// when `verification_max_abandoned_verifiers` is not set: half of the slots, so the other half keeps verifying
+func defaultMaxAbandonedVerifiers(maxWorkers int) int {
+	return max(maxWorkers/2, 1)
+}
+
+// SetMaxAbandonedVerifiers sets how many abandoned verifiers can hold a global slot, see SubmitVerification
+func (s *VerificationScheduler) SetMaxAbandonedVerifiers(maxAbandoned int) error {
+	if maxAbandoned < 0 {
+		return fmt.Errorf("invalid max abandoned verifiers: %d", maxAbandoned)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.maxAbandoned = maxAbandoned
+	return nil
+}
+
+// OnAbandonedChange sets a callback invoked every time the number of abandoned verifiers still running changes,
+// with the mutex held
+func (s *VerificationScheduler) OnAbandonedChange(callback func(abandoned int)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.onAbandonedChange = callback
+}
+
+// OnAbandon sets a callback invoked every time a verifier is abandoned, telling whether it holds a global slot
+func (s *VerificationScheduler) OnAbandon(callback func(provingSystem common.ProvingSystemId, holdsSlot bool)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.onAbandon = callback
+}
+
+// OnQueueDepthChange sets a callback invoked every time the queue depth of a proving system changes
+func (s *VerificationScheduler) OnQueueDepthChange(callback func(provingSystem common.ProvingSystemId, depth int)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.onQueueDepthChange = callback
+}
+
+// Submit enqueues task in the queue of the given proving system.
+// The task runs asynchronously once both a proving system worker and a global slot are available.
+func (s *VerificationScheduler) Submit(provingSystem common.ProvingSystemId, task func()) {
+	s.SubmitVerification(provingSystem, func() <-chan struct{} {
+		task()
+		return nil
+	})
+}
+
+// SubmitVerification is like Submit, for tasks that may leave a verifier running in the background once they return,
+// like a verifier that timed out but can't be interrupted. task returns a channel closed once that verifier returns,
+// or nil if none was left running. The worker of the proving system is freed as soon as task returns, so the queued
+// verifications go on, but the abandoned verifier keeps the global slot until it returns. Once the max abandoned
+// verifiers hold their slot, the rest keep running without one, so the operator can still verify other proofs.
+func (s *VerificationScheduler) SubmitVerification(provingSystem common.ProvingSystemId, task func() <-chan struct{}) {
+	queue := s.queueFor(provingSystem)
+
+	s.updateDepth(provingSystem, queue, 1)
+	queue.tasks <- task
+}
+
+// QueueDepth returns the number of verifications waiting for a worker, by proving system
+func (s *VerificationScheduler) QueueDepth() map[common.ProvingSystemId]int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	depths := make(map[common.ProvingSystemId]int, len(s.queues))
+	for provingSystem, queue := range s.queues {
+		queue.mutex.Lock()
+		depths[provingSystem] = queue.depth
+		queue.mutex.Unlock()
+	}
+	return depths
+}
+
+// queueFor returns the queue of the given proving system, starting its workers on first use
+func (s *VerificationScheduler) queueFor(provingSystem common.ProvingSystemId) *verificationQueue {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	queue, ok := s.queues[provingSystem]
+	if ok {
+		return queue
+	}
+
+	queue = &verificationQueue{tasks: make(chan func() <-chan struct{}, VerificationQueueSize)}
+	s.queues[provingSystem] = queue
+
+	workers, ok := s.limits[provingSystem]
+	if !ok || workers > s.defaultLimit {
+		workers = s.defaultLimit
+	}
+	for i := 0; i < workers; i++ {
+		go s.work(provingSystem, queue)
+	}
+
+	return queue
+}
+
+func (s *VerificationScheduler) work(provingSystem common.ProvingSystemId, queue *verificationQueue) {
+	for task := range queue.tasks {
+		s.globalSlots <- struct{}{}
+		s.updateDepth(provingSystem, queue, -1)
+		verifierDone := task()
+		if verifierDone == nil {
+			<-s.globalSlots
+			continue
+		}
+		select {
+		case <-verifierDone:
+			<-s.globalSlots
+		default:
+			s.abandon(provingSystem, verifierDone)
+		}
+	}
+}
+
+// abandon tracks a verifier still running after its verification timed out, until done is closed.
+// The global slot of its task is released then, or right away if the max abandoned verifiers already hold theirs.
+func (s *VerificationScheduler) abandon(provingSystem common.ProvingSystemId, done <-chan struct{}) {
+	s.mutex.Lock()
+	holdSlot := s.abandonedHolding < s.maxAbandoned
+	if holdSlot {
+		s.abandonedHolding++
+	}
+	s.abandoned++
+	s.notifyAbandonedLocked()
+	onAbandon := s.onAbandon
+	s.mutex.Unlock()
+
+	if !holdSlot {
+		<-s.globalSlots
+	}
+	if onAbandon != nil {
+		onAbandon(provingSystem, holdSlot)
+	}
+
+	go func() {
+		<-done
+		if holdSlot {
+			<-s.globalSlots
+		}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if holdSlot {
+			s.abandonedHolding--
+		}
+		s.abandoned--
+		s.notifyAbandonedLocked()
+	}()
+}
+
+// Abandoned returns the number of abandoned verifiers still running
+func (s *VerificationScheduler) Abandoned() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.abandoned
+}
+
+// notifyAbandonedLocked must be called with the mutex held
+func (s *VerificationScheduler) notifyAbandonedLocked() {
+	if s.onAbandonedChange != nil {
+		s.onAbandonedChange(s.abandoned)
+	}
+}
+
+func (s *VerificationScheduler) updateDepth(provingSystem common.ProvingSystemId, queue *verificationQueue, delta int) {
+	queue.mutex.Lock()
+	queue.depth += delta
+	depth := queue.depth
+	queue.mutex.Unlock()
+
+	s.mutex.Lock()
+	callback := s.onQueueDepthChange
+	s.mutex.Unlock()
+
+	if callback != nil {
+		callback(provingSystem, depth)
+	}
+}
+
+// newVerificationSchedulerFromConfig builds the scheduler from the `verification_max_workers`,
+// `verification_workers_limits` and `verification_max_abandoned_verifiers` operator config fields
+func newVerificationSchedulerFromConfig(configuration config.OperatorConfig) (*VerificationScheduler, error) {
+	limits := make(map[common.ProvingSystemId]int)
+	for provingSystemName, limit := range configuration.Operator.VerificationWorkersLimits {
+		provingSystem, err := common.ProvingSystemIdFromString(provingSystemName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid `verification_workers_limits` entry: %w", err)
+		}
+		limits[provingSystem] = limit
+	}
+
+	scheduler, err := NewVerificationScheduler(configuration.Operator.VerificationMaxWorkers, limits)
+	if err != nil {
+		return nil, err
+	}
+	if maxAbandoned := configuration.Operator.VerificationMaxAbandoned; maxAbandoned != 0 {
+		if err := scheduler.SetMaxAbandonedVerifiers(maxAbandoned); err != nil {
+			return nil, fmt.Errorf("invalid `verification_max_abandoned_verifiers`: %w", err)
+		}
+	}
+	return scheduler, nil
+}
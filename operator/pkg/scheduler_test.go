@@ -0,0 +1,161 @@
+package operator
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+// runConcurrently submits n tasks and returns the max number of them that ran at the same time
+func runConcurrently(scheduler *VerificationScheduler, provingSystems []common.ProvingSystemId, n int) int64 {
+	var running, maxRunning atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(n)
+
+	for i := 0; i < n; i++ {
+		scheduler.Submit(provingSystems[i%len(provingSystems)], func() {
+			defer wg.Done()
+			current := running.Add(1)
+			for {
+				observed := maxRunning.Load()
+				if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		})
+	}
+
+	wg.Wait()
+	return maxRunning.Load()
+}
+
+func TestVerificationSchedulerGlobalLimit(t *testing.T) {
+	scheduler, err := NewVerificationScheduler(3, nil)
+	if err != nil {
+		t.Fatalf("could not create scheduler: %s", err)
+	}
+
+	maxRunning := runConcurrently(scheduler, []common.ProvingSystemId{common.SP1, common.Risc0, common.GnarkPlonkBn254}, 30)
+	if maxRunning > 3 {
+		t.Errorf("global limit exceeded: %d verifications ran concurrently", maxRunning)
+	}
+}
+
+func TestVerificationSchedulerProvingSystemLimit(t *testing.T) {
+	scheduler, err := NewVerificationScheduler(8, map[common.ProvingSystemId]int{common.SP1: 2})
+	if err != nil {
+		t.Fatalf("could not create scheduler: %s", err)
+	}
+
+	maxRunning := runConcurrently(scheduler, []common.ProvingSystemId{common.SP1}, 20)
+	if maxRunning > 2 {
+		t.Errorf("SP1 limit exceeded: %d verifications ran concurrently", maxRunning)
+	}
+
+	if depth := scheduler.QueueDepth()[common.SP1]; depth != 0 {
+		t.Errorf("expected empty SP1 queue, got depth %d", depth)
+	}
+}
+
+func TestVerificationSchedulerRejectsInvalidLimits(t *testing.T) {
+	if _, err := NewVerificationScheduler(-1, nil); err == nil {
+		t.Errorf("expected an error for a negative global limit")
+	}
+	if _, err := NewVerificationScheduler(4, map[common.ProvingSystemId]int{common.Risc0: 0}); err == nil {
+		t.Errorf("expected an error for a zero proving system limit")
+	}
+}
+
+func TestVerificationSchedulerCapsAbandonedVerifiers(t *testing.T) {
+	scheduler, err := NewVerificationScheduler(2, nil)
+	if err != nil {
+		t.Fatalf("could not create scheduler: %s", err)
+	}
+	if err := scheduler.SetMaxAbandonedVerifiers(1); err != nil {
+		t.Fatalf("could not set max abandoned verifiers: %s", err)
+	}
+
+	held := make(chan bool, 2)
+	scheduler.OnAbandon(func(provingSystem common.ProvingSystemId, holdsSlot bool) { held <- holdsSlot })
+
+	// Both verifiers time out and never return, but only one of them keeps its slot
+	stuck := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		scheduler.SubmitVerification(common.SP1, func() <-chan struct{} { return stuck })
+	}
+	if first, second := <-held, <-held; first == second {
+		t.Errorf("expected a single abandoned verifier to hold its slot, got %t and %t", first, second)
+	}
+	if abandoned := scheduler.Abandoned(); abandoned != 2 {
+		t.Errorf("expected 2 abandoned verifiers, got %d", abandoned)
+	}
+
+	ran := make(chan struct{})
+	scheduler.Submit(common.GnarkPlonkBn254, func() { close(ran) })
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("abandoned verifiers took every slot")
+	}
+
+	close(stuck)
+	deadline := time.Now().Add(5 * time.Second)
+	for scheduler.Abandoned() != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if abandoned := scheduler.Abandoned(); abandoned != 0 {
+		t.Errorf("expected no abandoned verifiers once they returned, got %d", abandoned)
+	}
+}
+
+func TestProofVerificationsGoOnAfterTheOnlyWorkerIsAbandoned(t *testing.T) {
+	verifier := &blockingVerifier{release: make(chan struct{})}
+	defer close(verifier.release)
+	verifiers := NewVerifierRegistry()
+	verifiers.Replace(common.SP1, verifier)
+	scheduler, err := NewVerificationScheduler(4, map[common.ProvingSystemId]int{common.SP1: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationTimeouts, err := NewVerificationTimeouts(10*time.Millisecond, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verificationCache, err := NewVerificationCache(0, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := &Operator{
+		Logger:               newTestLogger(t),
+		verifiers:            verifiers,
+		scheduler:            scheduler,
+		verificationCache:    verificationCache,
+		verificationTimeouts: verificationTimeouts,
+		metrics:              newTestMetrics(t),
+	}
+
+	// The first verifier never returns, and SP1 has a single worker
+	verifications := operator.startProofVerifications(context.Background(), big.NewInt(0), false, nil)
+	verifications.Submit(VerificationData{ProvingSystemId: common.SP1, Proof: []byte{1}})
+	verifications.Submit(VerificationData{ProvingSystemId: common.SP1, Proof: []byte{2}})
+
+	verdictsChan := make(chan []ProofVerdict, 1)
+	go func() { verdictsChan <- verifications.Wait() }()
+	select {
+	case verdicts := <-verdictsChan:
+		for _, verdict := range verdicts {
+			if verdict.Outcome != OutcomeTimeout {
+				t.Errorf("expected every proof to time out, got %s", verdict)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second SP1 proof never ran after the first verifier was abandoned")
+	}
+}
@@ -0,0 +1,132 @@
+package operator
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/rpc"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/crypto/bls"
+	"github.com/yetanotherco/aligned_layer/core/types"
+	"github.com/yetanotherco/aligned_layer/core/utils"
+)
+
+// unresponsiveAggregator accepts signed task responses but never replies to them
+type unresponsiveAggregator struct {
+	received chan struct{}
+	release  chan struct{}
+}
+
+func (a *unresponsiveAggregator) ProcessOperatorSignedTaskResponseV2(signedTaskResponse *types.SignedTaskResponse, reply *uint8) error {
+	a.received <- struct{}{}
+	<-a.release
+	return nil
+}
+
+func TestShutdownCheckpointsBatchesInFlight(t *testing.T) {
+	aggregator := &unresponsiveAggregator{received: make(chan struct{}, 1), release: make(chan struct{})}
+	defer close(aggregator.release)
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("Aggregator", aggregator); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(rpcServer)
+	defer server.Close()
+
+	logger := newTestLogger(t)
+	aggregators, err := NewAggregatorFanOut([]string{strings.TrimPrefix(server.URL, "http://")}, AggregatorDeliveryAll, AggregatorProtocolGob, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal, err := OpenTaskJournal(filepath.Join(t.TempDir(), "journal.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	keyPair, err := bls.NewKeyPairFromString("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operator := &Operator{
+		Logger:        logger,
+		aggregators:   aggregators,
+		taskJournal:   journal,
+		tasksInFlight: make(map[[32]byte]struct{}),
+		blsSigner:     NewLocalBlsSigner(keyPair),
+		metrics:       newTestMetrics(t),
+	}
+
+	record := newTestTaskRecord(1, 10, TaskStageVerified)
+	record.BatchIdentifierHash = computeBatchIdentifierHash(record.BatchMerkleRoot, record.SenderAddress)
+	if err := journal.Put(record); err != nil {
+		t.Fatal(err)
+	}
+
+	tasksCtx, cancelTasks := context.WithCancelCause(context.Background())
+	operator.goTask(func() { operator.resumeTask(tasksCtx, record) })
+	select {
+	case <-aggregator.received:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the signed task response was not sent")
+	}
+
+	// Once shutting down, no new batch is claimed
+	operator.tasksInFlightMutex.Lock()
+	operator.shuttingDown = true
+	operator.tasksInFlightMutex.Unlock()
+	if _, ok := operator.startTask(newTestTaskRecord(2, 11, TaskStageReceived)); ok {
+		t.Error("expected no batch to be claimed while shutting down")
+	}
+
+	// The batch in flight is cancelled as in the shutdown timeout, and left to be resumed
+	cancelTasks(ErrOperatorShutdown)
+	if !utils.WaitTimeout(&operator.tasks, 5*time.Second) {
+		t.Fatal("the batch in flight did not stop after being cancelled")
+	}
+	journaled, ok := journal.Get(record.BatchIdentifierHash)
+	if !ok || journaled.Stage != TaskStageSigned || journaled.Error != ErrOperatorShutdown.Error() {
+		t.Errorf("expected the batch to be journaled as signed with the shutdown error, got %+v", journaled)
+	}
+	if unfinished := journal.Unfinished(); len(unfinished) != 1 {
+		t.Errorf("expected the batch to be resumed on the next start, got %d unfinished batches", len(unfinished))
+	}
+}
+
+func TestShutdownClosesResources(t *testing.T) {
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("Aggregator", &unresponsiveAggregator{}); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(rpcServer)
+	defer server.Close()
+
+	logger := newTestLogger(t)
+	aggregators, err := NewAggregatorFanOut([]string{strings.TrimPrefix(server.URL, "http://")}, AggregatorDeliveryAll, AggregatorProtocolGob, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal, err := OpenTaskJournal(filepath.Join(t.TempDir(), "journal.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator := &Operator{
+		Logger:        logger,
+		aggregators:   aggregators,
+		taskJournal:   journal,
+		tasksInFlight: make(map[[32]byte]struct{}),
+	}
+
+	_, cancelTasks := context.WithCancelCause(context.Background())
+	operator.shutdown(cancelTasks)
+
+	if err := journal.Put(newTestTaskRecord(1, 10, TaskStageReceived)); err == nil {
+		t.Error("expected the task journal to be closed")
+	}
+	if conn := aggregators.aggregators[0].conn; conn != nil {
+		t.Error("expected the aggregator connection to be closed")
+	}
+}
@@ -0,0 +1,330 @@
+package operator
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+const (
+	// StatusRefreshInterval is how often the aggregator connectivity and the operator registration are checked
+	StatusRefreshInterval = 30 * time.Second
+	// StatusHeartbeatInterval is how often the main loop of the operator records it's alive
+	StatusHeartbeatInterval = 10 * time.Second
+	// MaxStatusHeartbeatAge is how long the main loop can go without a heartbeat before the operator is unhealthy
+	MaxStatusHeartbeatAge = 6 * StatusHeartbeatInterval
+	// AggregatorDialTimeout bounds the connectivity check of the aggregator
+	AggregatorDialTimeout = 5 * time.Second
+)
+
+// Names of the websocket subscriptions in the operator status
+const (
+	SubscriptionNewBatchV2 = "new_batch_v2"
+	SubscriptionNewBatchV3 = "new_batch_v3"
+)
+
+// SubscriptionStatus is the state of a websocket subscription to new batches
+type SubscriptionStatus struct {
+	Connected bool      `json:"connected"`
+	Since     time.Time `json:"since"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// CheckStatus is the result of a check periodically run against another service.
+// A check never run has a zero CheckedAt.
+type CheckStatus struct {
+	Ok        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// OperatorStatus is the JSON document served at `/status`
+type OperatorStatus struct {
+	// Healthy is false if the main loop of the operator is stuck
+	Healthy bool `json:"healthy"`
+	// Ready is true if the operator can verify, sign and send batches
+	Ready           bool     `json:"ready"`
+	NotReadyReasons []string `json:"not_ready_reasons,omitempty"`
+
+	OperatorAddress string                        `json:"operator_address"`
+	OperatorId      string                        `json:"operator_id"`
+	Subscriptions   map[string]SubscriptionStatus `json:"subscriptions"`
+	// LastProcessedBlock is the highest block of a batch the operator finished with, or 0 if none was.
+	// Unlike LastSeenBlock, it doesn't move forward while batches are stuck in the pipeline.
+	LastProcessedBlock uint64 `json:"last_processed_block"`
+	// LastSeenBlock is the block of the last new batch event received from the subscriptions, or 0 if none was
+	LastSeenBlock   uint64      `json:"last_seen_block"`
+	BatchesInFlight int         `json:"batches_in_flight"`
+	Aggregator      CheckStatus `json:"aggregator"`
+	Registered      CheckStatus `json:"registered"`
+	// VerifierSelfTest has "ok" or the error of the self test of each proving system
+	VerifierSelfTest map[string]string `json:"verifier_self_test"`
+	LastHeartbeat    time.Time         `json:"last_heartbeat"`
+}
+
+// operatorHealth keeps the state reported by the status server, updated by the operator as it runs
+type operatorHealth struct {
+	subscriptions      map[string]SubscriptionStatus
+	aggregator         CheckStatus
+	registered         CheckStatus
+	selfTest           map[string]string
+	heartbeat          time.Time
+	lastSeenBlock      uint64
+	lastProcessedBlock uint64
+	mutex              sync.Mutex
+}
+
+func newOperatorHealth() *operatorHealth {
+	return &operatorHealth{
+		subscriptions: map[string]SubscriptionStatus{
+			SubscriptionNewBatchV2: {},
+			SubscriptionNewBatchV3: {},
+		},
+		selfTest: make(map[string]string),
+	}
+}
+
+// setSubscription records a subscription as connected if err is nil, or as disconnected with err otherwise
+func (h *operatorHealth) setSubscription(name string, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	subscription := h.subscriptions[name]
+	connected := err == nil
+	if subscription.Connected != connected || subscription.Since.IsZero() {
+		subscription.Since = time.Now()
+	}
+	subscription.Connected = connected
+	if err != nil {
+		subscription.LastError = err.Error()
+	}
+	h.subscriptions[name] = subscription
+}
+
+func (h *operatorHealth) setSelfTest(provingSystems []common.ProvingSystemId, failures map[common.ProvingSystemId]error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, provingSystem := range provingSystems {
+		name, err := common.ProvingSystemIdToString(provingSystem)
+		if err != nil {
+			name = fmt.Sprintf("%d", provingSystem)
+		}
+		h.selfTest[name] = "ok"
+		if failure, ok := failures[provingSystem]; ok {
+			h.selfTest[name] = failure.Error()
+		}
+	}
+}
+
+func (h *operatorHealth) setChecks(aggregator CheckStatus, registered CheckStatus) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.aggregator = aggregator
+	h.registered = registered
+}
+
+// recordBlock records the block of a new batch event received from a subscription
+func (h *operatorHealth) recordBlock(block uint64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.lastSeenBlock = max(h.lastSeenBlock, block)
+}
+
+// recordProcessedBlock records the block of a batch that reached a final stage of the task journal
+func (h *operatorHealth) recordProcessedBlock(block uint64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.lastProcessedBlock = max(h.lastProcessedBlock, block)
+}
+
+func (h *operatorHealth) recordHeartbeat() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.heartbeat = time.Now()
+}
+
+// fill copies the recorded state to status and evaluates it
+func (h *operatorHealth) fill(status *OperatorStatus) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	status.Subscriptions = make(map[string]SubscriptionStatus, len(h.subscriptions))
+	for name, subscription := range h.subscriptions {
+		status.Subscriptions[name] = subscription
+	}
+	status.VerifierSelfTest = make(map[string]string, len(h.selfTest))
+	for name, result := range h.selfTest {
+		status.VerifierSelfTest[name] = result
+	}
+	status.Aggregator = h.aggregator
+	status.Registered = h.registered
+	status.LastHeartbeat = h.heartbeat
+	status.LastSeenBlock = h.lastSeenBlock
+	status.LastProcessedBlock = h.lastProcessedBlock
+
+	status.Healthy = !h.heartbeat.IsZero() && time.Since(h.heartbeat) <= MaxStatusHeartbeatAge
+
+	var reasons []string
+	if !status.Healthy {
+		reasons = append(reasons, "main loop is not running")
+	}
+	for name, subscription := range status.Subscriptions {
+		if !subscription.Connected {
+			reasons = append(reasons, fmt.Sprintf("subscription %s is not connected", name))
+		}
+	}
+	if !status.Aggregator.Ok {
+		reasons = append(reasons, "aggregator is not reachable")
+	}
+	if !status.Registered.Ok {
+		reasons = append(reasons, "operator is not registered")
+	}
+	for name, result := range status.VerifierSelfTest {
+		if result != "ok" {
+			reasons = append(reasons, fmt.Sprintf("verifier self test of %s failed", name))
+		}
+	}
+	sort.Strings(reasons)
+	status.NotReadyReasons = reasons
+	status.Ready = len(reasons) == 0
+}
+
+// Status returns the current status of the operator
+func (o *Operator) Status() OperatorStatus {
+	o.tasksInFlightMutex.Lock()
+	batchesInFlight := len(o.tasksInFlight)
+	o.tasksInFlightMutex.Unlock()
+
+	status := OperatorStatus{
+		OperatorAddress: o.Address.Hex(),
+		OperatorId:      "0x" + hex.EncodeToString(o.OperatorId[:]),
+		BatchesInFlight: batchesInFlight,
+	}
+	o.health.fill(&status)
+	return status
+}
+
+// refreshStatusChecks checks the connectivity of any aggregator and the operator registration
+func (o *Operator) refreshStatusChecks() {
+	aggregator := CheckStatus{Ok: true, CheckedAt: time.Now()}
+	if err := o.aggregators.Ping(AggregatorDialTimeout); err != nil {
+		aggregator = CheckStatus{Error: err.Error(), CheckedAt: time.Now()}
+	}
+
+	registered := CheckStatus{CheckedAt: time.Now()}
+	isRegistered, err := o.avsReader.IsOperatorRegistered(o.Address)
+	switch {
+	case err != nil:
+		registered.Error = err.Error()
+	case !isRegistered:
+		registered.Error = "operator is not registered on the AVS"
+	default:
+		registered.Ok = true
+	}
+
+	o.health.setChecks(aggregator, registered)
+}
+
+// StartStatusServer serves the status of the operator at ipPortAddress and refreshes
+// the checks of other services every StatusRefreshInterval, until ctx is done
+func (o *Operator) StartStatusServer(ctx context.Context, ipPortAddress string) <-chan error {
+	go func() {
+		ticker := time.NewTicker(StatusRefreshInterval)
+		defer ticker.Stop()
+		for {
+			o.refreshStatusChecks()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return NewStatusServer(ipPortAddress, o.Status, o.Logger).Start(ctx)
+}
+
+// StatusServer serves the status of the operator over HTTP:
+//   - `/healthz` responds 200 while the operator main loop is running, for liveness probes
+//   - `/readyz` responds 200 if the operator is ready to handle batches, for readiness probes
+//   - `/status` responds the whole OperatorStatus as JSON
+//
+// `/healthz` and `/readyz` respond 503 otherwise, with the reasons in the body.
+type StatusServer struct {
+	ipPortAddress string
+	status        func() OperatorStatus
+	logger        logging.Logger
+}
+
+func NewStatusServer(ipPortAddress string, status func() OperatorStatus, logger logging.Logger) *StatusServer {
+	return &StatusServer{ipPortAddress: ipPortAddress, status: status, logger: logger}
+}
+
+func (s *StatusServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if status := s.status(); !status.Healthy {
+			http.Error(w, "main loop is not running", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status := s.status()
+		if !status.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			for _, reason := range status.NotReadyReasons {
+				fmt.Fprintln(w, reason)
+			}
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(s.status()); err != nil {
+			s.logger.Errorf("Could not encode operator status: %v", err)
+		}
+	})
+	return mux
+}
+
+// Start serves the status until ctx is done. The returned channel receives the error if the server fails.
+func (s *StatusServer) Start(ctx context.Context) <-chan error {
+	s.logger.Infof("Starting status server at %v", s.ipPortAddress)
+	errC := make(chan error, 1)
+
+	server := &http.Server{
+		Addr:              s.ipPortAddress,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errC <- fmt.Errorf("status server failed: %w", err)
+		}
+	}()
+	return errC
+}
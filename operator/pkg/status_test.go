@@ -0,0 +1,119 @@
+package operator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+func getStatusEndpoint(t *testing.T, server *httptest.Server, path string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestStatusServerReadiness(t *testing.T) {
+	health := newOperatorHealth()
+	health.setSelfTest([]common.ProvingSystemId{common.GnarkPlonkBn254, common.Groth16Bn254}, nil)
+	status := func() OperatorStatus {
+		status := OperatorStatus{BatchesInFlight: 2}
+		health.fill(&status)
+		return status
+	}
+	server := httptest.NewServer(NewStatusServer("", status, newTestLogger(t)).Handler())
+	defer server.Close()
+
+	// Nothing is running yet
+	if code, _ := getStatusEndpoint(t, server, "/healthz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected /healthz to fail before the first heartbeat, got %d", code)
+	}
+
+	health.recordHeartbeat()
+	health.setSubscription(SubscriptionNewBatchV2, nil)
+	health.setSubscription(SubscriptionNewBatchV3, errors.New("websocket closed"))
+	health.setChecks(CheckStatus{Ok: true, CheckedAt: time.Now()}, CheckStatus{Ok: true, CheckedAt: time.Now()})
+
+	if code, _ := getStatusEndpoint(t, server, "/healthz"); code != http.StatusOK {
+		t.Errorf("expected /healthz to succeed once the main loop runs, got %d", code)
+	}
+	code, body := getStatusEndpoint(t, server, "/readyz")
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "subscription new_batch_v3 is not connected") {
+		t.Errorf("expected /readyz to fail while a subscription is down, got %d %q", code, body)
+	}
+
+	health.setSubscription(SubscriptionNewBatchV3, nil)
+	health.recordBlock(120)
+	health.recordBlock(110)
+	if code, body := getStatusEndpoint(t, server, "/readyz"); code != http.StatusOK {
+		t.Errorf("expected /readyz to succeed, got %d %q", code, body)
+	}
+
+	health.setSelfTest([]common.ProvingSystemId{common.Groth16Bn254}, map[common.ProvingSystemId]error{common.Groth16Bn254: errors.New("bad vector")})
+	if code, body := getStatusEndpoint(t, server, "/readyz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "Groth16Bn254") {
+		t.Errorf("expected /readyz to fail after a failed self test, got %d %q", code, body)
+	}
+
+	code, body = getStatusEndpoint(t, server, "/status")
+	var document OperatorStatus
+	if err := json.Unmarshal([]byte(body), &document); err != nil || code != http.StatusOK {
+		t.Fatalf("expected a JSON status, got %d %q: %v", code, body, err)
+	}
+	if !document.Healthy || document.Ready || document.BatchesInFlight != 2 || document.LastSeenBlock != 120 || document.VerifierSelfTest["Groth16Bn254"] != "bad vector" {
+		t.Errorf("unexpected status: %+v", document)
+	}
+	if subscription := document.Subscriptions[SubscriptionNewBatchV3]; !subscription.Connected || subscription.LastError != "websocket closed" {
+		t.Errorf("expected the subscription to be connected and keep its last error, got %+v", subscription)
+	}
+}
+
+func TestStatusReportsLastProcessedBlock(t *testing.T) {
+	journal, err := OpenTaskJournal(filepath.Join(t.TempDir(), "journal.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	operator := &Operator{
+		Logger:        newTestLogger(t),
+		taskJournal:   journal,
+		tasksInFlight: make(map[[32]byte]struct{}),
+		health:        newOperatorHealth(),
+	}
+
+	finished := newTestTaskRecord(1, 100, TaskStageReceived)
+	stuck := newTestTaskRecord(2, 120, TaskStageReceived)
+	for _, record := range []TaskRecord{finished, stuck} {
+		if err := journal.Put(record); err != nil {
+			t.Fatal(err)
+		}
+		operator.health.recordBlock(record.BlockNumber)
+	}
+
+	operator.advanceTask(finished.BatchIdentifierHash, TaskStageVerified, nil)
+	operator.advanceTask(stuck.BatchIdentifierHash, TaskStageDownloaded, nil)
+	if status := operator.Status(); status.LastProcessedBlock != 0 || status.LastSeenBlock != 120 {
+		t.Errorf("expected no processed block before a batch is finished, got %+v", status)
+	}
+
+	operator.advanceTask(finished.BatchIdentifierHash, TaskStageSent, nil)
+	// A failed step doesn't finish the batch
+	operator.advanceTask(stuck.BatchIdentifierHash, TaskStageSent, errors.New("aggregator unreachable"))
+	if status := operator.Status(); status.LastProcessedBlock != 100 {
+		t.Errorf("expected the block of the finished batch to be processed, got %d", status.LastProcessedBlock)
+	}
+}
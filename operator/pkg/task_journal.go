@@ -0,0 +1,310 @@
+package operator
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// TaskStage is the last step completed by the operator on a batch
+type TaskStage string
+
+const (
+	TaskStageReceived   TaskStage = "received"
+	TaskStageDownloaded TaskStage = "downloaded"
+	TaskStageVerified   TaskStage = "verified"
+	TaskStageSigned     TaskStage = "signed"
+	// TaskStageSent means the signed response was accepted by the aggregator
+	TaskStageSent TaskStage = "sent"
+	// TaskStageRejected means some proof of the batch did not verify, so it's never signed
+	TaskStageRejected TaskStage = "rejected"
+	// TaskStageResponded means the batch was responded on chain before the operator finished it
+	TaskStageResponded TaskStage = "responded"
+	// TaskStageAbandoned means the batch was left unfinished for longer than the unfinished task retention,
+	// well past the time the aggregator waits for responses, so it's not resumed anymore
+	TaskStageAbandoned TaskStage = "abandoned"
+)
+
+// IsFinal reports whether nothing is left to do for a batch in this stage
+func (s TaskStage) IsFinal() bool {
+	return s == TaskStageSent || s == TaskStageRejected || s == TaskStageResponded || s == TaskStageAbandoned
+}
+
+const (
+	// DefaultTaskJournalFileName is used next to the last processed batch file when `task_journal_filepath` is not set
+	DefaultTaskJournalFileName = "task_journal.jsonl"
+	// TaskJournalRetention is how long finished tasks are kept in the journal
+	TaskJournalRetention = 7 * 24 * time.Hour
+	// DefaultUnfinishedTaskRetention is how long a task can stay unfinished before it's abandoned,
+	// when `task_journal_unfinished_retention` is not set
+	DefaultUnfinishedTaskRetention = time.Hour
+	// taskJournalCompactionThreshold is the number of appended lines that triggers a compaction,
+	// on top of one line per task kept
+	taskJournalCompactionThreshold = 1024
+)
+
+// TaskRecord is the state of a batch in the task journal.
+// It keeps everything needed to resume the batch after a restart.
+type TaskRecord struct {
+	BatchIdentifierHash   ethcommon.Hash          `json:"batch_identifier_hash"`
+	BatchMerkleRoot       ethcommon.Hash          `json:"batch_merkle_root"`
+	SenderAddress         ethcommon.Address       `json:"sender_address"`
+	BatchDataPointer      string                  `json:"batch_data_pointer"`
+	TaskCreatedBlock      uint32                  `json:"task_created_block"`
+	RespondToTaskFeeLimit *big.Int                `json:"respond_to_task_fee_limit,omitempty"`
+	BlockNumber           uint64                  `json:"block_number"`
+	EventVersion          int                     `json:"event_version"`
+	Stage                 TaskStage               `json:"stage"`
+	Error                 string                  `json:"error,omitempty"`
+	Timestamps            map[TaskStage]time.Time `json:"timestamps"`
+	UpdatedAt             time.Time               `json:"updated_at"`
+}
+
+// TaskJournal is a crash safe record of the batches handled by the operator.
+// Every update is appended to the journal file as a JSON line and synced before returning,
+// so the latest line of each batch is its current state. A torn line left by a crash is ignored on load.
+// The file is compacted by writing the current records to a new file and renaming it over the old one.
+type TaskJournal struct {
+	path                string
+	file                *os.File
+	records             map[ethcommon.Hash]*TaskRecord
+	appended            int
+	unfinishedRetention time.Duration
+	mutex               sync.Mutex
+}
+
+// OpenTaskJournal loads the journal at path, creating it if it doesn't exist.
+// Tasks received more than unfinishedRetention ago and still unfinished are abandoned on every compaction,
+// so they are not resumed after each restart. An unfinishedRetention of 0 uses DefaultUnfinishedTaskRetention.
+func OpenTaskJournal(path string, unfinishedRetention time.Duration) (*TaskJournal, error) {
+	if unfinishedRetention < 0 {
+		return nil, fmt.Errorf("invalid unfinished task retention: %s", unfinishedRetention)
+	}
+	if unfinishedRetention == 0 {
+		unfinishedRetention = DefaultUnfinishedTaskRetention
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("could not create task journal directory: %w", err)
+	}
+
+	journal := &TaskJournal{
+		path:                path,
+		records:             make(map[ethcommon.Hash]*TaskRecord),
+		unfinishedRetention: unfinishedRetention,
+	}
+	if err := journal.load(); err != nil {
+		return nil, err
+	}
+	if err := journal.compact(); err != nil {
+		return nil, err
+	}
+
+	return journal, nil
+}
+
+func (j *TaskJournal) load() error {
+	file, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not open task journal: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record TaskRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// Only the last line can be torn by a crash, and it's dropped by the compaction that follows
+			continue
+		}
+		j.records[record.BatchIdentifierHash] = &record
+	}
+	return scanner.Err()
+}
+
+// Get returns the record of a batch
+func (j *TaskJournal) Get(batchIdentifierHash [32]byte) (TaskRecord, bool) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	record, ok := j.records[batchIdentifierHash]
+	if !ok {
+		return TaskRecord{}, false
+	}
+	return *record, true
+}
+
+// Put stores record, stamping its stage and update time, and syncs it to disk
+func (j *TaskJournal) Put(record TaskRecord) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	now := time.Now()
+	timestamps := make(map[TaskStage]time.Time, len(record.Timestamps)+1)
+	for stage, timestamp := range record.Timestamps {
+		timestamps[stage] = timestamp
+	}
+	if _, ok := timestamps[record.Stage]; !ok {
+		timestamps[record.Stage] = now
+	}
+	record.Timestamps = timestamps
+	record.UpdatedAt = now
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write task journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("could not sync task journal: %w", err)
+	}
+	j.records[record.BatchIdentifierHash] = &record
+	j.appended++
+
+	if j.appended > len(j.records)+taskJournalCompactionThreshold {
+		return j.compact()
+	}
+	return nil
+}
+
+// Unfinished returns the records of the batches not in a final stage, oldest first
+func (j *TaskJournal) Unfinished() []TaskRecord {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var unfinished []TaskRecord
+	for _, record := range j.records {
+		if !record.Stage.IsFinal() {
+			unfinished = append(unfinished, *record)
+		}
+	}
+	sort.Slice(unfinished, func(a, b int) bool { return unfinished[a].BlockNumber < unfinished[b].BlockNumber })
+	return unfinished
+}
+
+// LatestBlock returns the highest block number of the recorded batches, or 0 if the journal is empty
+func (j *TaskJournal) LatestBlock() uint64 {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var latest uint64
+	for _, record := range j.records {
+		latest = max(latest, record.BlockNumber)
+	}
+	return latest
+}
+
+// LatestFinishedBlock returns the highest block number of the batches in a final stage, or 0 if there are none
+func (j *TaskJournal) LatestFinishedBlock() uint64 {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var latest uint64
+	for _, record := range j.records {
+		if record.Stage.IsFinal() {
+			latest = max(latest, record.BlockNumber)
+		}
+	}
+	return latest
+}
+
+func (j *TaskJournal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.file.Close()
+}
+
+// compact rewrites the journal with one line per record, dropping finished tasks older than
+// TaskJournalRetention except the latest one, which keeps track of the latest block seen.
+// Unfinished tasks received more than unfinishedRetention ago are marked as abandoned.
+// Must be called with the mutex held, or before the journal is shared.
+func (j *TaskJournal) compact() error {
+	now := time.Now()
+	for _, record := range j.records {
+		if !record.Stage.IsFinal() && now.Sub(record.receivedAt()) > j.unfinishedRetention {
+			record.Error = fmt.Sprintf("abandoned while %s, received more than %s ago", record.Stage, j.unfinishedRetention)
+			record.Stage = TaskStageAbandoned
+			// Records handed out by Get share the map, so it's copied instead of updated
+			timestamps := map[TaskStage]time.Time{TaskStageAbandoned: now}
+			for stage, timestamp := range record.Timestamps {
+				timestamps[stage] = timestamp
+			}
+			record.Timestamps = timestamps
+			record.UpdatedAt = now
+		}
+	}
+
+	var latest *TaskRecord
+	for _, record := range j.records {
+		if latest == nil || record.BlockNumber > latest.BlockNumber {
+			latest = record
+		}
+	}
+	for hash, record := range j.records {
+		if record.Stage.IsFinal() && time.Since(record.UpdatedAt) > TaskJournalRetention && record != latest {
+			delete(j.records, hash)
+		}
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not compact task journal: %w", err)
+	}
+	writer := bufio.NewWriter(tmpFile)
+	for _, record := range j.records {
+		line, err := json.Marshal(record)
+		if err == nil {
+			writer.Write(append(line, '\n'))
+		}
+	}
+	err = errors.Join(writer.Flush(), tmpFile.Sync(), tmpFile.Close())
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), j.path)
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return fmt.Errorf("could not compact task journal: %w", err)
+	}
+	syncDir(filepath.Dir(j.path))
+
+	if j.file != nil {
+		j.file.Close()
+	}
+	j.file, err = os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("could not open task journal: %w", err)
+	}
+	j.appended = 0
+	return nil
+}
+
+// receivedAt returns when the task was first journaled
+func (r *TaskRecord) receivedAt() time.Time {
+	if receivedAt, ok := r.Timestamps[TaskStageReceived]; ok {
+		return receivedAt
+	}
+	return r.UpdatedAt
+}
+
+// syncDir makes a rename in dir durable. Errors are ignored, as some platforms don't support it.
+func syncDir(dir string) {
+	if dirFile, err := os.Open(dir); err == nil {
+		dirFile.Sync()
+		dirFile.Close()
+	}
+}
@@ -0,0 +1,188 @@
+package operator
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+func newTestTaskRecord(id byte, blockNumber uint64, stage TaskStage) TaskRecord {
+	return TaskRecord{
+		BatchIdentifierHash:   ethcommon.Hash{id},
+		BatchMerkleRoot:       ethcommon.Hash{id, 1},
+		SenderAddress:         ethcommon.Address{id, 2},
+		BatchDataPointer:      "https://storage.alignedlayer.com/batch",
+		RespondToTaskFeeLimit: big.NewInt(1000),
+		BlockNumber:           blockNumber,
+		EventVersion:          3,
+		Stage:                 stage,
+	}
+}
+
+func TestTaskJournalSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenTaskJournal(path, 0)
+	if err != nil {
+		t.Fatalf("could not open task journal: %s", err)
+	}
+
+	record := newTestTaskRecord(1, 10, TaskStageReceived)
+	for _, stage := range []TaskStage{TaskStageReceived, TaskStageDownloaded, TaskStageVerified} {
+		record.Stage = stage
+		if err := journal.Put(record); err != nil {
+			t.Fatalf("could not update task journal: %s", err)
+		}
+		record, _ = journal.Get(record.BatchIdentifierHash)
+	}
+	journal.Close()
+
+	journal, err = OpenTaskJournal(path, 0)
+	if err != nil {
+		t.Fatalf("could not reopen task journal: %s", err)
+	}
+	defer journal.Close()
+
+	reloaded, ok := journal.Get(record.BatchIdentifierHash)
+	if !ok {
+		t.Fatal("record lost after reopening the journal")
+	}
+	if reloaded.Stage != TaskStageVerified || reloaded.RespondToTaskFeeLimit.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("unexpected record after reopening the journal: %+v", reloaded)
+	}
+	for _, stage := range []TaskStage{TaskStageReceived, TaskStageDownloaded, TaskStageVerified} {
+		if _, ok := reloaded.Timestamps[stage]; !ok {
+			t.Errorf("missing timestamp of stage %s", stage)
+		}
+	}
+}
+
+func TestTaskJournalIgnoresTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenTaskJournal(path, 0)
+	if err != nil {
+		t.Fatalf("could not open task journal: %s", err)
+	}
+	journal.Put(newTestTaskRecord(1, 10, TaskStageSigned))
+	journal.Close()
+
+	// A crash in the middle of an append leaves a partial line at the end
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	file.WriteString(`{"batch_identifier_hash":"0x02","stage":"sen`)
+	file.Close()
+
+	journal, err = OpenTaskJournal(path, 0)
+	if err != nil {
+		t.Fatalf("could not reopen task journal: %s", err)
+	}
+	defer journal.Close()
+
+	unfinished := journal.Unfinished()
+	if len(unfinished) != 1 || unfinished[0].Stage != TaskStageSigned {
+		t.Errorf("expected the signed record only, got %+v", unfinished)
+	}
+	if err := journal.Put(newTestTaskRecord(3, 11, TaskStageReceived)); err != nil {
+		t.Errorf("could not append after a torn line: %s", err)
+	}
+}
+
+func TestTaskJournalUnfinished(t *testing.T) {
+	journal, err := OpenTaskJournal(filepath.Join(t.TempDir(), "journal.jsonl"), 0)
+	if err != nil {
+		t.Fatalf("could not open task journal: %s", err)
+	}
+	defer journal.Close()
+
+	journal.Put(newTestTaskRecord(1, 30, TaskStageDownloaded))
+	journal.Put(newTestTaskRecord(2, 10, TaskStageSigned))
+	journal.Put(newTestTaskRecord(3, 40, TaskStageSent))
+	journal.Put(newTestTaskRecord(4, 20, TaskStageRejected))
+	journal.Put(newTestTaskRecord(5, 25, TaskStageResponded))
+
+	unfinished := journal.Unfinished()
+	if len(unfinished) != 2 || unfinished[0].BlockNumber != 10 || unfinished[1].BlockNumber != 30 {
+		t.Errorf("expected the records of blocks 10 and 30, got %+v", unfinished)
+	}
+	if journal.LatestBlock() != 40 {
+		t.Errorf("expected latest block 40, got %d", journal.LatestBlock())
+	}
+
+	journal.Put(newTestTaskRecord(6, 50, TaskStageReceived))
+	if journal.LatestFinishedBlock() != 40 {
+		t.Errorf("expected latest finished block 40, got %d", journal.LatestFinishedBlock())
+	}
+}
+
+func TestTaskJournalCompactionDropsOldFinishedTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenTaskJournal(path, 0)
+	if err != nil {
+		t.Fatalf("could not open task journal: %s", err)
+	}
+
+	journal.Put(newTestTaskRecord(1, 10, TaskStageSent))
+	journal.Put(newTestTaskRecord(2, 20, TaskStageReceived))
+	journal.Put(newTestTaskRecord(3, 30, TaskStageSent))
+	// Age every record past the retention window
+	for _, record := range journal.records {
+		record.UpdatedAt = time.Now().Add(-2 * TaskJournalRetention)
+	}
+	if err := journal.compact(); err != nil {
+		t.Fatalf("could not compact task journal: %s", err)
+	}
+	journal.Close()
+
+	journal, err = OpenTaskJournal(path, 0)
+	if err != nil {
+		t.Fatalf("could not reopen task journal: %s", err)
+	}
+	defer journal.Close()
+
+	if _, ok := journal.Get(ethcommon.Hash{1}); ok {
+		t.Error("old finished task was not dropped")
+	}
+	if _, ok := journal.Get(ethcommon.Hash{2}); !ok {
+		t.Error("unfinished task was dropped")
+	}
+	if journal.LatestBlock() != 30 {
+		t.Errorf("latest finished task was dropped, latest block is %d", journal.LatestBlock())
+	}
+}
+
+func TestTaskJournalAbandonsStaleUnfinishedTasks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenTaskJournal(path, time.Hour)
+	if err != nil {
+		t.Fatalf("could not open task journal: %s", err)
+	}
+
+	journal.Put(newTestTaskRecord(1, 10, TaskStageDownloaded))
+	journal.Put(newTestTaskRecord(2, 20, TaskStageReceived))
+	// Receive the first task before the retention window
+	journal.records[ethcommon.Hash{1}].Timestamps[TaskStageReceived] = time.Now().Add(-2 * time.Hour)
+	if err := journal.compact(); err != nil {
+		t.Fatalf("could not compact task journal: %s", err)
+	}
+	journal.Close()
+
+	journal, err = OpenTaskJournal(path, time.Hour)
+	if err != nil {
+		t.Fatalf("could not reopen task journal: %s", err)
+	}
+	defer journal.Close()
+
+	abandoned, _ := journal.Get(ethcommon.Hash{1})
+	if abandoned.Stage != TaskStageAbandoned || abandoned.Error == "" {
+		t.Errorf("expected the stale task to be abandoned, got %+v", abandoned)
+	}
+	if unfinished := journal.Unfinished(); len(unfinished) != 1 || unfinished[0].BatchIdentifierHash != (ethcommon.Hash{2}) {
+		t.Errorf("expected only the recent task to be resumed, got %+v", unfinished)
+	}
+
+	if _, err := OpenTaskJournal(path, -time.Hour); err == nil {
+		t.Error("expected a negative retention to be rejected")
+	}
+}
@@ -0,0 +1,176 @@
+package operator
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+// VerificationOutcome classifies the result of verifying a single proof
+type VerificationOutcome string
+
+const (
+	OutcomeValid                    VerificationOutcome = "valid"
+	OutcomeInvalid                  VerificationOutcome = "invalid"
+	OutcomeDeserializationError     VerificationOutcome = "deserialization_error"
+	OutcomeVerifierDisabled         VerificationOutcome = "verifier_disabled"
+	OutcomeVerifierPanic            VerificationOutcome = "verifier_panic"
+	OutcomeTimeout                  VerificationOutcome = "timeout"
+	OutcomeUnsupportedProvingSystem VerificationOutcome = "unsupported_proving_system"
+	OutcomeWorkerCrashed            VerificationOutcome = "worker_crashed"
+	// OutcomeError is any other failure of the verifier, which may not happen again and so is never cached
+	OutcomeError VerificationOutcome = "error"
+)
+
+var (
+	// ErrDeserialization is wrapped by verifiers when the proof, public input or key can't be parsed
+	ErrDeserialization = errors.New("could not deserialize verification data")
+	// ErrVerifierPanic is wrapped by verifiers when the underlying library panicked
+	ErrVerifierPanic = errors.New("verifier panicked")
+	// ErrVerificationTimeout is wrapped when a verification was cancelled or ran past its deadline
+	ErrVerificationTimeout = errors.New("verification timed out or was cancelled")
+	// ErrWorkerCrashed is wrapped when a verifier worker died while verifying a proof
+	ErrWorkerCrashed = errors.New("verifier worker crashed")
+	// ErrUnsupportedProvingSystem is returned when no verifier is registered for the proving system
+	ErrUnsupportedProvingSystem = errors.New("unsupported proving system")
+	// ErrBatchFailed cancels the remaining verifications of a batch once one of its proofs failed
+	ErrBatchFailed = errors.New("another proof of the batch failed")
+	// ErrBatchAlreadyResponded cancels the remaining verifications of a batch already responded on chain
+	ErrBatchAlreadyResponded = errors.New("batch already responded")
+	// ErrBatchVerificationTimeout cancels the remaining verifications of a batch after BatchVerificationTimeout
+	ErrBatchVerificationTimeout = errors.New("batch verification timed out")
+	// ErrOperatorShutdown cancels the batches still in flight ShutdownTimeout after the operator was asked to stop
+	ErrOperatorShutdown = errors.New("operator shut down")
+)
+
+// MaxStoredBatchVerdicts is the number of batches whose verdicts are kept in memory
+const MaxStoredBatchVerdicts = 1000
+
+// ProofVerdict is the verification verdict of a single proof in a batch
+type ProofVerdict struct {
+	Index           int                    `json:"index"`
+	ProvingSystemId common.ProvingSystemId `json:"proving_system"`
+	Outcome         VerificationOutcome    `json:"outcome"`
+	Duration        time.Duration          `json:"duration_ns"`
+	Error           string                 `json:"error,omitempty"`
+	// Cached is set when the outcome was taken from the verification cache
+	Cached bool `json:"cached,omitempty"`
+}
+
+func (v ProofVerdict) IsValid() bool {
+	return v.Outcome == OutcomeValid
+}
+
+func (v ProofVerdict) String() string {
+	provingSystem, err := common.ProvingSystemIdToString(v.ProvingSystemId)
+	if err != nil {
+		provingSystem = fmt.Sprintf("%d", v.ProvingSystemId)
+	}
+	verdict := fmt.Sprintf("proof %d (%s): %s in %s", v.Index, provingSystem, v.Outcome, v.Duration)
+	if v.Cached {
+		verdict += " (cached)"
+	}
+	if v.Error != "" {
+		verdict += ": " + v.Error
+	}
+	return verdict
+}
+
+// BatchVerdicts holds the per-proof verdicts of a batch, in the same order as the batch
+type BatchVerdicts struct {
+	BatchMerkleRoot [32]byte       `json:"batch_merkle_root"`
+	SenderAddress   [20]byte       `json:"sender_address"`
+	VerifiedAt      time.Time      `json:"verified_at"`
+	Verdicts        []ProofVerdict `json:"verdicts"`
+}
+
+// Valid returns true if every proof in the batch verified
+func (b *BatchVerdicts) Valid() bool {
+	return len(b.Failed()) == 0
+}
+
+// Failed returns the verdicts of the proofs that did not verify
+func (b *BatchVerdicts) Failed() []ProofVerdict {
+	var failed []ProofVerdict
+	for _, verdict := range b.Verdicts {
+		if !verdict.IsValid() {
+			failed = append(failed, verdict)
+		}
+	}
+	return failed
+}
+
+// InvalidBatchError is returned when at least one proof of a batch did not verify
+type InvalidBatchError struct {
+	BatchMerkleRoot [32]byte
+	Failed          []ProofVerdict
+}
+
+func (e *InvalidBatchError) Error() string {
+	return fmt.Sprintf("invalid proof: %d proofs of batch 0x%s did not verify, first failure: %s",
+		len(e.Failed), hex.EncodeToString(e.BatchMerkleRoot[:]), e.Failed[0])
+}
+
+// outcomeFromResult maps the result of a Verifier to a VerificationOutcome
+func outcomeFromResult(result VerificationResult) VerificationOutcome {
+	switch {
+	case result.Err == nil && result.Verified:
+		return OutcomeValid
+	case result.Err == nil:
+		return OutcomeInvalid
+	case errors.Is(result.Err, ErrDeserialization):
+		return OutcomeDeserializationError
+	case errors.Is(result.Err, ErrVerifierPanic):
+		return OutcomeVerifierPanic
+	case errors.Is(result.Err, ErrVerificationTimeout):
+		return OutcomeTimeout
+	case errors.Is(result.Err, ErrWorkerCrashed):
+		return OutcomeWorkerCrashed
+	case errors.Is(result.Err, ErrUnsupportedProvingSystem):
+		return OutcomeUnsupportedProvingSystem
+	default:
+		return OutcomeError
+	}
+}
+
+// VerdictStore keeps the verdicts of the last MaxStoredBatchVerdicts batches, keyed by batch merkle root
+type VerdictStore struct {
+	verdicts map[[32]byte]BatchVerdicts
+	order    [][32]byte
+	capacity int
+	mutex    sync.RWMutex
+}
+
+func NewVerdictStore(capacity int) *VerdictStore {
+	return &VerdictStore{
+		verdicts: make(map[[32]byte]BatchVerdicts),
+		capacity: capacity,
+	}
+}
+
+func (s *VerdictStore) Add(batchVerdicts BatchVerdicts) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.verdicts[batchVerdicts.BatchMerkleRoot]; !ok {
+		s.order = append(s.order, batchVerdicts.BatchMerkleRoot)
+	}
+	s.verdicts[batchVerdicts.BatchMerkleRoot] = batchVerdicts
+
+	for len(s.order) > s.capacity {
+		delete(s.verdicts, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+func (s *VerdictStore) Get(batchMerkleRoot [32]byte) (BatchVerdicts, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	batchVerdicts, ok := s.verdicts[batchMerkleRoot]
+	return batchVerdicts, ok
+}
@@ -0,0 +1,167 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+type panickingVerifier struct{}
+
+func (v *panickingVerifier) Name() string                     { return "panicking" }
+func (v *panickingVerifier) Init(logger logging.Logger) error { return nil }
+func (v *panickingVerifier) SelfTest() error                  { return nil }
+func (v *panickingVerifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	panic("boom")
+}
+
+// blockingVerifier never returns until released, like a verifier stuck on a pathological proof
+type blockingVerifier struct {
+	release chan struct{}
+}
+
+func (v *blockingVerifier) Name() string                     { return "blocking" }
+func (v *blockingVerifier) Init(logger logging.Logger) error { return nil }
+func (v *blockingVerifier) SelfTest() error                  { return nil }
+func (v *blockingVerifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	<-v.release
+	return VerificationResult{Verified: true}
+}
+
+func TestOutcomeFromResult(t *testing.T) {
+	cases := []struct {
+		result VerificationResult
+		want   VerificationOutcome
+	}{
+		{VerificationResult{Verified: true}, OutcomeValid},
+		{VerificationResult{Verified: false}, OutcomeInvalid},
+		{VerificationResult{Err: fmt.Errorf("%w: bad proof", ErrDeserialization)}, OutcomeDeserializationError},
+		{VerificationResult{Err: fmt.Errorf("%w: ffi", ErrVerifierPanic)}, OutcomeVerifierPanic},
+		{VerificationResult{Err: fmt.Errorf("%w: deadline", ErrVerificationTimeout)}, OutcomeTimeout},
+		{VerificationResult{Err: errors.New("other")}, OutcomeError},
+		{verifierWorkerResponse{Outcome: OutcomeInvalid, Error: "unknown failure"}.result(), OutcomeError},
+	}
+
+	for _, c := range cases {
+		if got := outcomeFromResult(c.result); got != c.want {
+			t.Errorf("outcomeFromResult(%+v) = %s, want %s", c.result, got, c.want)
+		}
+	}
+	if isCacheable(OutcomeError) {
+		t.Error("expected unclassified verifier errors not to be cached")
+	}
+}
+
+func TestRunVerifierRecoversPanics(t *testing.T) {
+	result, _ := runVerifier(context.Background(), &panickingVerifier{}, VerificationData{})
+	if result.Verified || !errors.Is(result.Err, ErrVerifierPanic) {
+		t.Errorf("expected a verifier panic result, got %+v", result)
+	}
+}
+
+func TestRunVerifierTimesOut(t *testing.T) {
+	verifier := &blockingVerifier{release: make(chan struct{})}
+	defer close(verifier.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	result, verifierDone := runVerifier(ctx, verifier, VerificationData{})
+	if result.Verified || outcomeFromResult(result) != OutcomeTimeout {
+		t.Errorf("expected a timeout result, got %+v", result)
+	}
+
+	select {
+	case <-verifierDone:
+		t.Error("expected the verifier to be reported running until it returns")
+	default:
+	}
+	verifier.release <- struct{}{}
+	<-verifierDone
+}
+
+func TestRunVerifierReportsCancellationCause(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrBatchFailed)
+
+	result, _ := runVerifier(ctx, &panickingVerifier{}, VerificationData{})
+	if !errors.Is(result.Err, ErrVerificationTimeout) || outcomeFromResult(result) != OutcomeTimeout {
+		t.Errorf("expected a timeout result, got %+v", result)
+	}
+	if result.Err.Error() != fmt.Sprintf("%s: %s", ErrVerificationTimeout, ErrBatchFailed) {
+		t.Errorf("expected the cancellation cause in the error, got %q", result.Err)
+	}
+}
+
+func TestVerdictStoreEvictsOldestBatches(t *testing.T) {
+	store := NewVerdictStore(2)
+	roots := [][32]byte{{1}, {2}, {3}}
+	for _, root := range roots {
+		store.Add(BatchVerdicts{BatchMerkleRoot: root})
+	}
+
+	if _, ok := store.Get(roots[0]); ok {
+		t.Errorf("oldest batch should have been evicted")
+	}
+	for _, root := range roots[1:] {
+		if _, ok := store.Get(root); !ok {
+			t.Errorf("batch %x should still be stored", root)
+		}
+	}
+}
+
+func TestBatchVerdictsFailed(t *testing.T) {
+	batchVerdicts := BatchVerdicts{Verdicts: []ProofVerdict{
+		{Index: 0, Outcome: OutcomeValid},
+		{Index: 1, Outcome: OutcomeVerifierDisabled},
+		{Index: 2, Outcome: OutcomeValid},
+	}}
+
+	failed := batchVerdicts.Failed()
+	if batchVerdicts.Valid() || len(failed) != 1 || failed[0].Index != 1 {
+		t.Errorf("expected only proof 1 to fail, got %v", failed)
+	}
+}
+
+func TestBatchRespondedDuringVerificationIsNotRejected(t *testing.T) {
+	journal, err := OpenTaskJournal(filepath.Join(t.TempDir(), "journal.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+	operator := &Operator{
+		Logger:      newTestLogger(t),
+		taskJournal: journal,
+		metrics:     newTestMetrics(t),
+		verdicts:    NewVerdictStore(MaxStoredBatchVerdicts),
+	}
+
+	record := newTestTaskRecord(1, 10, TaskStageReceived)
+	if err := journal.Put(record); err != nil {
+		t.Fatal(err)
+	}
+
+	verifyCtx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrBatchAlreadyResponded)
+	verdicts := []ProofVerdict{
+		{Index: 0, Outcome: OutcomeValid},
+		{Index: 1, Outcome: OutcomeTimeout, Error: verificationCancelled(verifyCtx).Error()},
+	}
+	err = operator.finishBatchVerification(context.Background(), verifyCtx, record.BatchIdentifierHash, record.BatchMerkleRoot, record.SenderAddress, verdicts, nil)
+	if !errors.Is(err, ErrBatchAlreadyResponded) {
+		t.Errorf("expected ErrBatchAlreadyResponded, got %v", err)
+	}
+
+	journaled, _ := journal.Get(record.BatchIdentifierHash)
+	if journaled.Stage != TaskStageResponded {
+		t.Errorf("expected the batch to be journaled as responded, got %s", journaled.Stage)
+	}
+	if _, ok := operator.BatchVerdicts(record.BatchMerkleRoot); ok {
+		t.Error("expected the cancelled verdicts not to be recorded")
+	}
+}
@@ -0,0 +1,240 @@
+package operator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/core/lru"
+)
+
+// DefaultVerificationCacheSize is the number of verdicts kept in memory when `verification_cache_size` is not set
+const DefaultVerificationCacheSize = 16384
+
+// DefaultVerificationCacheDiskEntries is the number of verdicts kept on disk when `verification_cache_disk_entries` is not set
+const DefaultVerificationCacheDiskEntries = 1 << 18
+
+const (
+	VerificationCacheTierMemory = "memory"
+	VerificationCacheTierDisk   = "disk"
+)
+
+// VerificationCache stores the outcome of already verified proofs, keyed by a hash of their content.
+// It has an in-memory LRU tier and an optional on-disk tier that survives restarts.
+// The on-disk tier is bounded too, evicting the least recently used verdicts, so the verdicts
+// orphaned by a verifier upgrade are eventually removed.
+// Only outcomes that depend solely on the verification data are stored.
+type VerificationCache struct {
+	memory   *lru.Cache[[32]byte, VerificationOutcome]
+	dir      string
+	disk     *lru.Cache[[32]byte, struct{}]
+	onLookup func(hit bool, tier string)
+	mutex    sync.Mutex
+}
+
+// NewVerificationCache creates a cache holding up to size verdicts in memory.
+// If dir is not empty, up to diskEntries verdicts are also persisted there, one file per verdict.
+// The verdicts already stored are loaded, most recently used first, and the rest are removed.
+func NewVerificationCache(size int, dir string, diskEntries int) (*VerificationCache, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid verification cache size: %d", size)
+	}
+	if size == 0 {
+		size = DefaultVerificationCacheSize
+	}
+	if diskEntries < 0 {
+		return nil, fmt.Errorf("invalid verification cache disk entries: %d", diskEntries)
+	}
+	if diskEntries == 0 {
+		diskEntries = DefaultVerificationCacheDiskEntries
+	}
+
+	cache := &VerificationCache{
+		memory: lru.New[[32]byte, VerificationOutcome](int64(size)),
+		dir:    dir,
+	}
+	if dir == "" {
+		return cache, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create verification cache directory: %w", err)
+	}
+	cache.disk = lru.New[[32]byte, struct{}](int64(diskEntries))
+	cache.disk.OnEvict(func(key [32]byte, _ struct{}) {
+		os.Remove(cache.pathFor(key))
+	})
+	if err := cache.loadDiskTier(); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
+
+// loadDiskTier adds the verdicts stored in dir to the on-disk LRU, oldest first,
+// so the least recently used ones are evicted if there are too many
+func (c *VerificationCache) loadDiskTier() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("could not read verification cache directory: %w", err)
+	}
+
+	type storedVerdict struct {
+		key      [32]byte
+		lastUsed time.Time
+	}
+	var verdicts []storedVerdict
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(entry.Name(), "tmp-") {
+			// Verdicts left behind by a crash while writing them. Recent ones may belong to a running operator.
+			if time.Since(info.ModTime()) > time.Hour {
+				os.Remove(filepath.Join(c.dir, entry.Name()))
+			}
+			continue
+		}
+		key, err := hex.DecodeString(entry.Name())
+		if entry.IsDir() || err != nil || len(key) != 32 {
+			continue
+		}
+		verdicts = append(verdicts, storedVerdict{key: [32]byte(key), lastUsed: info.ModTime()})
+	}
+
+	sort.Slice(verdicts, func(i, j int) bool {
+		return verdicts[i].lastUsed.Before(verdicts[j].lastUsed)
+	})
+	for _, verdict := range verdicts {
+		c.disk.Add(verdict.key, struct{}{}, 1)
+	}
+	return nil
+}
+
+// OnLookup sets a callback invoked on every lookup, with the tier that served it on hits
+func (c *VerificationCache) OnLookup(callback func(hit bool, tier string)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onLookup = callback
+}
+
+// VerificationCacheKey hashes the proving system, the version of its verifier, see VerifierVersion,
+// and every input of the verification. Each field is length prefixed so different splits of the same bytes don't collide.
+func VerificationCacheKey(verificationData VerificationData, verifierVersion string) [32]byte {
+	hasher := sha256.New()
+
+	var buf [8]byte
+	binary.BigEndian.PutUint16(buf[:2], uint16(verificationData.ProvingSystemId))
+	hasher.Write(buf[:2])
+
+	for _, field := range [][]byte{[]byte(verifierVersion), verificationData.Proof, verificationData.PubInput, verificationData.VerificationKey, verificationData.VmProgramCode} {
+		binary.BigEndian.PutUint64(buf[:], uint64(len(field)))
+		hasher.Write(buf[:])
+		hasher.Write(field)
+	}
+
+	var key [32]byte
+	copy(key[:], hasher.Sum(nil))
+	return key
+}
+
+// isCacheable reports whether an outcome only depends on the verification data,
+// as opposed to the operator state (disabled verifiers) or transient failures (panics, timeouts)
+func isCacheable(outcome VerificationOutcome) bool {
+	switch outcome {
+	case OutcomeValid, OutcomeInvalid, OutcomeDeserializationError:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *VerificationCache) Get(key [32]byte) (VerificationOutcome, bool) {
+	if outcome, ok := c.memory.Get(key); ok {
+		c.notifyLookup(true, VerificationCacheTierMemory)
+		return outcome, true
+	}
+
+	if c.dir != "" {
+		if _, ok := c.disk.Get(key); ok {
+			if content, err := os.ReadFile(c.pathFor(key)); err == nil {
+				outcome := VerificationOutcome(content)
+				if isCacheable(outcome) {
+					// The modification time keeps the order of use across restarts
+					now := time.Now()
+					os.Chtimes(c.pathFor(key), now, now)
+					c.memory.Add(key, outcome, 1)
+					c.notifyLookup(true, VerificationCacheTierDisk)
+					return outcome, true
+				}
+			}
+			c.disk.Remove(key)
+		}
+	}
+
+	c.notifyLookup(false, "")
+	return "", false
+}
+
+// Put stores the outcome of a verification. Outcomes that are not cacheable are ignored.
+func (c *VerificationCache) Put(key [32]byte, outcome VerificationOutcome) error {
+	if !isCacheable(outcome) {
+		return nil
+	}
+
+	c.memory.Add(key, outcome, 1)
+
+	if c.dir == "" {
+		return nil
+	}
+
+	// Write to a temporary file and rename it, so a crash never leaves a partial verdict behind
+	tmpFile, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmpFile.WriteString(string(outcome))
+	closeErr := tmpFile.Close()
+	if err = errors.Join(err, closeErr); err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+
+	if err := os.Rename(tmpFile.Name(), c.pathFor(key)); err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+	c.disk.Add(key, struct{}{}, 1)
+	return nil
+}
+
+// DiskEntries returns the number of verdicts stored on disk
+func (c *VerificationCache) DiskEntries() int {
+	if c.disk == nil {
+		return 0
+	}
+	return c.disk.Len()
+}
+
+func (c *VerificationCache) pathFor(key [32]byte) string {
+	return filepath.Join(c.dir, hex.EncodeToString(key[:]))
+}
+
+func (c *VerificationCache) notifyLookup(hit bool, tier string) {
+	c.mutex.Lock()
+	callback := c.onLookup
+	c.mutex.Unlock()
+
+	if callback != nil {
+		callback(hit, tier)
+	}
+}
@@ -0,0 +1,120 @@
+package operator
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+func TestVerificationCacheKeyIsLengthPrefixed(t *testing.T) {
+	a := VerificationData{ProvingSystemId: common.SP1, Proof: []byte{1, 2}, VmProgramCode: []byte{3}}
+	b := VerificationData{ProvingSystemId: common.SP1, Proof: []byte{1}, VmProgramCode: []byte{2, 3}}
+	if VerificationCacheKey(a, "v1") == VerificationCacheKey(b, "v1") {
+		t.Errorf("different verification data produced the same cache key")
+	}
+
+	c := a
+	c.ProvingSystemId = common.Risc0
+	if VerificationCacheKey(a, "v1") == VerificationCacheKey(c, "v1") {
+		t.Errorf("the proving system is not part of the cache key")
+	}
+	if VerificationCacheKey(a, "v1") == VerificationCacheKey(a, "v2") {
+		t.Errorf("the verifier version is not part of the cache key")
+	}
+}
+
+func TestVerificationCacheDiskTier(t *testing.T) {
+	dir := t.TempDir()
+	key := VerificationCacheKey(VerificationData{ProvingSystemId: common.GnarkPlonkBn254, Proof: []byte{1}}, "v1")
+
+	cache, err := NewVerificationCache(10, dir, 0)
+	if err != nil {
+		t.Fatalf("could not create cache: %s", err)
+	}
+	if err := cache.Put(key, OutcomeValid); err != nil {
+		t.Fatalf("could not store verdict: %s", err)
+	}
+
+	// A new cache simulates an operator restart, so the verdict can only come from disk
+	restarted, err := NewVerificationCache(10, dir, 0)
+	if err != nil {
+		t.Fatalf("could not create cache: %s", err)
+	}
+	var servedBy string
+	restarted.OnLookup(func(hit bool, tier string) { servedBy = tier })
+
+	outcome, ok := restarted.Get(key)
+	if !ok || outcome != OutcomeValid || servedBy != VerificationCacheTierDisk {
+		t.Errorf("expected a valid verdict from disk, got %q (found: %t, tier: %q)", outcome, ok, servedBy)
+	}
+}
+
+func TestVerificationCacheIgnoresTransientOutcomes(t *testing.T) {
+	cache, err := NewVerificationCache(10, "", 0)
+	if err != nil {
+		t.Fatalf("could not create cache: %s", err)
+	}
+
+	for _, outcome := range []VerificationOutcome{OutcomeTimeout, OutcomeVerifierPanic, OutcomeVerifierDisabled} {
+		key := [32]byte{byte(len(outcome))}
+		if err := cache.Put(key, outcome); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("outcome %s should not be cached", outcome)
+		}
+	}
+}
+
+func TestVerificationCacheDiskTierIsBounded(t *testing.T) {
+	dir := t.TempDir()
+	keys := make([][32]byte, 4)
+	for i := range keys {
+		keys[i] = VerificationCacheKey(VerificationData{ProvingSystemId: common.GnarkPlonkBn254, Proof: []byte{byte(i)}}, "v1")
+	}
+
+	cache, err := NewVerificationCache(10, dir, 3)
+	if err != nil {
+		t.Fatalf("could not create cache: %s", err)
+	}
+	for i, key := range keys[:3] {
+		if err := cache.Put(key, OutcomeValid); err != nil {
+			t.Fatalf("could not store verdict: %s", err)
+		}
+		lastUsed := time.Now().Add(time.Duration(i-10) * time.Minute)
+		os.Chtimes(cache.pathFor(key), lastUsed, lastUsed)
+	}
+	// Reading the first verdict from disk makes the second one the least recently used
+	fromDisk, err := NewVerificationCache(10, dir, 3)
+	if err != nil {
+		t.Fatalf("could not create cache: %s", err)
+	}
+	if _, ok := fromDisk.Get(keys[0]); !ok {
+		t.Fatalf("expected the first verdict on disk")
+	}
+	if err := fromDisk.Put(keys[3], OutcomeInvalid); err != nil {
+		t.Fatalf("could not store verdict: %s", err)
+	}
+
+	if _, err := os.Stat(fromDisk.pathFor(keys[1])); !os.IsNotExist(err) {
+		t.Errorf("expected the least recently used verdict to be removed from disk, got %v", err)
+	}
+	if entries := fromDisk.DiskEntries(); entries != 3 {
+		t.Errorf("expected 3 verdicts on disk, got %d", entries)
+	}
+
+	// Lowering the limit prunes the verdicts already stored on the next start
+	pruned, err := NewVerificationCache(10, dir, 1)
+	if err != nil {
+		t.Fatalf("could not create cache: %s", err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || pruned.DiskEntries() != 1 {
+		t.Errorf("expected a single verdict left on disk, got %d files", len(files))
+	}
+}
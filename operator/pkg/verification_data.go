@@ -10,4 +10,6 @@ type VerificationData struct {
 	PubInput        []byte                 `json:"pub_input"`
 	VerificationKey []byte                 `json:"verification_key"`
 	VmProgramCode   []byte                 `json:"vm_program_code"`
+	// ProofGeneratorAddr is only needed to compute the merkle root of the batch
+	ProofGeneratorAddr string `json:"proof_generator_addr"`
 }
@@ -0,0 +1,62 @@
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// DefaultVerificationTimeout is the deadline of a single proof verification when `verification_timeout` is not set
+const DefaultVerificationTimeout = 2 * time.Minute
+
+// VerificationTimeouts holds the deadline of a single proof verification for each proving system
+type VerificationTimeouts struct {
+	defaultTimeout  time.Duration
+	byProvingSystem map[common.ProvingSystemId]time.Duration
+}
+
+// NewVerificationTimeouts creates the verification deadlines.
+// A defaultTimeout of 0 uses DefaultVerificationTimeout. Proving systems not in byProvingSystem use the default.
+func NewVerificationTimeouts(defaultTimeout time.Duration, byProvingSystem map[common.ProvingSystemId]time.Duration) (*VerificationTimeouts, error) {
+	if defaultTimeout < 0 {
+		return nil, fmt.Errorf("invalid verification timeout: %s", defaultTimeout)
+	}
+	if defaultTimeout == 0 {
+		defaultTimeout = DefaultVerificationTimeout
+	}
+
+	timeouts := make(map[common.ProvingSystemId]time.Duration, len(byProvingSystem))
+	for provingSystem, timeout := range byProvingSystem {
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid verification timeout for proving system %d: %s", provingSystem, timeout)
+		}
+		timeouts[provingSystem] = timeout
+	}
+
+	return &VerificationTimeouts{defaultTimeout: defaultTimeout, byProvingSystem: timeouts}, nil
+}
+
+// For returns the verification deadline of the given proving system
+func (t *VerificationTimeouts) For(provingSystem common.ProvingSystemId) time.Duration {
+	if timeout, ok := t.byProvingSystem[provingSystem]; ok {
+		return timeout
+	}
+	return t.defaultTimeout
+}
+
+// newVerificationTimeoutsFromConfig builds the deadlines from the `verification_timeout`
+// and `verification_timeouts` operator config fields
+func newVerificationTimeoutsFromConfig(configuration config.OperatorConfig) (*VerificationTimeouts, error) {
+	byProvingSystem := make(map[common.ProvingSystemId]time.Duration, len(configuration.Operator.VerificationTimeouts))
+	for provingSystemName, timeout := range configuration.Operator.VerificationTimeouts {
+		provingSystem, err := common.ProvingSystemIdFromString(provingSystemName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid `verification_timeouts` entry: %w", err)
+		}
+		byProvingSystem[provingSystem] = timeout
+	}
+
+	return NewVerificationTimeouts(configuration.Operator.VerificationTimeout, byProvingSystem)
+}
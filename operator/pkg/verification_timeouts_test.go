@@ -0,0 +1,25 @@
+package operator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+func TestVerificationTimeouts(t *testing.T) {
+	timeouts, err := NewVerificationTimeouts(0, map[common.ProvingSystemId]time.Duration{common.SP1: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("could not create timeouts: %s", err)
+	}
+	if timeout := timeouts.For(common.SP1); timeout != 5*time.Minute {
+		t.Errorf("expected the SP1 timeout, got %s", timeout)
+	}
+	if timeout := timeouts.For(common.Groth16Bn254); timeout != DefaultVerificationTimeout {
+		t.Errorf("expected the default timeout, got %s", timeout)
+	}
+
+	if _, err := NewVerificationTimeouts(time.Minute, map[common.ProvingSystemId]time.Duration{common.Risc0: 0}); err == nil {
+		t.Errorf("expected an error for a zero proving system timeout")
+	}
+}
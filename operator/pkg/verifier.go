@@ -0,0 +1,255 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+// VerificationResult is the outcome of running a Verifier over a single proof
+type VerificationResult struct {
+	Verified bool
+	// Err is set when the verifier could not reach a verdict,
+	// e.g. the proof could not be deserialized or the verifier panicked
+	Err error
+}
+
+// Verifier checks proofs of a single proving system.
+// Implementations are registered in a VerifierRegistry keyed by ProvingSystemId,
+// so new or forked verifiers can be plugged in without modifying the operator.
+type Verifier interface {
+	// Name is a human readable name used in logs
+	Name() string
+	// Init is called once before the verifier is used
+	Init(logger logging.Logger) error
+	// SelfTest checks that the verifier backend is usable
+	SelfTest() error
+	// Verify checks the proof contained in verificationData.
+	// Implementations should give up with an ErrVerificationTimeout error once ctx is done.
+	Verify(ctx context.Context, verificationData VerificationData) VerificationResult
+}
+
+// VersionedVerifier is implemented by verifiers whose verdicts can change with the version of their backend.
+// The version is part of the verification cache key, so verdicts cached by a previous version are not reused.
+type VersionedVerifier interface {
+	Version() string
+}
+
+// VerifierVersion returns the version of verifier, or its name if it doesn't report one
+func VerifierVersion(verifier Verifier) string {
+	if versioned, ok := verifier.(VersionedVerifier); ok {
+		return versioned.Version()
+	}
+	return verifier.Name()
+}
+
+// VerifierRegistry maps each ProvingSystemId to the Verifier in charge of it
+type VerifierRegistry struct {
+	verifiers map[common.ProvingSystemId]Verifier
+	mutex     sync.RWMutex
+}
+
+func NewVerifierRegistry() *VerifierRegistry {
+	return &VerifierRegistry{
+		verifiers: make(map[common.ProvingSystemId]Verifier),
+	}
+}
+
+// Register adds a verifier for the given proving system.
+// Returns an error if a verifier is already registered for it.
+func (r *VerifierRegistry) Register(provingSystem common.ProvingSystemId, verifier Verifier) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if existing, ok := r.verifiers[provingSystem]; ok {
+		return fmt.Errorf("proving system %d already has a registered verifier: %s", provingSystem, existing.Name())
+	}
+	r.verifiers[provingSystem] = verifier
+	return nil
+}
+
+// Replace sets the verifier for the given proving system, overriding any previously registered one.
+// This is meant for forked or in-house verifiers that take the place of a built-in one.
+func (r *VerifierRegistry) Replace(provingSystem common.ProvingSystemId, verifier Verifier) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.verifiers[provingSystem] = verifier
+}
+
+func (r *VerifierRegistry) Get(provingSystem common.ProvingSystemId) (Verifier, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	verifier, ok := r.verifiers[provingSystem]
+	return verifier, ok
+}
+
+// ProvingSystems returns the registered proving systems in ascending order
+func (r *VerifierRegistry) ProvingSystems() []common.ProvingSystemId {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	provingSystems := make([]common.ProvingSystemId, 0, len(r.verifiers))
+	for provingSystem := range r.verifiers {
+		provingSystems = append(provingSystems, provingSystem)
+	}
+	sort.Slice(provingSystems, func(i, j int) bool { return provingSystems[i] < provingSystems[j] })
+	return provingSystems
+}
+
+// InitAll calls Init on every registered verifier, stopping at the first failure
+func (r *VerifierRegistry) InitAll(logger logging.Logger) error {
+	for _, provingSystem := range r.ProvingSystems() {
+		verifier, _ := r.Get(provingSystem)
+		if err := verifier.Init(logger); err != nil {
+			return fmt.Errorf("could not initialize %s verifier: %w", verifier.Name(), err)
+		}
+	}
+	return nil
+}
+
+// SelfTestAll runs SelfTest on every registered verifier and returns the failures by proving system
+func (r *VerifierRegistry) SelfTestAll() map[common.ProvingSystemId]error {
+	failures := make(map[common.ProvingSystemId]error)
+	for _, provingSystem := range r.ProvingSystems() {
+		verifier, _ := r.Get(provingSystem)
+		if err := verifier.SelfTest(); err != nil {
+			failures[provingSystem] = err
+		}
+	}
+	return failures
+}
+
+// Clone returns a registry with the same verifiers, which can be changed without affecting r.
+// The verifier instances are shared with r, so initializing them again affects both registries.
+func (r *VerifierRegistry) Clone() *VerifierRegistry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	clone := NewVerifierRegistry()
+	for provingSystem, verifier := range r.verifiers {
+		clone.verifiers[provingSystem] = verifier
+	}
+	return clone
+}
+
+// VerifierFactory builds a new instance of a Verifier
+type VerifierFactory func() Verifier
+
+var (
+	// defaultVerifiers holds the factories of the built-in verifiers and those added by RegisterVerifier
+	defaultVerifiers      = newDefaultVerifierFactories()
+	defaultVerifiersMutex sync.RWMutex
+)
+
+// DefaultVerifierRegistry returns a new registry with the built-in verifiers and those added by RegisterVerifier.
+// Each call builds new verifier instances, so initializing or changing a registry doesn't leak into
+// other operators or batch verifiers.
+func DefaultVerifierRegistry() *VerifierRegistry {
+	defaultVerifiersMutex.RLock()
+	defer defaultVerifiersMutex.RUnlock()
+
+	registry := NewVerifierRegistry()
+	for provingSystem, newVerifier := range defaultVerifiers {
+		registry.verifiers[provingSystem] = newVerifier()
+	}
+	return registry
+}
+
+// RegisterVerifier adds a verifier to the registries returned by DefaultVerifierRegistry from now on.
+// newVerifier is called once for each of these registries.
+// Returns an error if a verifier is already registered for the proving system.
+func RegisterVerifier(provingSystem common.ProvingSystemId, newVerifier VerifierFactory) error {
+	defaultVerifiersMutex.Lock()
+	defer defaultVerifiersMutex.Unlock()
+
+	if _, ok := defaultVerifiers[provingSystem]; ok {
+		return fmt.Errorf("proving system %d already has a registered verifier", provingSystem)
+	}
+	defaultVerifiers[provingSystem] = newVerifier
+	return nil
+}
+
+func newDefaultVerifierFactories() map[common.ProvingSystemId]VerifierFactory {
+	return map[common.ProvingSystemId]VerifierFactory{
+		common.GnarkPlonkBls12_381: func() Verifier { return NewGnarkPlonkVerifier(ecc.BLS12_381) },
+		common.GnarkPlonkBn254:     func() Verifier { return NewGnarkPlonkVerifier(ecc.BN254) },
+		common.Groth16Bn254:        func() Verifier { return NewGnarkGroth16Verifier(ecc.BN254) },
+		common.SP1:                 func() Verifier { return NewSp1Verifier() },
+		common.Risc0:               func() Verifier { return NewRiscZeroVerifier() },
+		common.GnarkPlonkBls12_377: func() Verifier { return NewGnarkPlonkVerifier(ecc.BLS12_377) },
+		common.GnarkPlonkBw6_761:   func() Verifier { return NewGnarkPlonkVerifier(ecc.BW6_761) },
+		common.Groth16Bls12_381:    func() Verifier { return NewGnarkGroth16Verifier(ecc.BLS12_381) },
+		common.Groth16Bls12_377:    func() Verifier { return NewGnarkGroth16Verifier(ecc.BLS12_377) },
+		common.Groth16Bw6_761:      func() Verifier { return NewGnarkGroth16Verifier(ecc.BW6_761) },
+	}
+}
+
+// runVerifier calls verifier.Verify, turning a panic into an ErrVerifierPanic result.
+// If ctx is done before the verifier returns, an ErrVerificationTimeout result is returned right away.
+// gnark and FFI calls can't be interrupted, so the verifier is left to finish in the background:
+// the returned channel is closed once it really returns, so callers can keep its resources until then.
+func runVerifier(ctx context.Context, verifier Verifier, verificationData VerificationData) (VerificationResult, <-chan struct{}) {
+	if err := verificationCancelled(ctx); err != nil {
+		return VerificationResult{Verified: false, Err: err}, verifierReturned
+	}
+
+	resultChan := make(chan VerificationResult, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			rec := recover()
+			if rec != nil {
+				resultChan <- VerificationResult{Verified: false, Err: fmt.Errorf("%w: %v", ErrVerifierPanic, rec)}
+			}
+		}()
+		resultChan <- verifier.Verify(ctx, verificationData)
+	}()
+
+	select {
+	case result := <-resultChan:
+		return result, done
+	case <-ctx.Done():
+		return VerificationResult{Verified: false, Err: verificationCancelled(ctx)}, done
+	}
+}
+
+// verifierReturned is returned by runVerifier when no verifier was left running
+var verifierReturned = func() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}()
+
+// verificationCancelled returns an ErrVerificationTimeout error with the cancellation cause if ctx is done
+func verificationCancelled(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrVerificationTimeout, context.Cause(ctx))
+}
+
+// malformedVerificationData is used by self tests: every verifier must reject it cleanly
+func malformedVerificationData() VerificationData {
+	return VerificationData{
+		Proof:           []byte{0x00, 0x01, 0x02, 0x03},
+		PubInput:        []byte{0x00},
+		VerificationKey: []byte{0x00},
+		VmProgramCode:   []byte{0x00},
+	}
+}
+
+func selfTestRejectsMalformed(verifier Verifier) error {
+	result, _ := runVerifier(context.Background(), verifier, malformedVerificationData())
+	if result.Verified {
+		return fmt.Errorf("%s verifier accepted a malformed proof", verifier.Name())
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package operator
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/metrics"
+)
+
+const plonkBn254TestFilesPath = "../../scripts/test_files/gnark_plonk_bn254_script/"
+
+func readPlonkBn254VerificationData(t *testing.T) VerificationData {
+	proof, err := os.ReadFile(plonkBn254TestFilesPath + "plonk.proof")
+	if err != nil {
+		t.Fatalf("could not open proof file: %s", err)
+	}
+	pubInput, err := os.ReadFile(plonkBn254TestFilesPath + "plonk_pub_input.pub")
+	if err != nil {
+		t.Fatalf("could not open public input file: %s", err)
+	}
+	verificationKey, err := os.ReadFile(plonkBn254TestFilesPath + "plonk.vk")
+	if err != nil {
+		t.Fatalf("could not open verification key file: %s", err)
+	}
+
+	return VerificationData{
+		ProvingSystemId: common.GnarkPlonkBn254,
+		Proof:           proof,
+		PubInput:        pubInput,
+		VerificationKey: verificationKey,
+	}
+}
+
+func newTestLogger(t *testing.T) logging.Logger {
+	logger, err := logging.NewZapLogger(logging.Development)
+	if err != nil {
+		t.Fatalf("could not create logger: %s", err)
+	}
+	return logger
+}
+
+func newTestMetrics(t *testing.T) *metrics.Metrics {
+	return metrics.NewMetrics("", prometheus.NewRegistry(), newTestLogger(t))
+}
+
+func TestVerifierRegistry(t *testing.T) {
+	registry := NewVerifierRegistry()
+	plonkVerifier := NewGnarkPlonkVerifier(ecc.BN254)
+
+	if err := registry.Register(common.GnarkPlonkBn254, plonkVerifier); err != nil {
+		t.Fatalf("unexpected error registering verifier: %s", err)
+	}
+	if err := registry.Register(common.GnarkPlonkBn254, plonkVerifier); err == nil {
+		t.Errorf("registering a verifier twice for the same proving system should fail")
+	}
+
+	groth16Verifier := NewGnarkGroth16Verifier(ecc.BN254)
+	registry.Replace(common.GnarkPlonkBn254, groth16Verifier)
+	got, ok := registry.Get(common.GnarkPlonkBn254)
+	if !ok || got != groth16Verifier {
+		t.Errorf("Replace did not override the registered verifier")
+	}
+
+	if _, ok := registry.Get(common.SP1); ok {
+		t.Errorf("got a verifier for a proving system that was never registered")
+	}
+}
+
+func TestDefaultVerifierRegistryCoversAllProvingSystems(t *testing.T) {
+	provingSystems := []common.ProvingSystemId{
+		common.GnarkPlonkBls12_381, common.GnarkPlonkBn254, common.Groth16Bn254, common.SP1, common.Risc0,
+		common.GnarkPlonkBls12_377, common.GnarkPlonkBw6_761, common.Groth16Bls12_381, common.Groth16Bls12_377, common.Groth16Bw6_761,
+	}
+	for _, provingSystem := range provingSystems {
+		if _, ok := DefaultVerifierRegistry().Get(provingSystem); !ok {
+			t.Errorf("no default verifier for proving system %d", provingSystem)
+		}
+	}
+}
+
+func TestDefaultVerifierRegistryIsACopy(t *testing.T) {
+	registry := DefaultVerifierRegistry()
+	builtIn, _ := registry.Get(common.SP1)
+	registry.Replace(common.SP1, NewGnarkGroth16Verifier(ecc.BN254))
+
+	got, _ := DefaultVerifierRegistry().Get(common.SP1)
+	if _, ok := got.(*Sp1Verifier); !ok {
+		t.Error("expected changes to a default registry not to leak into the next ones")
+	}
+	if got == builtIn {
+		t.Error("expected each default registry to have its own verifier instances")
+	}
+}
+
+func TestDefaultVerifierRegistriesInitIndependently(t *testing.T) {
+	first := DefaultVerifierRegistry()
+	second := DefaultVerifierRegistry()
+
+	firstLogger := newTestLogger(t)
+	if err := first.InitAll(firstLogger); err != nil {
+		t.Fatalf("could not init verifiers: %s", err)
+	}
+	if err := second.InitAll(newTestLogger(t)); err != nil {
+		t.Fatalf("could not init verifiers: %s", err)
+	}
+
+	verifier, _ := first.Get(common.GnarkPlonkBn254)
+	if verifier.(*GnarkPlonkVerifier).logger != firstLogger {
+		t.Error("initializing a default registry changed the verifiers of another one")
+	}
+}
+
+func TestGnarkPlonkVerifier(t *testing.T) {
+	verifier := NewGnarkPlonkVerifier(ecc.BN254)
+	if err := verifier.Init(newTestLogger(t)); err != nil {
+		t.Fatalf("could not init verifier: %s", err)
+	}
+	if err := verifier.SelfTest(); err != nil {
+		t.Errorf("self test failed: %s", err)
+	}
+
+	verificationData := readPlonkBn254VerificationData(t)
+	result := verifier.Verify(context.Background(), verificationData)
+	if result.Err != nil || !result.Verified {
+		t.Errorf("proof did not verify: %v", result.Err)
+	}
+
+	verificationData.PubInput = verificationData.PubInput[:len(verificationData.PubInput)-1]
+	result = verifier.Verify(context.Background(), verificationData)
+	if result.Verified {
+		t.Errorf("proof with corrupted public input verified")
+	}
+}
+
+func TestDefaultGnarkVerifiers(t *testing.T) {
+	testCases := []struct {
+		provingSystem common.ProvingSystemId
+		dir           string
+		scheme        string
+	}{
+		{common.GnarkPlonkBls12_381, "gnark_plonk_bls12_381_script", "plonk"},
+		{common.GnarkPlonkBls12_377, "gnark_plonk_bls12_377_script", "plonk"},
+		{common.GnarkPlonkBw6_761, "gnark_plonk_bw6_761_script", "plonk"},
+		{common.Groth16Bn254, "gnark_groth16_bn254_script", "groth16"},
+		{common.Groth16Bls12_381, "gnark_groth16_bls12_381_script", "groth16"},
+		{common.Groth16Bls12_377, "gnark_groth16_bls12_377_script", "groth16"},
+		{common.Groth16Bw6_761, "gnark_groth16_bw6_761_script", "groth16"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.provingSystem.String(), func(t *testing.T) {
+			path := "../../scripts/test_files/" + testCase.dir + "/" + testCase.scheme
+			pubInputPath := path + ".pub"
+			if testCase.scheme == "plonk" {
+				pubInputPath = path + "_pub_input.pub"
+			}
+
+			verificationData := VerificationData{ProvingSystemId: testCase.provingSystem}
+			for _, file := range []struct {
+				path string
+				dst  *[]byte
+			}{
+				{path + ".proof", &verificationData.Proof},
+				{pubInputPath, &verificationData.PubInput},
+				{path + ".vk", &verificationData.VerificationKey},
+			} {
+				content, err := os.ReadFile(file.path)
+				if err != nil {
+					t.Fatalf("could not open test file: %s", err)
+				}
+				*file.dst = content
+			}
+
+			verifier, ok := DefaultVerifierRegistry().Get(testCase.provingSystem)
+			if !ok {
+				t.Fatalf("no default verifier")
+			}
+			if err := verifier.Init(newTestLogger(t)); err != nil {
+				t.Fatalf("could not init verifier: %s", err)
+			}
+			if result := verifier.Verify(context.Background(), verificationData); result.Err != nil || !result.Verified {
+				t.Errorf("proof did not verify: %v", result.Err)
+			}
+		})
+	}
+}
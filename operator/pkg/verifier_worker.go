@@ -0,0 +1,180 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/yetanotherco/aligned_layer/common"
+	"github.com/yetanotherco/aligned_layer/core/config"
+)
+
+// Verifier workers are child processes running FFI verifications, so a crash in the
+// underlying library only takes down the worker and not the operator.
+// The operator and its workers talk over two pipes passed as extra files:
+// requests are read from VerifierWorkerRequestsFd and responses written to VerifierWorkerResponsesFd,
+// one JSON document per line. Stdout and stderr are left to the verifier libraries.
+const (
+	VerifierWorkerRequestsFd  = 3
+	VerifierWorkerResponsesFd = 4
+)
+
+// VerifierWorkerCPULimitExitCode is the exit code of a worker that ran out of CPU time for a proof
+const VerifierWorkerCPULimitExitCode = 152
+
+// VerifierWorkerCommandName is the hidden operator subcommand that runs a verifier worker
+const VerifierWorkerCommandName = "verifier-worker"
+
+// DefaultVerifierWorkers is the number of workers when `verification_sandbox_workers` is not set
+const DefaultVerifierWorkers = 2
+
+// sandboxedProvingSystems are verified through FFI, so they run in workers when the sandbox is enabled
+var sandboxedProvingSystems = []common.ProvingSystemId{common.SP1, common.Risc0}
+
+type verifierWorkerRequest struct {
+	VerificationData VerificationData `json:"verification_data"`
+}
+
+type verifierWorkerResponse struct {
+	Verified bool                `json:"verified"`
+	Outcome  VerificationOutcome `json:"outcome"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// VerifierWorkerLimits are the resource limits applied by a worker to itself
+type VerifierWorkerLimits struct {
+	// MaxMemoryBytes bounds the address space of the worker. 0 means no limit.
+	MaxMemoryBytes int64
+	// CPUSecondsPerProof bounds the CPU time spent on a single proof. 0 means no limit.
+	CPUSecondsPerProof int64
+}
+
+// RunVerifierWorker serves verification requests with the verifiers in registry
+// until the requests pipe is closed
+func RunVerifierWorker(requests io.Reader, responses io.Writer, registry *VerifierRegistry, limits VerifierWorkerLimits, logger logging.Logger) error {
+	if limits.MaxMemoryBytes > 0 {
+		if err := setMemoryLimit(limits.MaxMemoryBytes); err != nil {
+			return fmt.Errorf("could not set worker memory limit: %w", err)
+		}
+	}
+	if limits.CPUSecondsPerProof > 0 {
+		exitOnCPULimit(VerifierWorkerCPULimitExitCode)
+	}
+
+	if err := registry.InitAll(logger); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(requests)
+	encoder := json.NewEncoder(responses)
+	for {
+		var request verifierWorkerRequest
+		if err := decoder.Decode(&request); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("could not read verification request: %w", err)
+		}
+
+		if limits.CPUSecondsPerProof > 0 {
+			if err := setCPULimit(limits.CPUSecondsPerProof); err != nil {
+				return fmt.Errorf("could not set worker CPU limit: %w", err)
+			}
+		}
+
+		result := VerificationResult{Err: ErrUnsupportedProvingSystem}
+		if verifier, ok := registry.Get(request.VerificationData.ProvingSystemId); ok {
+			result, _ = runVerifier(context.Background(), verifier, request.VerificationData)
+		}
+		response := verifierWorkerResponse{Verified: result.Verified, Outcome: outcomeFromResult(result)}
+		if result.Err != nil {
+			response.Error = result.Err.Error()
+		}
+
+		if err := encoder.Encode(response); err != nil {
+			return fmt.Errorf("could not write verification response: %w", err)
+		}
+	}
+}
+
+// result turns a worker response back into the result the in-process verifier would have returned
+func (r verifierWorkerResponse) result() VerificationResult {
+	var err error
+	switch r.Outcome {
+	case OutcomeDeserializationError:
+		err = fmt.Errorf("%w: %s", ErrDeserialization, r.Error)
+	case OutcomeVerifierPanic:
+		err = fmt.Errorf("%w: %s", ErrVerifierPanic, r.Error)
+	case OutcomeUnsupportedProvingSystem:
+		err = fmt.Errorf("%w: %s", ErrUnsupportedProvingSystem, r.Error)
+	default:
+		if r.Error != "" {
+			err = errors.New(r.Error)
+		}
+	}
+	return VerificationResult{Verified: r.Verified, Err: err}
+}
+
+// SandboxedVerifier runs the verifications of a proving system in a VerifierWorkerPool,
+// with the verifier of the same version built into the worker
+type SandboxedVerifier struct {
+	name    string
+	version string
+	pool    *VerifierWorkerPool
+}
+
+func NewSandboxedVerifier(name string, version string, pool *VerifierWorkerPool) *SandboxedVerifier {
+	return &SandboxedVerifier{name: name, version: version, pool: pool}
+}
+
+func (v *SandboxedVerifier) Name() string {
+	return v.name + " (sandboxed)"
+}
+
+func (v *SandboxedVerifier) Version() string {
+	return v.version
+}
+
+func (v *SandboxedVerifier) Init(logger logging.Logger) error {
+	return nil
+}
+
+func (v *SandboxedVerifier) SelfTest() error {
+	return selfTestRejectsMalformed(v)
+}
+
+func (v *SandboxedVerifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	return v.pool.Verify(ctx, verificationData)
+}
+
+// sandboxVerifiers starts a worker pool from the `verification_sandbox_*` operator config fields
+// and replaces the FFI verifiers of registry by sandboxed ones running in it
+func sandboxVerifiers(registry *VerifierRegistry, configuration config.OperatorConfig, logger logging.Logger) (*VerifierWorkerPool, error) {
+	workers := configuration.Operator.VerificationSandboxWorkers
+	if workers == 0 {
+		workers = DefaultVerifierWorkers
+	}
+
+	newCommand, err := NewVerifierWorkerCommand(VerifierWorkerCommandName,
+		"--max-memory-bytes", strconv.FormatInt(configuration.Operator.VerificationSandboxMaxMemory, 10),
+		"--cpu-seconds", strconv.FormatInt(configuration.Operator.VerificationSandboxCPUSeconds, 10),
+	)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := NewVerifierWorkerPool(workers, newCommand, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, provingSystem := range sandboxedProvingSystems {
+		if verifier, ok := registry.Get(provingSystem); ok {
+			registry.Replace(provingSystem, NewSandboxedVerifier(verifier.Name(), VerifierVersion(verifier), pool))
+		}
+	}
+	return pool, nil
+}
@@ -0,0 +1,266 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+)
+
+const (
+	// VerifierWorkerRestartDelay is the initial wait before respawning a worker that failed to start
+	VerifierWorkerRestartDelay = 100 * time.Millisecond
+	// VerifierWorkerMaxRestartDelay caps the exponential backoff between failed starts
+	VerifierWorkerMaxRestartDelay = 10 * time.Second
+)
+
+// Reasons passed to the OnRestart callback
+const (
+	VerifierWorkerRestartCrash   = "crash"
+	VerifierWorkerRestartTimeout = "timeout"
+)
+
+// verifierWorker is a running worker process, serving one verification at a time
+type verifierWorker struct {
+	cmd       *exec.Cmd
+	encoder   *json.Encoder
+	decoder   *json.Decoder
+	exited    chan struct{}
+	exitError error
+}
+
+// VerifierWorkerPool keeps a fixed number of verifier worker processes running.
+// Crashed, killed or timed out workers are replaced by new ones.
+type VerifierWorkerPool struct {
+	newCommand func() *exec.Cmd
+	idle       chan *verifierWorker
+	logger     logging.Logger
+	onRestart  func(reason string)
+	closed     chan struct{}
+	closeOnce  sync.Once
+	mutex      sync.Mutex
+	workers    map[*verifierWorker]struct{}
+}
+
+// NewVerifierWorkerPool starts size workers, each running the command returned by newCommand.
+// The pool sets the ExtraFiles of the command to pass the request and response pipes.
+func NewVerifierWorkerPool(size int, newCommand func() *exec.Cmd, logger logging.Logger) (*VerifierWorkerPool, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid verifier worker pool size: %d", size)
+	}
+
+	pool := &VerifierWorkerPool{
+		newCommand: newCommand,
+		idle:       make(chan *verifierWorker, size),
+		logger:     logger,
+		closed:     make(chan struct{}),
+		workers:    make(map[*verifierWorker]struct{}),
+	}
+	for i := 0; i < size; i++ {
+		worker, err := pool.startWorker()
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.idle <- worker
+	}
+
+	return pool, nil
+}
+
+// NewVerifierWorkerCommand returns a command factory that runs the current executable with the given arguments,
+// typically the hidden `verifier-worker` subcommand of the operator
+func NewVerifierWorkerCommand(args ...string) (func() *exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("could not find the operator executable: %w", err)
+	}
+
+	return func() *exec.Cmd {
+		cmd := exec.Command(executable, args...)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		return cmd
+	}, nil
+}
+
+// OnRestart sets a callback invoked every time a worker is replaced,
+// with VerifierWorkerRestartCrash or VerifierWorkerRestartTimeout as reason
+func (p *VerifierWorkerPool) OnRestart(callback func(reason string)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.onRestart = callback
+}
+
+// Verify sends verificationData to an idle worker and waits for its result.
+// If the worker dies, the result wraps ErrWorkerCrashed. If ctx is done first, the worker
+// is killed and the result wraps ErrVerificationTimeout. In both cases the worker is replaced.
+func (p *VerifierWorkerPool) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	var worker *verifierWorker
+	select {
+	case worker = <-p.idle:
+	case <-ctx.Done():
+		return VerificationResult{Verified: false, Err: verificationCancelled(ctx)}
+	case <-p.closed:
+		return VerificationResult{Verified: false, Err: fmt.Errorf("%w: worker pool closed", ErrWorkerCrashed)}
+	}
+
+	responseChan := make(chan verifierWorkerResponse, 1)
+	errChan := make(chan error, 1)
+	go func() {
+		var response verifierWorkerResponse
+		if err := worker.encoder.Encode(verifierWorkerRequest{VerificationData: verificationData}); err != nil {
+			errChan <- err
+			return
+		}
+		if err := worker.decoder.Decode(&response); err != nil {
+			errChan <- err
+			return
+		}
+		responseChan <- response
+	}()
+
+	select {
+	case response := <-responseChan:
+		p.idle <- worker
+		return response.result()
+	case <-errChan:
+		worker.kill()
+		<-worker.exited
+		err := p.crashError(worker)
+		p.replaceWorker(worker, VerifierWorkerRestartCrash, err)
+		return VerificationResult{Verified: false, Err: err}
+	case <-ctx.Done():
+		worker.kill()
+		err := verificationCancelled(ctx)
+		p.replaceWorker(worker, VerifierWorkerRestartTimeout, err)
+		return VerificationResult{Verified: false, Err: err}
+	}
+}
+
+// Close kills every worker. Verifications in flight fail with ErrWorkerCrashed.
+func (p *VerifierWorkerPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		for worker := range p.workers {
+			worker.kill()
+		}
+	})
+}
+
+func (p *VerifierWorkerPool) startWorker() (*verifierWorker, error) {
+	requestsReader, requestsWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	responsesReader, responsesWriter, err := os.Pipe()
+	if err != nil {
+		requestsReader.Close()
+		requestsWriter.Close()
+		return nil, err
+	}
+
+	cmd := p.newCommand()
+	// ExtraFiles[i] becomes file descriptor 3+i in the worker
+	cmd.ExtraFiles = []*os.File{requestsReader, responsesWriter}
+	err = cmd.Start()
+	// The worker ends of the pipes belong to the worker now
+	requestsReader.Close()
+	responsesWriter.Close()
+	if err != nil {
+		requestsWriter.Close()
+		responsesReader.Close()
+		return nil, fmt.Errorf("could not start verifier worker: %w", err)
+	}
+
+	worker := &verifierWorker{
+		cmd:     cmd,
+		encoder: json.NewEncoder(requestsWriter),
+		decoder: json.NewDecoder(responsesReader),
+		exited:  make(chan struct{}),
+	}
+	go func() {
+		worker.exitError = cmd.Wait()
+		requestsWriter.Close()
+		responsesReader.Close()
+		close(worker.exited)
+	}()
+
+	p.mutex.Lock()
+	p.workers[worker] = struct{}{}
+	p.mutex.Unlock()
+
+	p.logger.Debugf("Started verifier worker with pid %d", cmd.Process.Pid)
+	return worker, nil
+}
+
+// replaceWorker forgets a dead worker and starts a new one in the background,
+// backing off while workers fail to start
+func (p *VerifierWorkerPool) replaceWorker(worker *verifierWorker, reason string, cause error) {
+	p.mutex.Lock()
+	delete(p.workers, worker)
+	onRestart := p.onRestart
+	p.mutex.Unlock()
+
+	p.logger.Warnf("Restarting verifier worker with pid %d: %v", worker.cmd.Process.Pid, cause)
+	if onRestart != nil {
+		onRestart(reason)
+	}
+
+	go func() {
+		delay := VerifierWorkerRestartDelay
+		for {
+			select {
+			case <-p.closed:
+				return
+			default:
+			}
+
+			newWorker, err := p.startWorker()
+			if err == nil {
+				select {
+				case <-p.closed:
+					newWorker.kill()
+				default:
+					p.idle <- newWorker
+				}
+				return
+			}
+
+			p.logger.Errorf("Could not restart verifier worker, retrying in %s: %v", delay, err)
+			select {
+			case <-p.closed:
+				return
+			case <-time.After(delay):
+			}
+			delay = min(2*delay, VerifierWorkerMaxRestartDelay)
+		}
+	}()
+}
+
+// crashError describes why a worker exited, once it did
+func (p *VerifierWorkerPool) crashError(worker *verifierWorker) error {
+	var exitErr *exec.ExitError
+	if errors.As(worker.exitError, &exitErr) && exitErr.ExitCode() == VerifierWorkerCPULimitExitCode {
+		return fmt.Errorf("%w: worker exceeded its CPU time limit", ErrVerificationTimeout)
+	}
+	if worker.exitError == nil {
+		return fmt.Errorf("%w: worker exited", ErrWorkerCrashed)
+	}
+	return fmt.Errorf("%w: %v", ErrWorkerCrashed, worker.exitError)
+}
+
+func (w *verifierWorker) kill() {
+	// Killing an already exited worker fails harmlessly
+	_ = w.cmd.Process.Kill()
+}
@@ -0,0 +1,103 @@
+package operator
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Layr-Labs/eigensdk-go/logging"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/yetanotherco/aligned_layer/common"
+)
+
+const verifierWorkerHelperEnv = "ALIGNED_TEST_VERIFIER_WORKER"
+
+// crashingVerifier kills its process, like an abort in an FFI library
+type crashingVerifier struct{}
+
+func (v *crashingVerifier) Name() string                     { return "crashing" }
+func (v *crashingVerifier) Init(logger logging.Logger) error { return nil }
+func (v *crashingVerifier) SelfTest() error                  { return nil }
+func (v *crashingVerifier) Verify(ctx context.Context, verificationData VerificationData) VerificationResult {
+	os.Exit(3)
+	return VerificationResult{}
+}
+
+// TestVerifierWorkerHelperProcess is not a real test: it's the worker process spawned by the pool tests
+func TestVerifierWorkerHelperProcess(t *testing.T) {
+	if os.Getenv(verifierWorkerHelperEnv) != "1" {
+		t.Skip("only runs as a verifier worker spawned by other tests")
+	}
+
+	registry := NewVerifierRegistry()
+	registry.Replace(common.GnarkPlonkBn254, NewGnarkPlonkVerifier(ecc.BN254))
+	registry.Replace(common.SP1, &crashingVerifier{})
+	registry.Replace(common.Risc0, &blockingVerifier{release: make(chan struct{})})
+
+	requests := os.NewFile(VerifierWorkerRequestsFd, "requests")
+	responses := os.NewFile(VerifierWorkerResponsesFd, "responses")
+	if err := RunVerifierWorker(requests, responses, registry, VerifierWorkerLimits{}, newTestLogger(t)); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func newTestVerifierWorkerPool(t *testing.T) *VerifierWorkerPool {
+	pool, err := NewVerifierWorkerPool(1, func() *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestVerifierWorkerHelperProcess$")
+		cmd.Env = append(os.Environ(), verifierWorkerHelperEnv+"=1")
+		cmd.Stderr = os.Stderr
+		return cmd
+	}, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("could not start worker pool: %s", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestVerifierWorkerPoolVerifies(t *testing.T) {
+	pool := newTestVerifierWorkerPool(t)
+	verificationData := readPlonkBn254VerificationData(t)
+
+	if result := pool.Verify(context.Background(), verificationData); result.Err != nil || !result.Verified {
+		t.Errorf("proof did not verify: %v", result.Err)
+	}
+
+	verificationData.Proof = []byte{1, 2, 3}
+	if outcome := outcomeFromResult(pool.Verify(context.Background(), verificationData)); outcome != OutcomeDeserializationError {
+		t.Errorf("expected a deserialization error, got %s", outcome)
+	}
+}
+
+func TestVerifierWorkerPoolRestartsWorkers(t *testing.T) {
+	pool := newTestVerifierWorkerPool(t)
+	var restarts atomic.Int32
+	pool.OnRestart(func(reason string) { restarts.Add(1) })
+	validData := readPlonkBn254VerificationData(t)
+
+	result := pool.Verify(context.Background(), VerificationData{ProvingSystemId: common.SP1})
+	if outcome := outcomeFromResult(result); outcome != OutcomeWorkerCrashed {
+		t.Errorf("expected a worker crash, got %s (%v)", outcome, result.Err)
+	}
+	if result := pool.Verify(context.Background(), validData); !result.Verified {
+		t.Errorf("restarted worker did not verify a valid proof: %v", result.Err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	result = pool.Verify(ctx, VerificationData{ProvingSystemId: common.Risc0})
+	if outcome := outcomeFromResult(result); outcome != OutcomeTimeout {
+		t.Errorf("expected a timeout, got %s (%v)", outcome, result.Err)
+	}
+	if result := pool.Verify(context.Background(), validData); !result.Verified {
+		t.Errorf("restarted worker did not verify a valid proof: %v", result.Err)
+	}
+
+	if restarts.Load() != 2 {
+		t.Errorf("expected 2 worker restarts, got %d", restarts.Load())
+	}
+}
@@ -0,0 +1,16 @@
+package operator
+
+import (
+	"github.com/yetanotherco/aligned_layer/core/vkcache"
+)
+
+// DefaultVerifyingKeyCacheMaxBytes is the memory budget of the parsed verifying key cache
+// when `verifying_key_cache_max_bytes` is not set
+const DefaultVerifyingKeyCacheMaxBytes = vkcache.DefaultMaxBytes
+
+var defaultVerifyingKeyCache = vkcache.New(DefaultVerifyingKeyCacheMaxBytes)
+
+// DefaultVerifyingKeyCache returns the cache shared by the built-in gnark verifiers
+func DefaultVerifyingKeyCache() *vkcache.Cache {
+	return defaultVerifyingKeyCache
+}